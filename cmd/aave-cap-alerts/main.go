@@ -5,29 +5,53 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/api"
 	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/logging"
 	"aave-cap-alerts/internal/monitor"
 	"aave-cap-alerts/internal/notify"
 )
 
+// version is stamped at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+var logger = logging.New("main")
+
 func main() {
 	var configPath string
+	var strictStartup bool
+	var backfillSince string
+	var simulatePath string
+	var debugCalls bool
 	flag.StringVar(&configPath, "config", "config.yaml", "Path to the YAML configuration file")
+	flag.BoolVar(&strictStartup, "strict-startup", false, "Exit with an error if any asset's first check fails")
+	flag.StringVar(&backfillSince, "backfill-since", "", "Seed baselines from chain state at this time (a duration ago such as 24h, or RFC 3339); requires an archive node")
+	flag.StringVar(&simulatePath, "simulate", "", "Read every asset with the eth_call state overrides in this JSON file, report the triggers they would fire, and exit")
+	flag.BoolVar(&debugCalls, "debug-calls", false, "Log the calldata and raw result of every contract call (requires the aave module at debug log level)")
 	flag.Parse()
 
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
+	if strictStartup {
+		cfg.StrictStartup = true
+	}
+	if err := configureLogging(cfg); err != nil {
+		log.Fatalf("configure logging: %v", err)
+	}
 
 	pollInterval := 1 * time.Minute
 	if cfg.PollInterval != "" {
@@ -43,41 +67,350 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	ethClient, err := ethclient.DialContext(ctx, cfg.RPCURL)
+	retry, err := parseDialRetry(cfg.DialRetry)
+	if err != nil {
+		log.Fatalf("parse dial_retry: %v", err)
+	}
+
+	ethClient, err := dialRPC(ctx, cfg.RPCURL, retry)
 	if err != nil {
 		log.Fatalf("connect RPC: %v", err)
 	}
 	defer ethClient.Close()
+	if err := verifyChainID(ctx, ethClient, cfg.ExpectedChainID); err != nil {
+		log.Fatalf("rpc_url: %v", err)
+	}
 
 	aaveClient, err := aave.NewClient(ethClient)
 	if err != nil {
 		log.Fatalf("setup aave client: %v", err)
 	}
 
+	if cfg.RPC.RateLimit < 0 || cfg.RPC.Burst < 0 {
+		log.Fatalf("rpc.rate_limit and rpc.burst must not be negative")
+	}
+	if cfg.RPC.RateLimit > 0 {
+		aaveClient.SetRateLimit(cfg.RPC.RateLimit, cfg.RPC.Burst)
+	}
+	aaveClient.SetReadFinalized(cfg.RPC.ReadFinalized)
+	aaveClient.SetDebugCalls(debugCalls)
+	if cfg.Contracts.Multicall3 != "" {
+		if !common.IsHexAddress(cfg.Contracts.Multicall3) {
+			log.Fatalf("contracts.multicall3 is not a valid hex string")
+		}
+		aaveClient.SetMulticall3(common.HexToAddress(cfg.Contracts.Multicall3))
+	}
+	if debugCalls && !logging.New("aave").Enabled(logging.LevelDebug) {
+		logger.Warnf("--debug-calls has no effect unless the aave module logs at debug (log_level or log_levels.aave)")
+	}
+
+	requiredMethods := cfg.RPCMethods
+	if len(requiredMethods) == 0 {
+		requiredMethods = aave.DefaultRequiredMethods
+	}
+	if err := aaveClient.VerifyCapabilities(ctx, requiredMethods); err != nil {
+		log.Fatalf("verify RPC capabilities: %v", err)
+	}
+
 	notifiers, err := buildNotifiers(cfg)
 	if err != nil {
 		log.Fatalf("configure notifiers: %v", err)
 	}
 
 	if len(notifiers) == 0 {
-		log.Println("warning: no notifiers configured; total supply changes will only be written to stdout")
+		logger.Warnf("no notifiers configured; total supply changes will only be written to stdout as JSON")
+		notifiers = append(notifiers, stdoutFallback(cfg))
+	}
+
+	var incidents *notify.IncidentTracker
+	if cfg.Notifications.Incidents.Enabled {
+		if cfg.Notifications.Incidents.StatePath != "" && cfg.Snapshot.Shared() {
+			log.Fatalf("configure incidents: notifications.incidents.state_path keeps incidents in a local file; snapshot.backend %s already keeps them, so remove state_path", cfg.Snapshot.Backend)
+		}
+		incidents, err = notify.NewIncidentTracker(cfg.Notifications.Incidents.StatePath)
+		if err != nil {
+			log.Fatalf("configure incidents: %v", err)
+		}
+		notifiers = withIncidents(notifiers, incidents)
+	}
+
+	if err := monitor.ResolveSymbols(ctx, aaveClient, cfg); err != nil {
+		log.Fatalf("resolve asset symbols: %v", err)
 	}
 
 	service, err := monitor.NewService(aaveClient, cfg, notifiers, pollInterval)
 	if err != nil {
 		log.Fatalf("build monitor: %v", err)
 	}
+	if incidents != nil {
+		if err := service.AddSnapshotSection("incidents", incidents); err != nil {
+			log.Fatalf("state snapshot: %v", err)
+		}
+	}
 
-	log.Printf("monitoring %d asset(s) with poll interval %s", len(cfg.Assets), pollInterval)
+	if simulatePath != "" {
+		if err := simulate(ctx, service, simulatePath); err != nil {
+			log.Fatalf("simulate: %v", err)
+		}
+		return
+	}
+
+	if cfg.ConfirmRPCURL != "" {
+		confirmEth, err := dialRPC(ctx, cfg.ConfirmRPCURL, retry)
+		if err != nil {
+			log.Fatalf("connect confirm RPC: %v", err)
+		}
+		defer confirmEth.Close()
+		if err := verifyChainID(ctx, confirmEth, cfg.ExpectedChainID); err != nil {
+			log.Fatalf("confirm_rpc_url: %v", err)
+		}
+		confirmClient, err := aave.NewClient(confirmEth)
+		if err != nil {
+			log.Fatalf("setup confirm client: %v", err)
+		}
+		confirmClient.SetReadFinalized(cfg.RPC.ReadFinalized)
+		confirmClient.SetDebugCalls(debugCalls)
+		if err := service.SetConfirmClient(confirmClient); err != nil {
+			log.Fatalf("confirm RPC: %v", err)
+		}
+	}
+
+	if urls := service.ChainRPCURLs(); len(urls) > 0 {
+		clients := make(map[string]*aave.Client, len(urls))
+		for _, url := range urls {
+			chainEth, err := dialRPC(ctx, url, retry)
+			if err != nil {
+				log.Fatalf("connect aggregate RPC %s: %v", url, err)
+			}
+			defer chainEth.Close()
+			chainClient, err := aave.NewClient(chainEth)
+			if err != nil {
+				log.Fatalf("setup aggregate client: %v", err)
+			}
+			if cfg.RPC.RateLimit > 0 {
+				chainClient.SetRateLimit(cfg.RPC.RateLimit, cfg.RPC.Burst)
+			}
+			chainClient.SetReadFinalized(cfg.RPC.ReadFinalized)
+			chainClient.SetDebugCalls(debugCalls)
+			clients[url] = chainClient
+		}
+		if err := service.SetChainClients(clients); err != nil {
+			log.Fatalf("aggregate RPCs: %v", err)
+		}
+	}
+
+	if backfillSince != "" {
+		since, err := parseSince(backfillSince, time.Now())
+		if err != nil {
+			log.Fatalf("parse --backfill-since: %v", err)
+		}
+		if err := service.Backfill(ctx, since); err != nil {
+			log.Fatalf("backfill: %v", err)
+		}
+	}
+
+	// api_addr predates http_addr and serves the same endpoints; both may be set.
+	for _, addr := range httpAddrs(cfg) {
+		httpServer := api.NewServer(addr, service, service, cfg.APIToken)
+		go func() {
+			if err := httpServer.Run(ctx); err != nil {
+				logger.Errorf("http server error: %v", err)
+			}
+		}()
+		logger.Infof("serving HTTP endpoints on %s", addr)
+	}
+
+	if cfg.TextfilePath != "" {
+		var interval time.Duration
+		if cfg.TextfileInterval != "" {
+			interval, err = time.ParseDuration(cfg.TextfileInterval)
+			if err != nil || interval <= 0 {
+				log.Fatalf("textfile_interval must be a positive duration: %q", cfg.TextfileInterval)
+			}
+		}
+		textfile := api.NewTextfileWriter(cfg.TextfilePath, interval, service)
+		go textfile.Run(ctx)
+		logger.Infof("writing metrics to %s", cfg.TextfilePath)
+	}
+
+	go reloadNotifiersOnSignal(ctx, configPath, service, incidents)
+
+	logger.Infof("monitoring %d asset(s) with poll interval %s", len(cfg.Assets), pollInterval)
 	if err := service.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
 		log.Fatalf("monitor run error: %v", err)
 	}
 
-	log.Println("shutdown complete")
+	logger.Infof("shutdown complete")
+}
+
+type dialRetry struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func parseDialRetry(cfg config.RetryConfig) (dialRetry, error) {
+	retry := dialRetry{
+		maxAttempts:    5,
+		initialBackoff: 2 * time.Second,
+		maxBackoff:     30 * time.Second,
+	}
+
+	if cfg.MaxAttempts < 0 {
+		return retry, fmt.Errorf("max_attempts must not be negative")
+	}
+	if cfg.MaxAttempts > 0 {
+		retry.maxAttempts = cfg.MaxAttempts
+	}
+
+	if cfg.InitialBackoff != "" {
+		d, err := time.ParseDuration(cfg.InitialBackoff)
+		if err != nil {
+			return retry, fmt.Errorf("initial_backoff: %w", err)
+		}
+		if d <= 0 {
+			return retry, fmt.Errorf("initial_backoff must be positive")
+		}
+		retry.initialBackoff = d
+	}
+
+	if cfg.MaxBackoff != "" {
+		d, err := time.ParseDuration(cfg.MaxBackoff)
+		if err != nil {
+			return retry, fmt.Errorf("max_backoff: %w", err)
+		}
+		if d <= 0 {
+			return retry, fmt.Errorf("max_backoff must be positive")
+		}
+		retry.maxBackoff = d
+	}
+
+	if retry.maxBackoff < retry.initialBackoff {
+		retry.maxBackoff = retry.initialBackoff
+	}
+	return retry, nil
 }
 
-func buildNotifiers(cfg *config.Config) ([]notify.Notifier, error) {
+// dialRPC connects to the endpoint and confirms it answers eth_chainId, retrying with
+// exponential backoff so a brief outage at deploy time doesn't kill the process.
+// HTTP dials never touch the network, so the chain ID probe is what proves reachability.
+func dialRPC(ctx context.Context, url string, retry dialRetry) (*ethclient.Client, error) {
+	backoff := retry.initialBackoff
+	var lastErr error
+
+	for attempt := 1; attempt <= retry.maxAttempts; attempt++ {
+		client, err := ethclient.DialContext(ctx, url)
+		if err == nil {
+			if _, err = client.ChainID(ctx); err == nil {
+				return client, nil
+			}
+			client.Close()
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt == retry.maxAttempts {
+			break
+		}
+
+		logger.Warnf("RPC dial attempt %d/%d failed: %v; retrying in %s", attempt, retry.maxAttempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > retry.maxBackoff {
+			backoff = retry.maxBackoff
+		}
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempt(s): %w", retry.maxAttempts, lastErr)
+}
+
+// verifyChainID fails when the endpoint's eth_chainId differs from expected_chain_id, so a
+// testnet RPC in a mainnet config stops startup instead of producing odd numbers. A zero
+// expected ID skips the check.
+func verifyChainID(ctx context.Context, client *ethclient.Client, expected uint64) error {
+	if expected == 0 {
+		return nil
+	}
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("read chain ID: %w", err)
+	}
+	if !id.IsUint64() || id.Uint64() != expected {
+		return fmt.Errorf("chain ID %s does not match expected_chain_id %d", id.String(), expected)
+	}
+	return nil
+}
+
+// httpAddrs returns the distinct configured listen addresses.
+func httpAddrs(cfg *config.Config) []string {
+	var addrs []string
+	for _, addr := range []string{cfg.HTTPAddr, cfg.APIAddr} {
+		if addr != "" && (len(addrs) == 0 || addrs[0] != addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+func buildNotifiers(cfg *config.Config) (_ []notify.Notifier, err error) {
 	notifiers := make([]notify.Notifier, 0, 2)
+	// A later notifier that fails to build must not leak the connections and files of
+	// those built before it, or every failed reload would leave them open.
+	defer func() {
+		if err != nil {
+			closeNotifiers(notifiers)
+		}
+	}()
+
+	userAgent := cfg.Notifications.UserAgent
+	if userAgent == "" {
+		userAgent = notify.DefaultUserAgent + "/" + version
+	}
+
+	pct, err := percentFormat(cfg)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := notify.ParseAmountFormat(cfg.Notifications.AmountFormat, cfg.Notifications.SignificantFigures)
+	if err != nil {
+		return nil, fmt.Errorf("amount_format: %w", err)
+	}
+
+	tmpl := cfg.Notifications.Templates
+	showRaw := true
+	if cfg.Notifications.ShowRaw != nil {
+		showRaw = *cfg.Notifications.ShowRaw
+	}
+	byAsset := make(map[string]string)
+	for _, asset := range cfg.Assets {
+		if asset.MessageTemplateFile == "" {
+			continue
+		}
+		text, err := readTemplateFile(asset.MessageTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %w", assetKey(asset), err)
+		}
+		byAsset[assetKey(asset)] = text
+	}
+	renderer, err := notify.NewRenderer(notify.RenderOptions{
+		DefaultTemplate: tmpl.Default,
+		ByType:          tmpl.ByType,
+		ByAsset:         byAsset,
+		Pct:             pct,
+		Amount:          amount,
+		ShowRaw:         showRaw,
+		Sparkline:       cfg.Notifications.IncludeSparkline,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("templates: %w", err)
+	}
 
 	if tg := cfg.Notifications.Telegram; tg != nil {
 		if tg.BotToken == "" {
@@ -86,15 +419,395 @@ func buildNotifiers(cfg *config.Config) ([]notify.Notifier, error) {
 		if tg.ChatID == "" {
 			return nil, fmt.Errorf("telegram.chat_id is required")
 		}
-		notifiers = append(notifiers, notify.NewTelegramNotifier(tg.BotToken, tg.ChatID))
+		chatRoutes := make([]notify.TelegramChatRoute, 0, len(tg.ChatRouting))
+		for i, rule := range tg.ChatRouting {
+			if rule.ChatID == "" {
+				return nil, fmt.Errorf("telegram.chat_routing[%d].chat_id is required", i)
+			}
+			eventTypes := make([]notify.EventType, 0, len(rule.EventTypes))
+			for _, name := range rule.EventTypes {
+				eventType, err := notify.ParseEventType(name)
+				if err != nil {
+					return nil, fmt.Errorf("telegram.chat_routing[%d].event_types: %w", i, err)
+				}
+				eventTypes = append(eventTypes, eventType)
+			}
+			chatRoutes = append(chatRoutes, notify.TelegramChatRoute{
+				Assets:     rule.Assets,
+				EventTypes: eventTypes,
+				ChatID:     rule.ChatID,
+			})
+		}
+		verbosity, err := notify.ParseVerbosity(tg.Verbosity, notify.VerbosityNormal)
+		if err != nil {
+			return nil, fmt.Errorf("telegram.verbosity: %w", err)
+		}
+		tgRenderer, err := notifierRenderer(renderer, tg.MessageTemplateFile, verbosity)
+		if err != nil {
+			return nil, fmt.Errorf("telegram: %w", err)
+		}
+		notifiers = append(notifiers, notify.NewTelegramNotifier(tg.BotToken, tg.ChatID, chatRoutes, userAgent, tgRenderer))
 	}
 
 	if rpc := cfg.Notifications.JSONRPC; rpc != nil {
 		if rpc.URL == "" {
 			return nil, fmt.Errorf("json_rpc.url is required")
 		}
-		notifiers = append(notifiers, notify.NewJSONRPCNotifier(rpc.URL))
+		if rpc.SignatureHeader != "" && rpc.SigningSecret == "" {
+			return nil, fmt.Errorf("json_rpc.signature_header requires json_rpc.signing_secret")
+		}
+		if rpc.SignTimestamp && rpc.SigningSecret == "" {
+			return nil, fmt.Errorf("json_rpc.sign_timestamp requires json_rpc.signing_secret")
+		}
+		verbosity, err := notify.ParseVerbosity(rpc.Verbosity, notify.VerbosityCompact)
+		if err != nil {
+			return nil, fmt.Errorf("json_rpc.verbosity: %w", err)
+		}
+		format, err := notify.ParseJSONRPCFormat(rpc.Format)
+		if err != nil {
+			return nil, fmt.Errorf("json_rpc.format: %w", err)
+		}
+		rpcRenderer, err := notifierRenderer(renderer, rpc.MessageTemplateFile, verbosity)
+		if err != nil {
+			return nil, fmt.Errorf("json_rpc: %w", err)
+		}
+		notifier := notify.NewJSONRPCNotifier(rpc.URL, format, rpc.Method, userAgent, rpcRenderer)
+		notifiers = append(notifiers, notifier.WithSigningSecret(rpc.SigningSecret, rpc.SignatureHeader, rpc.SignTimestamp))
+	}
+
+	if wh := cfg.Notifications.Webhook; wh != nil {
+		if wh.SignatureHeader != "" && wh.SigningSecret == "" {
+			return nil, fmt.Errorf("webhook.signature_header requires webhook.signing_secret")
+		}
+		if wh.SignTimestamp && wh.SigningSecret == "" {
+			return nil, fmt.Errorf("webhook.sign_timestamp requires webhook.signing_secret")
+		}
+		notifier, err := notify.NewWebhookNotifier(wh.URL, wh.BodyTemplate, wh.ContentType, userAgent, renderer)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier.WithSigningSecret(wh.SigningSecret, wh.SignatureHeader, wh.SignTimestamp))
+	}
+
+	if og := cfg.Notifications.OpsGenie; og != nil {
+		if og.APIKey == "" {
+			return nil, fmt.Errorf("opsgenie.api_key is required")
+		}
+		eventTypes := make([]notify.EventType, 0, len(og.EventTypes))
+		for _, name := range og.EventTypes {
+			eventType, err := notify.ParseEventType(name)
+			if err != nil {
+				return nil, fmt.Errorf("opsgenie.event_types: %w", err)
+			}
+			eventTypes = append(eventTypes, eventType)
+		}
+		verbosity, err := notify.ParseVerbosity(og.Verbosity, notify.VerbosityNormal)
+		if err != nil {
+			return nil, fmt.Errorf("opsgenie.verbosity: %w", err)
+		}
+		ogRenderer, err := notifierRenderer(renderer, og.MessageTemplateFile, verbosity)
+		if err != nil {
+			return nil, fmt.Errorf("opsgenie: %w", err)
+		}
+		ogRenderer, err = ogRenderer.WithSubjectTemplate(og.SubjectTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("opsgenie.subject_template: %w", err)
+		}
+		notifier, err := notify.NewOpsGenieNotifier(og.APIKey, og.APIURL, og.Priority, eventTypes, userAgent, ogRenderer)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if db := cfg.Notifications.SQLite; db != nil {
+		if db.Path == "" {
+			return nil, fmt.Errorf("sqlite.path is required")
+		}
+		notifier, err := notify.NewSQLiteNotifier(db.Path, db.Chain)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if mq := cfg.Notifications.AMQP; mq != nil {
+		if mq.URI == "" {
+			return nil, fmt.Errorf("amqp.uri is required")
+		}
+		notifier, err := notify.NewAMQPNotifier(mq.URI, mq.Exchange, mq.RoutingKey, pct)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if gc := cfg.Notifications.GRPC; gc != nil {
+		opts := notify.GRPCOptions{Address: gc.Address, MaxAttempts: gc.MaxAttempts, Pct: pct}
+		if gc.Timeout != "" {
+			timeout, err := time.ParseDuration(gc.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("parse grpc.timeout: %w", err)
+			}
+			opts.Timeout = timeout
+		}
+		if t := gc.TLS; t != nil {
+			opts.TLS = &notify.GRPCTLSOptions{
+				CAFile:             t.CAFile,
+				CertFile:           t.CertFile,
+				KeyFile:            t.KeyFile,
+				ServerName:         t.ServerName,
+				InsecureSkipVerify: t.InsecureSkipVerify,
+			}
+		}
+		notifier, err := notify.NewGRPCNotifier(opts)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if sl := cfg.Notifications.Slack; sl != nil {
+		if sl.WebhookURL == "" {
+			return nil, fmt.Errorf("slack.webhook_url is required")
+		}
+		notifiers = append(notifiers, notify.NewSlackNotifier(sl.WebhookURL).WithUserAgent(userAgent).WithRenderer(renderer))
+	}
+
+	if em := cfg.Notifications.Email; em != nil {
+		switch {
+		case em.Host == "":
+			return nil, fmt.Errorf("email.host is required")
+		case em.Port == 0:
+			return nil, fmt.Errorf("email.port is required")
+		case em.From == "":
+			return nil, fmt.Errorf("email.from is required")
+		case len(em.Recipients) == 0:
+			return nil, fmt.Errorf("email.recipients is required")
+		}
+		verbosity, err := notify.ParseVerbosity(em.Verbosity, notify.VerbosityNormal)
+		if err != nil {
+			return nil, fmt.Errorf("email.verbosity: %w", err)
+		}
+		emRenderer, err := notifierRenderer(renderer, em.MessageTemplateFile, verbosity)
+		if err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+		emRenderer, err = emRenderer.WithSubjectTemplate(em.SubjectTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("email.subject_template: %w", err)
+		}
+		notifier, err := notify.NewEmailNotifier(notify.EmailOptions{
+			Host:       em.Host,
+			Port:       em.Port,
+			Username:   em.Username,
+			Password:   em.Password,
+			From:       em.From,
+			Recipients: em.Recipients,
+		}, emRenderer)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if ns := cfg.Notifications.Nostr; ns != nil {
+		if ns.PrivateKey == "" {
+			return nil, fmt.Errorf("nostr.private_key is required")
+		}
+		verbosity, err := notify.ParseVerbosity(ns.Verbosity, notify.VerbosityNormal)
+		if err != nil {
+			return nil, fmt.Errorf("nostr.verbosity: %w", err)
+		}
+		nsRenderer, err := notifierRenderer(renderer, ns.MessageTemplateFile, verbosity)
+		if err != nil {
+			return nil, fmt.Errorf("nostr: %w", err)
+		}
+		notifier, err := notify.NewNostrNotifier(ns.PrivateKey, ns.Relays, userAgent, nsRenderer)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, notifier)
+	}
+
+	if cfg.Notifications.Stdout {
+		notifiers = append(notifiers, notify.NewStdoutNotifier(pct))
 	}
 
 	return notifiers, nil
 }
+
+// percentFormat parses the notifications.percent settings.
+func percentFormat(cfg *config.Config) (notify.PctFormat, error) {
+	pct, err := notify.ParsePctFormat(cfg.Notifications.Percent.Decimals, cfg.Notifications.Percent.Rounding)
+	if err != nil {
+		return pct, fmt.Errorf("percent: %w", err)
+	}
+	return pct, nil
+}
+
+// stdoutFallback is the notifier used when none is configured. buildNotifiers has
+// already rejected invalid percent settings.
+// closeNotifiers releases notifiers that hold resources, such as connections or open
+// database files.
+func closeNotifiers(notifiers []notify.Notifier) {
+	for _, n := range notifiers {
+		if closer, ok := n.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Warnf("close notifier %s: %v", n.Name(), err)
+			}
+		}
+	}
+}
+
+func stdoutFallback(cfg *config.Config) notify.Notifier {
+	pct, _ := percentFormat(cfg)
+	return notify.NewStdoutNotifier(pct)
+}
+
+// withIncidents wraps every notifier with the incident tracker; a nil tracker leaves them
+// unchanged.
+func withIncidents(notifiers []notify.Notifier, incidents *notify.IncidentTracker) []notify.Notifier {
+	if incidents == nil {
+		return notifiers
+	}
+	wrapped := make([]notify.Notifier, len(notifiers))
+	for i, n := range notifiers {
+		wrapped[i] = incidents.Wrap(n)
+	}
+	return wrapped
+}
+
+// notifierRenderer applies a notifier's verbosity and, when set, its
+// message_template_file as the default template.
+func notifierRenderer(renderer *notify.Renderer, templateFile string, verbosity notify.Verbosity) (*notify.Renderer, error) {
+	renderer = renderer.WithVerbosity(verbosity)
+	if templateFile == "" {
+		return renderer, nil
+	}
+	text, err := readTemplateFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	return renderer.WithDefaultTemplate(templateFile, text)
+}
+
+// readTemplateFile loads a message_template_file; parse errors surface when the renderer
+// is built.
+func readTemplateFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("message_template_file: %w", err)
+	}
+	return string(data), nil
+}
+
+// assetKey is the name an asset's events carry before symbols are resolved: its name,
+// then its symbol, then its address.
+func assetKey(asset config.AssetConfig) string {
+	switch {
+	case asset.Name != "":
+		return asset.Name
+	case asset.Symbol != "":
+		return asset.Symbol
+	}
+	return asset.Address
+}
+
+// reloadNotifiersOnSignal re-reads the config file on SIGUSR1 and swaps in notifiers
+// built from its notifications section, so rotated tokens and webhook URLs take effect
+// without restarting watchers. The incident tracker built at startup is kept so open
+// incidents carry over. A config that fails to load or validate is logged and
+// the running notifiers are kept.
+func reloadNotifiersOnSignal(ctx context.Context, configPath string, service *monitor.Service, incidents *notify.IncidentTracker) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			logger.Errorf("notifier reload: %v; keeping current notifiers", err)
+			continue
+		}
+		notifiers, err := buildNotifiers(cfg)
+		if err != nil {
+			logger.Errorf("notifier reload: configure notifiers: %v; keeping current notifiers", err)
+			continue
+		}
+		if len(notifiers) == 0 {
+			notifiers = append(notifiers, stdoutFallback(cfg))
+		}
+		notifiers = withIncidents(notifiers, incidents)
+		if err := service.ReloadNotifiers(notifiers, cfg.Notifications); err != nil {
+			logger.Errorf("notifier reload: %v; keeping current notifiers", err)
+			closeNotifiers(notifiers)
+			continue
+		}
+		logger.Infof("notifiers reloaded from %s: %d notifier(s)", configPath, len(notifiers))
+	}
+}
+
+// simulate applies the state overrides in path and prints, per asset, the current and
+// simulated values and the triggers the simulated value would fire.
+func simulate(ctx context.Context, service *monitor.Service, path string) error {
+	overrides, err := aave.LoadStateOverrides(path)
+	if err != nil {
+		return err
+	}
+	results, err := service.Simulate(ctx, overrides)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fmt.Printf("%s %s: %s -> %s\n", r.Asset, r.Metric, r.Current.String(), r.Simulated.String())
+		if r.EventType == "" {
+			fmt.Println("  no triggers would fire")
+			continue
+		}
+		fmt.Printf("  would notify %s: %s\n", r.EventType, strings.Join(r.Reasons, "; "))
+	}
+	return nil
+}
+
+// parseSince accepts a positive duration, meaning that long before now, or an RFC 3339
+// timestamp.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("duration %q must be positive", value)
+		}
+		return now.Add(-d), nil
+	}
+	ts, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor an RFC 3339 time", value)
+	}
+	if ts.After(now) {
+		return time.Time{}, fmt.Errorf("%q is in the future", value)
+	}
+	return ts, nil
+}
+
+// configureLogging applies log_level and the per-module log_levels overrides.
+func configureLogging(cfg *config.Config) error {
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("log_level: %w", err)
+	}
+	modules := make(map[string]logging.Level, len(cfg.LogLevels))
+	for module, name := range cfg.LogLevels {
+		moduleLevel, err := logging.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("log_levels.%s: %w", module, err)
+		}
+		modules[module] = moduleLevel
+	}
+	logging.Configure(level, modules)
+	return nil
+}
@@ -2,17 +2,25 @@ package aave
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/time/rate"
+
+	"aave-cap-alerts/internal/logging"
 )
 
+var logger = logging.New("aave")
+
 const scaledSupplyABIJSON = `[
     {
         "inputs": [],
@@ -43,6 +51,38 @@ const erc20ABIJSON = `[
         "stateMutability": "view",
         "type": "function"
     },
+    {
+        "inputs": [
+            {
+                "internalType": "address",
+                "name": "account",
+                "type": "address"
+            }
+        ],
+        "name": "balanceOf",
+        "outputs": [
+            {
+                "internalType": "uint256",
+                "name": "",
+                "type": "uint256"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [],
+        "name": "symbol",
+        "outputs": [
+            {
+                "internalType": "string",
+                "name": "",
+                "type": "string"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
     {
         "inputs": [],
         "name": "totalSupply",
@@ -58,17 +98,84 @@ const erc20ABIJSON = `[
     }
 ]`
 
+const underlyingABIJSON = `[
+    {
+        "inputs": [],
+        "name": "UNDERLYING_ASSET_ADDRESS",
+        "outputs": [
+            {
+                "internalType": "address",
+                "name": "",
+                "type": "address"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// ErrNoContractData is returned when an eth_call succeeds but returns no data, which almost
+// always means the configured address has no code or is not the expected contract.
+var ErrNoContractData = errors.New("no data returned: address has no code or is not the expected contract")
+
+// ErrUnexpectedResult is returned when a call succeeds but its result cannot be decoded
+// as expected; retrying the call will not help.
+var ErrUnexpectedResult = errors.New("unexpected contract result")
+
+// Backend is the chain access the client needs. *ethclient.Client implements it, as does
+// the client of go-ethereum's simulated backend used in integration tests.
+type Backend interface {
+	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.ContractCaller
+	ethereum.LogFilterer
+	ethereum.BlockNumberReader
+	ethereum.ChainIDReader
+}
+
+// rpcBackend is a Backend that also exposes its JSON-RPC connection, which batch reads
+// and state overrides need.
+type rpcBackend interface {
+	Client() *rpc.Client
+}
+
 // Client wraps the low-level contract calls we need.
 type Client struct {
-	backend        *ethclient.Client
+	backend        Backend
 	supplyABI      abi.ABI
 	erc20ABI       abi.ABI
+	underlyingABI  abi.ABI
+	poolABI        abi.ABI
+	multicall3ABI  abi.ABI
+	limiter        *rate.Limiter
 	decimalsCache  map[common.Address]uint8
 	decimalsLocker sync.RWMutex
+	latestBlock    latestBlockCache
+	// overrides, when set, are applied to every contract call.
+	overrides StateOverrides
+	// readFinalized pins contract calls to the finalized block; finalizedUnsupported
+	// records that the endpoint rejected the tag.
+	readFinalized        bool
+	finalizedUnsupported atomic.Bool
+	// debugCalls logs every call's full calldata and raw result at debug level.
+	debugCalls bool
+	// multicall3, when set, is the contract batch reads go through; batchUnsupported
+	// records that the endpoint rejected a JSON-RPC batch.
+	multicall3       *common.Address
+	batchUnsupported atomic.Bool
 }
 
-// NewClient builds a client that can query scaled supply and ERC20 metadata.
-func NewClient(backend *ethclient.Client) (*Client, error) {
+// SetDebugCalls logs the packed calldata and raw hex result of every contract call at
+// debug level, for diagnosing ABI mismatches and proxy behaviour. It has no effect unless
+// the aave module logs at debug.
+func (c *Client) SetDebugCalls(enabled bool) {
+	c.debugCalls = enabled
+}
+
+// NewClient builds a client that can query scaled supply and ERC20 metadata. A backend
+// without a JSON-RPC connection reads batches one call at a time and cannot apply state
+// overrides.
+func NewClient(backend Backend) (*Client, error) {
 	supplyABI, err := abi.JSON(strings.NewReader(scaledSupplyABIJSON))
 	if err != nil {
 		return nil, fmt.Errorf("parse scaled supply ABI: %w", err)
@@ -79,12 +186,90 @@ func NewClient(backend *ethclient.Client) (*Client, error) {
 		return nil, fmt.Errorf("parse erc20 ABI: %w", err)
 	}
 
-	return &Client{
+	underlyingABI, err := abi.JSON(strings.NewReader(underlyingABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse underlying ABI: %w", err)
+	}
+
+	poolABI, err := abi.JSON(strings.NewReader(poolABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse pool ABI: %w", err)
+	}
+
+	multicall3ABI, err := abi.JSON(strings.NewReader(multicall3ABIJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse Multicall3 ABI: %w", err)
+	}
+
+	c := &Client{
 		backend:       backend,
 		supplyABI:     supplyABI,
 		erc20ABI:      erc20ABI,
+		underlyingABI: underlyingABI,
+		poolABI:       poolABI,
+		multicall3ABI: multicall3ABI,
 		decimalsCache: make(map[common.Address]uint8),
-	}, nil
+	}
+	if _, ok := backend.(rpcBackend); !ok {
+		c.batchUnsupported.Store(true)
+	}
+	return c, nil
+}
+
+// rpcClient returns the backend's JSON-RPC connection, or an error when it has none.
+func (c *Client) rpcClient() (*rpc.Client, error) {
+	b, ok := c.backend.(rpcBackend)
+	if !ok {
+		return nil, fmt.Errorf("backend %T has no JSON-RPC connection", c.backend)
+	}
+	return b.Client(), nil
+}
+
+// callContract performs an eth_call at the latest block, or the finalized block with
+// SetReadFinalized, once the rate limiter allows it. Empty return data is reported as
+// ErrNoContractData rather than left to fail decoding.
+func (c *Client) callContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	block, err := c.readBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.callContractAt(ctx, call, block)
+}
+
+// callContractAt is callContract at a given block; nil means latest. Historical blocks
+// need an archive node.
+func (c *Client) callContractAt(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	if logger.Enabled(logging.LevelDebug) {
+		at := "latest"
+		if block != nil {
+			at = block.String()
+		}
+		logger.Debugf("eth_call %s selector %x at %s", call.To.Hex(), call.Data[:min(4, len(call.Data))], at)
+	}
+	var raw []byte
+	var err error
+	if c.overrides != nil {
+		raw, err = c.CallContractWithOverrides(ctx, call, block, c.overrides)
+	} else {
+		raw, err = c.backend.CallContract(ctx, call, block)
+	}
+	if c.debugCalls && logger.Enabled(logging.LevelDebug) {
+		if err != nil {
+			logger.Debugf("eth_call %s data %s failed: %v", call.To.Hex(), hexutil.Encode(call.Data), err)
+		} else {
+			logger.Debugf("eth_call %s data %s returned %s", call.To.Hex(), hexutil.Encode(call.Data), hexutil.Encode(raw))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s: %w", call.To.Hex(), ErrNoContractData)
+	}
+	return raw, nil
 }
 
 // ScaledTotalSupply fetches the current scaled total supply for an aToken.
@@ -95,7 +280,7 @@ func (c *Client) ScaledTotalSupply(ctx context.Context, asset common.Address) (*
 	}
 
 	call := ethereum.CallMsg{To: &asset, Data: payload}
-	raw, err := c.backend.CallContract(ctx, call, nil)
+	raw, err := c.callContract(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("call scaledTotalSupply: %w", err)
 	}
@@ -126,13 +311,18 @@ func (c *Client) Decimals(ctx context.Context, asset common.Address) (uint8, err
 	}
 	c.decimalsLocker.RUnlock()
 
+	return c.RefreshDecimals(ctx, asset)
+}
+
+// RefreshDecimals reads decimals from the chain, bypassing and then updating the cache.
+func (c *Client) RefreshDecimals(ctx context.Context, asset common.Address) (uint8, error) {
 	payload, err := c.erc20ABI.Pack("decimals")
 	if err != nil {
 		return 0, fmt.Errorf("pack decimals call: %w", err)
 	}
 
 	call := ethereum.CallMsg{To: &asset, Data: payload}
-	raw, err := c.backend.CallContract(ctx, call, nil)
+	raw, err := c.callContract(ctx, call)
 	if err != nil {
 		return 0, fmt.Errorf("call decimals: %w", err)
 	}
@@ -161,13 +351,18 @@ func (c *Client) Decimals(ctx context.Context, asset common.Address) (uint8, err
 
 // TotalSupply returns the current ERC20 totalSupply() value.
 func (c *Client) TotalSupply(ctx context.Context, asset common.Address) (*big.Int, error) {
+	return c.TotalSupplyAt(ctx, asset, nil)
+}
+
+// TotalSupplyAt returns totalSupply as of the given block; nil means latest.
+func (c *Client) TotalSupplyAt(ctx context.Context, asset common.Address, block *big.Int) (*big.Int, error) {
 	payload, err := c.erc20ABI.Pack("totalSupply")
 	if err != nil {
 		return nil, fmt.Errorf("pack totalSupply call: %w", err)
 	}
 
 	call := ethereum.CallMsg{To: &asset, Data: payload}
-	raw, err := c.backend.CallContract(ctx, call, nil)
+	raw, err := c.callContractAt(ctx, call, block)
 	if err != nil {
 		return nil, fmt.Errorf("call totalSupply: %w", err)
 	}
@@ -188,3 +383,111 @@ func (c *Client) TotalSupply(ctx context.Context, asset common.Address) (*big.In
 
 	return new(big.Int).Set(supply), nil
 }
+
+// BalanceOf returns the ERC20 balanceOf(holder) value for the token.
+func (c *Client) BalanceOf(ctx context.Context, asset, holder common.Address) (*big.Int, error) {
+	return c.BalanceOfAt(ctx, asset, holder, nil)
+}
+
+// BalanceOfAt returns balanceOf(holder) as of the given block; nil means latest.
+func (c *Client) BalanceOfAt(ctx context.Context, asset, holder common.Address, block *big.Int) (*big.Int, error) {
+	payload, err := c.erc20ABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, fmt.Errorf("pack balanceOf call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &asset, Data: payload}
+	raw, err := c.callContractAt(ctx, call, block)
+	if err != nil {
+		return nil, fmt.Errorf("call balanceOf: %w", err)
+	}
+
+	values, err := c.erc20ABI.Unpack("balanceOf", raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack balanceOf: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected balanceOf result length: %d", len(values))
+	}
+
+	balance, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected balanceOf type %T", values[0])
+	}
+
+	return new(big.Int).Set(balance), nil
+}
+
+// UnderlyingAsset returns the reserve asset backing an aToken.
+func (c *Client) UnderlyingAsset(ctx context.Context, aToken common.Address) (common.Address, error) {
+	payload, err := c.underlyingABI.Pack("UNDERLYING_ASSET_ADDRESS")
+	if err != nil {
+		return common.Address{}, fmt.Errorf("pack UNDERLYING_ASSET_ADDRESS call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &aToken, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("call UNDERLYING_ASSET_ADDRESS: %w", err)
+	}
+
+	values, err := c.underlyingABI.Unpack("UNDERLYING_ASSET_ADDRESS", raw)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("unpack UNDERLYING_ASSET_ADDRESS: %w", err)
+	}
+
+	if len(values) != 1 {
+		return common.Address{}, fmt.Errorf("unexpected UNDERLYING_ASSET_ADDRESS result length: %d", len(values))
+	}
+
+	underlying, ok := values[0].(common.Address)
+	if !ok {
+		return common.Address{}, fmt.Errorf("unexpected UNDERLYING_ASSET_ADDRESS type %T", values[0])
+	}
+
+	return underlying, nil
+}
+
+// Symbol fetches an ERC20 token's symbol. Tokens that return bytes32 instead of a
+// string fail to decode and return an error.
+func (c *Client) Symbol(ctx context.Context, token common.Address) (string, error) {
+	payload, err := c.erc20ABI.Pack("symbol")
+	if err != nil {
+		return "", fmt.Errorf("pack symbol call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &token, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return "", fmt.Errorf("call symbol: %w", err)
+	}
+
+	values, err := c.erc20ABI.Unpack("symbol", raw)
+	if err != nil {
+		return "", fmt.Errorf("unpack symbol: %w: %v", ErrUnexpectedResult, err)
+	}
+
+	if len(values) != 1 {
+		return "", fmt.Errorf("unexpected symbol result length: %d", len(values))
+	}
+
+	symbol, ok := values[0].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected symbol type %T", values[0])
+	}
+
+	return symbol, nil
+}
+
+// BlockNumber returns the latest block number known to the RPC endpoint.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	if err := c.wait(ctx); err != nil {
+		return 0, err
+	}
+	number, err := c.backend.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("fetch block number: %w", err)
+	}
+	return number, nil
+}
@@ -0,0 +1,256 @@
+package aave
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"aave-cap-alerts/internal/logging"
+)
+
+const multicall3ABIJSON = `[
+    {
+        "inputs": [
+            {
+                "components": [
+                    {"internalType": "address", "name": "target", "type": "address"},
+                    {"internalType": "bool", "name": "allowFailure", "type": "bool"},
+                    {"internalType": "bytes", "name": "callData", "type": "bytes"}
+                ],
+                "internalType": "struct Multicall3.Call3[]",
+                "name": "calls",
+                "type": "tuple[]"
+            }
+        ],
+        "name": "aggregate3",
+        "outputs": [
+            {
+                "components": [
+                    {"internalType": "bool", "name": "success", "type": "bool"},
+                    {"internalType": "bytes", "name": "returnData", "type": "bytes"}
+                ],
+                "internalType": "struct Multicall3.Result[]",
+                "name": "returnData",
+                "type": "tuple[]"
+            }
+        ],
+        "stateMutability": "payable",
+        "type": "function"
+    }
+]`
+
+// multicall3Call and multicall3Result mirror Multicall3's Call3 and Result structs.
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// SupplyResult is one token's outcome in a batch read: its supply, or why it could not
+// be read.
+type SupplyResult struct {
+	Supply *big.Int
+	Err    error
+}
+
+// SetMulticall3 makes batch reads go through the Multicall3 contract at address, as one
+// eth_call, instead of a JSON-RPC batch request.
+func (c *Client) SetMulticall3(address common.Address) {
+	c.multicall3 = &address
+}
+
+// BatchTotalSupply reads totalSupply() of every token in one round-trip: through
+// Multicall3 when SetMulticall3 is set, otherwise as a JSON-RPC batch of eth_calls. The
+// batch counts as one request for the rate limiter. Results are in the order of assets;
+// the error is set only when the batch as a whole failed. Endpoints that reject batch
+// requests are remembered and read one call at a time instead.
+func (c *Client) BatchTotalSupply(ctx context.Context, assets []common.Address) ([]SupplyResult, error) {
+	payload, err := c.erc20ABI.Pack("totalSupply")
+	if err != nil {
+		return nil, fmt.Errorf("pack totalSupply call: %w", err)
+	}
+	block, err := c.readBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws [][]byte
+	var errs []error
+	switch {
+	case c.multicall3 != nil:
+		raws, errs, err = c.multicallSame(ctx, assets, payload, block)
+	case !c.batchUnsupported.Load():
+		raws, errs, err = c.batchCallSame(ctx, assets, payload, block)
+		if err != nil && ctx.Err() == nil && batchRejected(err) {
+			c.batchUnsupported.Store(true)
+			logger.Warnf("JSON-RPC batch request rejected (%v); reading one call at a time from now on", err)
+			raws, errs, err = c.callEachSame(ctx, assets, payload, block)
+		}
+	default:
+		raws, errs, err = c.callEachSame(ctx, assets, payload, block)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SupplyResult, len(assets))
+	for i, raw := range raws {
+		if errs[i] != nil {
+			results[i].Err = fmt.Errorf("call totalSupply: %w", errs[i])
+			continue
+		}
+		values, err := c.erc20ABI.Unpack("totalSupply", raw)
+		if err != nil {
+			results[i].Err = fmt.Errorf("unpack totalSupply: %w", err)
+			continue
+		}
+		if len(values) != 1 {
+			results[i].Err = fmt.Errorf("%w: totalSupply returned %d value(s)", ErrUnexpectedResult, len(values))
+			continue
+		}
+		supply, ok := values[0].(*big.Int)
+		if !ok {
+			results[i].Err = fmt.Errorf("%w: totalSupply type %T", ErrUnexpectedResult, values[0])
+			continue
+		}
+		results[i].Supply = new(big.Int).Set(supply)
+	}
+	return results, nil
+}
+
+// batchRejected reports whether err means the endpoint does not accept batch requests,
+// as opposed to a transient transport failure: it answered with a JSON-RPC error, a
+// single response object where the batch array belongs, or an HTTP 4xx status.
+func batchRejected(err error) bool {
+	var rpcErr rpc.Error
+	var httpErr rpc.HTTPError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 400 && httpErr.StatusCode < 500
+	}
+	return errors.As(err, &rpcErr) || errors.As(err, &typeErr)
+}
+
+// batchCallSame sends payload to every target as one JSON-RPC batch of eth_calls at block
+// (nil means latest). The error reports a failed batch; errs holds each call's own.
+func (c *Client) batchCallSame(ctx context.Context, targets []common.Address, payload []byte, block *big.Int) ([][]byte, []error, error) {
+	client, err := c.rpcClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.wait(ctx); err != nil {
+		return nil, nil, err
+	}
+	blockArg := "latest"
+	if block != nil {
+		blockArg = hexutil.EncodeBig(block)
+	}
+	raws := make([]hexutil.Bytes, len(targets))
+	elems := make([]rpc.BatchElem, len(targets))
+	for i := range targets {
+		// Geth reads "input" and older nodes "data"; both are accepted when they agree.
+		args := []any{map[string]any{"to": targets[i], "input": hexutil.Bytes(payload), "data": hexutil.Bytes(payload)}, blockArg}
+		if c.overrides != nil {
+			args = append(args, c.overrides)
+		}
+		elems[i] = rpc.BatchElem{Method: "eth_call", Args: args, Result: &raws[i]}
+	}
+	logger.Debugf("eth_call batch of %d call(s) selector %x at %s", len(targets), payload[:min(4, len(payload))], blockArg)
+	if err := client.BatchCallContext(ctx, elems); err != nil {
+		return nil, nil, fmt.Errorf("batch eth_call: %w", err)
+	}
+
+	out := make([][]byte, len(targets))
+	errs := make([]error, len(targets))
+	for i, elem := range elems {
+		c.logBatchCall(targets[i], payload, raws[i], elem.Error)
+		switch {
+		case elem.Error != nil:
+			errs[i] = elem.Error
+		case len(raws[i]) == 0:
+			errs[i] = fmt.Errorf("%s: %w", targets[i].Hex(), ErrNoContractData)
+		default:
+			out[i] = raws[i]
+		}
+	}
+	return out, errs, nil
+}
+
+// multicallSame sends payload to every target through Multicall3's aggregate3, allowing
+// each call to fail on its own.
+func (c *Client) multicallSame(ctx context.Context, targets []common.Address, payload []byte, block *big.Int) ([][]byte, []error, error) {
+	calls := make([]multicall3Call, len(targets))
+	for i, target := range targets {
+		calls[i] = multicall3Call{Target: target, AllowFailure: true, CallData: payload}
+	}
+	data, err := c.multicall3ABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, nil, fmt.Errorf("pack aggregate3 call: %w", err)
+	}
+	raw, err := c.callContractAt(ctx, ethereum.CallMsg{To: c.multicall3, Data: data}, block)
+	if err != nil {
+		return nil, nil, fmt.Errorf("call Multicall3 aggregate3: %w", err)
+	}
+	values, err := c.multicall3ABI.Unpack("aggregate3", raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unpack aggregate3: %w", err)
+	}
+	if len(values) != 1 {
+		return nil, nil, fmt.Errorf("%w: aggregate3 returned %d value(s)", ErrUnexpectedResult, len(values))
+	}
+	results := *abi.ConvertType(values[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(results) != len(targets) {
+		return nil, nil, fmt.Errorf("%w: aggregate3 returned %d result(s) for %d call(s)", ErrUnexpectedResult, len(results), len(targets))
+	}
+
+	out := make([][]byte, len(targets))
+	errs := make([]error, len(targets))
+	for i, result := range results {
+		switch {
+		case !result.Success:
+			errs[i] = fmt.Errorf("%s: call reverted", targets[i].Hex())
+		case len(result.ReturnData) == 0:
+			errs[i] = fmt.Errorf("%s: %w", targets[i].Hex(), ErrNoContractData)
+		default:
+			out[i] = result.ReturnData
+		}
+	}
+	return out, errs, nil
+}
+
+// callEachSame is the unbatched fallback: one eth_call per target.
+func (c *Client) callEachSame(ctx context.Context, targets []common.Address, payload []byte, block *big.Int) ([][]byte, []error, error) {
+	out := make([][]byte, len(targets))
+	errs := make([]error, len(targets))
+	for i := range targets {
+		out[i], errs[i] = c.callContractAt(ctx, ethereum.CallMsg{To: &targets[i], Data: payload}, block)
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+	}
+	return out, errs, nil
+}
+
+func (c *Client) logBatchCall(target common.Address, payload, raw []byte, err error) {
+	if !c.debugCalls || !logger.Enabled(logging.LevelDebug) {
+		return
+	}
+	if err != nil {
+		logger.Debugf("eth_call %s data %s failed: %v", target.Hex(), hexutil.Encode(payload), err)
+	} else {
+		logger.Debugf("eth_call %s data %s returned %s", target.Hex(), hexutil.Encode(payload), hexutil.Encode(raw))
+	}
+}
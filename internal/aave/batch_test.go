@@ -0,0 +1,36 @@
+package aave
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// jsonRPCError is an rpc.Error as returned for a request the endpoint refused.
+type jsonRPCError struct{}
+
+func (jsonRPCError) Error() string  { return "batch requests are not supported" }
+func (jsonRPCError) ErrorCode() int { return -32600 }
+
+func TestBatchRejectedOnlyForRefusals(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("batch eth_call: %w", jsonRPCError{}), true},
+		{fmt.Errorf("batch eth_call: %w", rpc.HTTPError{StatusCode: http.StatusMethodNotAllowed}), true},
+		{fmt.Errorf("batch eth_call: %w", &json.UnmarshalTypeError{Value: "object"}), true},
+		{rpc.HTTPError{StatusCode: http.StatusBadGateway}, false},
+		{fmt.Errorf("batch eth_call: %w", errors.New("connection reset by peer")), false},
+		{fmt.Errorf("batch eth_call: %w", errors.New("context deadline exceeded")), false},
+	}
+	for _, tt := range tests {
+		if got := batchRejected(tt.err); got != tt.want {
+			t.Errorf("batchRejected(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
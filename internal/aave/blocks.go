@@ -0,0 +1,107 @@
+package aave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrBeforeGenesis is returned by BlockByTimestamp when the requested time predates the
+// first block the endpoint can serve.
+var ErrBeforeGenesis = errors.New("timestamp is before the genesis block")
+
+// header fetches a block header, or the latest header when number is nil.
+func (c *Client) header(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	logger.Debugf("eth_getBlockByNumber %v", number)
+	head, err := c.backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		if number == nil {
+			return nil, fmt.Errorf("fetch latest header: %w", err)
+		}
+		return nil, fmt.Errorf("fetch header %s: %w", number, err)
+	}
+	return head, nil
+}
+
+// latestBlockTTL is how long a latest header is reused. Watchers polling on the same
+// interval check at nearly the same moment, so they share one header read per cycle
+// instead of each fetching their own.
+const latestBlockTTL = 2 * time.Second
+
+// latestBlockCache holds the most recent LatestBlock result.
+type latestBlockCache struct {
+	mu        sync.Mutex
+	number    uint64
+	timestamp time.Time
+	fetchedAt time.Time
+}
+
+// LatestBlock returns the number and timestamp of the latest block header, or of the
+// finalized header when reads are pinned to it with SetReadFinalized. Results are shared
+// between callers for latestBlockTTL; concurrent callers wait for a single read.
+func (c *Client) LatestBlock(ctx context.Context) (uint64, time.Time, error) {
+	cache := &c.latestBlock
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if !cache.fetchedAt.IsZero() && time.Since(cache.fetchedAt) < latestBlockTTL {
+		return cache.number, cache.timestamp, nil
+	}
+
+	head, err := c.readHeader(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	cache.number = head.Number.Uint64()
+	cache.timestamp = time.Unix(int64(head.Time), 0).UTC()
+	cache.fetchedAt = time.Now()
+	return cache.number, cache.timestamp, nil
+}
+
+// BlockByTimestamp returns the number of the last block mined at or before ts. It binary
+// searches block headers rather than estimating from an average block time, so it stays
+// correct on chains with irregular block intervals; it only relies on timestamps being
+// non-decreasing. Reading state at old blocks additionally requires an archive node.
+func (c *Client) BlockByTimestamp(ctx context.Context, ts time.Time) (uint64, error) {
+	target := uint64(ts.Unix())
+
+	latest, err := c.header(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if latest.Time <= target {
+		return latest.Number.Uint64(), nil
+	}
+
+	genesis, err := c.header(ctx, big.NewInt(0))
+	if err != nil {
+		return 0, err
+	}
+	if genesis.Time > target {
+		return 0, ErrBeforeGenesis
+	}
+
+	// Invariant: block lo is at or before target, block hi is after it.
+	lo, hi := uint64(0), latest.Number.Uint64()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		head, err := c.header(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if head.Time <= target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
@@ -0,0 +1,67 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultRequiredMethods are the RPC methods every poll depends on.
+var DefaultRequiredMethods = []string{"eth_getBlockByNumber", "eth_call"}
+
+// capabilityProbes issue a cheap request exercising each supported method.
+var capabilityProbes = map[string]func(ctx context.Context, c *Client) error{
+	"eth_chainId": func(ctx context.Context, c *Client) error {
+		_, err := c.backend.ChainID(ctx)
+		return err
+	},
+	"eth_blockNumber": func(ctx context.Context, c *Client) error {
+		_, err := c.backend.BlockNumber(ctx)
+		return err
+	},
+	"eth_getBlockByNumber": func(ctx context.Context, c *Client) error {
+		_, err := c.backend.HeaderByNumber(ctx, nil)
+		return err
+	},
+	"eth_call": func(ctx context.Context, c *Client) error {
+		// A call to the zero address executes no code and succeeds on any compliant node.
+		to := common.Address{}
+		_, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &to}, nil)
+		return err
+	},
+	"eth_getBalance": func(ctx context.Context, c *Client) error {
+		_, err := c.backend.BalanceAt(ctx, common.Address{}, nil)
+		return err
+	},
+	"eth_subscribe": func(ctx context.Context, c *Client) error {
+		heads := make(chan *types.Header, 1)
+		sub, err := c.backend.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			return err
+		}
+		sub.Unsubscribe()
+		return nil
+	},
+}
+
+// VerifyCapabilities confirms the endpoint answers each named RPC method so an incompatible
+// or restricted provider fails at startup with the missing method named, rather than
+// obscurely on the first check.
+func (c *Client) VerifyCapabilities(ctx context.Context, methods []string) error {
+	for _, method := range methods {
+		probe, ok := capabilityProbes[method]
+		if !ok {
+			return fmt.Errorf("no capability probe for RPC method %q", method)
+		}
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		if err := probe(ctx, c); err != nil {
+			return fmt.Errorf("RPC endpoint does not support %s: %w", method, err)
+		}
+	}
+	return nil
+}
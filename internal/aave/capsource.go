@@ -0,0 +1,140 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultCapMethod is the AaveProtocolDataProvider method used when no custom method is configured.
+const DefaultCapMethod = "getReserveCaps"
+
+// DefaultCapOutput is the output field holding the supply cap in the standard data provider.
+const DefaultCapOutput = "supplyCap"
+
+const dataProviderCapsABIJSON = `[
+    {
+        "inputs": [
+            {
+                "internalType": "address",
+                "name": "asset",
+                "type": "address"
+            }
+        ],
+        "name": "getReserveCaps",
+        "outputs": [
+            {
+                "internalType": "uint256",
+                "name": "borrowCap",
+                "type": "uint256"
+            },
+            {
+                "internalType": "uint256",
+                "name": "supplyCap",
+                "type": "uint256"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// CapSource describes the contract call used to look up a reserve supply cap.
+// The method must accept the underlying asset address as its only argument.
+type CapSource struct {
+	address common.Address
+	abi     abi.ABI
+	method  string
+	output  int
+}
+
+// NewCapSource builds a cap source. An empty ABI and method fall back to the canonical
+// Aave v3 data provider getReserveCaps call; output names the returned value holding the
+// supply cap and may be empty when the method only returns a single value.
+func NewCapSource(address common.Address, abiJSON, method, output string) (*CapSource, error) {
+	if abiJSON == "" {
+		abiJSON = dataProviderCapsABIJSON
+		if method == "" {
+			method = DefaultCapMethod
+		}
+		if output == "" {
+			output = DefaultCapOutput
+		}
+	}
+	if method == "" {
+		return nil, fmt.Errorf("cap source method must be provided with a custom ABI")
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("parse cap source ABI: %w", err)
+	}
+
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("cap source ABI has no method %q", method)
+	}
+	if len(m.Inputs) != 1 || m.Inputs[0].Type.T != abi.AddressTy {
+		return nil, fmt.Errorf("cap source method %q must take a single address argument", method)
+	}
+
+	index := -1
+	switch {
+	case output != "":
+		for i, out := range m.Outputs {
+			if out.Name == output {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("cap source method %q has no output named %q", method, output)
+		}
+	case len(m.Outputs) == 1:
+		index = 0
+	default:
+		return nil, fmt.Errorf("cap source method %q returns %d values; output must be set", method, len(m.Outputs))
+	}
+
+	return &CapSource{
+		address: address,
+		abi:     parsed,
+		method:  method,
+		output:  index,
+	}, nil
+}
+
+// SupplyCap fetches the supply cap, in whole tokens, for the given underlying reserve asset.
+func (c *Client) SupplyCap(ctx context.Context, source *CapSource, underlying common.Address) (*big.Int, error) {
+	payload, err := source.abi.Pack(source.method, underlying)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s call: %w", source.method, err)
+	}
+
+	call := ethereum.CallMsg{To: &source.address, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", source.method, err)
+	}
+
+	values, err := source.abi.Unpack(source.method, raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack %s: %w", source.method, err)
+	}
+
+	if len(values) <= source.output {
+		return nil, fmt.Errorf("unexpected %s result length: %d", source.method, len(values))
+	}
+
+	supplyCap, ok := values[source.output].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s type %T", source.method, values[source.output])
+	}
+
+	return new(big.Int).Set(supplyCap), nil
+}
@@ -0,0 +1,94 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DebtTokens returns the stable and variable debt tokens of the reserve for the given
+// underlying asset. The stable address is zero on reserves with stable borrowing removed.
+func (c *Client) DebtTokens(ctx context.Context, pool, underlying common.Address) (stable, variable common.Address, err error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	if data.VariableDebtTokenAddress == (common.Address{}) {
+		return common.Address{}, common.Address{}, fmt.Errorf("%w: reserve %s has no variable debt token", ErrUnexpectedResult, underlying.Hex())
+	}
+	return data.StableDebtTokenAddress, data.VariableDebtTokenAddress, nil
+}
+
+// ReserveDebt is a reserve's outstanding debt. Stable and Variable are each in their
+// debt token's own base units; Total is their sum in the underlying's Decimals.
+type ReserveDebt struct {
+	StableDebtToken   common.Address // zero when stable borrowing is disabled
+	VariableDebtToken common.Address
+	Stable            *big.Int
+	Variable          *big.Int
+	Total             *big.Int
+	Decimals          uint8
+}
+
+// TotalDebt reads the supplies of a reserve's debt tokens and sums them. Debt tokens are
+// expected to share the underlying's decimals; one that does not is rescaled to them
+// before summing, truncating any extra precision, so the total is always in the
+// underlying's units. A zero stable debt token contributes nothing.
+func (c *Client) TotalDebt(ctx context.Context, pool, underlying common.Address) (*ReserveDebt, error) {
+	stable, variable, err := c.DebtTokens(ctx, pool, underlying)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := c.Decimals(ctx, underlying)
+	if err != nil {
+		return nil, fmt.Errorf("read underlying decimals: %w", err)
+	}
+
+	debt := &ReserveDebt{
+		StableDebtToken:   stable,
+		VariableDebtToken: variable,
+		Stable:            new(big.Int),
+		Decimals:          decimals,
+	}
+	debt.Variable, err = c.TotalSupply(ctx, variable)
+	if err != nil {
+		return nil, fmt.Errorf("read variable debt: %w", err)
+	}
+	total, err := c.toDecimals(ctx, variable, debt.Variable, decimals)
+	if err != nil {
+		return nil, fmt.Errorf("read variable debt: %w", err)
+	}
+	if stable != (common.Address{}) {
+		debt.Stable, err = c.TotalSupply(ctx, stable)
+		if err != nil {
+			return nil, fmt.Errorf("read stable debt: %w", err)
+		}
+		scaled, err := c.toDecimals(ctx, stable, debt.Stable, decimals)
+		if err != nil {
+			return nil, fmt.Errorf("read stable debt: %w", err)
+		}
+		total.Add(total, scaled)
+	}
+	debt.Total = total
+	return debt, nil
+}
+
+// toDecimals converts amount, in token's base units, to want decimals.
+func (c *Client) toDecimals(ctx context.Context, token common.Address, amount *big.Int, want uint8) (*big.Int, error) {
+	have, err := c.Decimals(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if have == want {
+		return new(big.Int).Set(amount), nil
+	}
+	logger.Warnf("debt token %s has %d decimals, underlying has %d; rescaling", token.Hex(), have, want)
+	if have < want {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(want-have)), nil)
+		return new(big.Int).Mul(amount, scale), nil
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(have-want)), nil)
+	return new(big.Int).Quo(amount, scale), nil
+}
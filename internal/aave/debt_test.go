@@ -0,0 +1,112 @@
+package aave
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeReserveBackend answers getReserveData from the pool and decimals and totalSupply
+// for known tokens. Any other call fails.
+type fakeReserveBackend struct {
+	Backend
+	client   *Client
+	pool     common.Address
+	reserve  reserveData
+	decimals map[common.Address]uint8
+	supply   map[common.Address]*big.Int
+}
+
+func (f *fakeReserveBackend) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	selector := string(call.Data[:4])
+	switch {
+	case *call.To == f.pool && selector == string(f.client.poolABI.Methods["getReserveData"].ID):
+		return f.client.poolABI.Methods["getReserveData"].Outputs.Pack(f.reserve)
+	case selector == string(f.client.erc20ABI.Methods["decimals"].ID):
+		if d, ok := f.decimals[*call.To]; ok {
+			return f.client.erc20ABI.Methods["decimals"].Outputs.Pack(d)
+		}
+	case selector == string(f.client.erc20ABI.Methods["totalSupply"].ID):
+		if s, ok := f.supply[*call.To]; ok {
+			return f.client.erc20ABI.Methods["totalSupply"].Outputs.Pack(s)
+		}
+	}
+	return nil, fmt.Errorf("unexpected call to %s", call.To.Hex())
+}
+
+func newDebtClient(t *testing.T, stable, variable common.Address, decimals map[common.Address]uint8, supply map[common.Address]*big.Int) *Client {
+	t.Helper()
+	backend := &fakeReserveBackend{
+		pool:     common.HexToAddress("0x100"),
+		decimals: decimals,
+		supply:   supply,
+	}
+	backend.reserve.Configuration.Data = new(big.Int)
+	for _, v := range []**big.Int{
+		&backend.reserve.LiquidityIndex, &backend.reserve.CurrentLiquidityRate,
+		&backend.reserve.VariableBorrowIndex, &backend.reserve.CurrentVariableBorrowRate,
+		&backend.reserve.CurrentStableBorrowRate, &backend.reserve.LastUpdateTimestamp,
+		&backend.reserve.AccruedToTreasury, &backend.reserve.Unbacked, &backend.reserve.IsolationModeTotalDebt,
+	} {
+		*v = new(big.Int)
+	}
+	backend.reserve.StableDebtTokenAddress = stable
+	backend.reserve.VariableDebtTokenAddress = variable
+	client, err := NewClient(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.client = client
+	return client
+}
+
+func TestTotalDebt(t *testing.T) {
+	underlying := common.HexToAddress("0x1")
+	stable := common.HexToAddress("0x2")
+	variable := common.HexToAddress("0x3")
+	pool := common.HexToAddress("0x100")
+
+	t.Run("zero stable debt token", func(t *testing.T) {
+		c := newDebtClient(t, common.Address{}, variable,
+			map[common.Address]uint8{underlying: 6, variable: 6},
+			map[common.Address]*big.Int{variable: big.NewInt(1_500_000)})
+		debt, err := c.TotalDebt(context.Background(), pool, underlying)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if debt.Stable.Sign() != 0 || debt.Variable.Int64() != 1_500_000 || debt.Total.Int64() != 1_500_000 || debt.Decimals != 6 {
+			t.Fatalf("debt = %+v", debt)
+		}
+	})
+
+	t.Run("rescaled debt token decimals", func(t *testing.T) {
+		// The stable token has fewer decimals than the underlying and is scaled up; the
+		// variable one has more and is scaled down, dropping the extra precision.
+		c := newDebtClient(t, stable, variable,
+			map[common.Address]uint8{underlying: 6, stable: 4, variable: 8},
+			map[common.Address]*big.Int{stable: big.NewInt(25), variable: big.NewInt(1_234_567_899)})
+		debt, err := c.TotalDebt(context.Background(), pool, underlying)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if debt.Stable.Int64() != 25 || debt.Variable.Int64() != 1_234_567_899 {
+			t.Fatalf("raw debt = %s stable, %s variable; want them in their own units", debt.Stable, debt.Variable)
+		}
+		if want := int64(2_500 + 12_345_678); debt.Total.Int64() != want {
+			t.Fatalf("total = %s, want %d", debt.Total, want)
+		}
+	})
+
+	t.Run("missing variable debt token", func(t *testing.T) {
+		c := newDebtClient(t, stable, common.Address{},
+			map[common.Address]uint8{underlying: 6}, nil)
+		if _, err := c.TotalDebt(context.Background(), pool, underlying); !errors.Is(err, ErrUnexpectedResult) {
+			t.Fatalf("err = %v, want ErrUnexpectedResult", err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultExchangeRateMethod is the ERC-4626 conversion used when no method is configured.
+const DefaultExchangeRateMethod = "convertToAssets(uint256)"
+
+var exchangeRateSignature = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\((uint256)?\)$`)
+
+// ExchangeRate describes how a wrapper token converts its amounts to the underlying
+// asset. A method taking a uint256, such as ERC-4626 convertToAssets or wstETH
+// getStETHByWstETH, is called with the amount and returns the underlying amount. A
+// method without arguments, such as rETH getExchangeRate, returns a rate that is applied
+// as amount * rate / scale.
+type ExchangeRate struct {
+	selector []byte
+	withArg  bool
+	scale    *big.Int
+}
+
+// NewExchangeRate parses a method signature like "convertToAssets(uint256)" or
+// "getExchangeRate()". An empty signature uses DefaultExchangeRateMethod; scale is only
+// used by methods without arguments, where nil means 1e18.
+func NewExchangeRate(signature string, scale *big.Int) (*ExchangeRate, error) {
+	if signature == "" {
+		signature = DefaultExchangeRateMethod
+	}
+	match := exchangeRateSignature.FindStringSubmatch(signature)
+	if match == nil {
+		return nil, fmt.Errorf("exchange rate method %q must look like name(uint256) or name()", signature)
+	}
+	rate := &ExchangeRate{selector: crypto.Keccak256([]byte(signature))[:4], withArg: match[1] != ""}
+	if rate.withArg {
+		if scale != nil {
+			return nil, fmt.Errorf("exchange rate scale only applies to methods without arguments")
+		}
+		return rate, nil
+	}
+	if scale == nil {
+		rate.scale = big.NewInt(1e18)
+		return rate, nil
+	}
+	if scale.Sign() <= 0 {
+		return nil, fmt.Errorf("exchange rate scale must be positive")
+	}
+	rate.scale = new(big.Int).Set(scale)
+	return rate, nil
+}
+
+// ToUnderlying converts amount of the wrapper token to underlying units with the
+// wrapper's exchange rate method.
+func (c *Client) ToUnderlying(ctx context.Context, wrapper common.Address, rate *ExchangeRate, amount *big.Int) (*big.Int, error) {
+	payload := append([]byte(nil), rate.selector...)
+	if rate.withArg {
+		payload = append(payload, math.U256Bytes(new(big.Int).Set(amount))...)
+	}
+	raw, err := c.callContract(ctx, ethereum.CallMsg{To: &wrapper, Data: payload})
+	if err != nil {
+		return nil, fmt.Errorf("call exchange rate method: %w", err)
+	}
+	if len(raw) < 32 {
+		return nil, fmt.Errorf("exchange rate method returned %d bytes: %w", len(raw), ErrUnexpectedResult)
+	}
+	value := new(big.Int).SetBytes(raw[:32])
+	if rate.withArg {
+		return value, nil
+	}
+	value.Mul(value, amount)
+	return value.Quo(value, rate.scale), nil
+}
@@ -0,0 +1,62 @@
+package aave
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SetReadFinalized makes contract calls read state at the finalized block instead of
+// latest, so values are not reported from blocks that a reorg may still drop. Endpoints
+// that do not support the finalized tag fall back to latest; the fallback is logged once.
+func (c *Client) SetReadFinalized(enabled bool) {
+	c.readFinalized = enabled
+}
+
+// readHeader fetches the header that reads are made at: the finalized block when
+// SetReadFinalized is on and the endpoint supports the tag, otherwise the latest block.
+// Support is detected on first use and cached for the life of the client.
+func (c *Client) readHeader(ctx context.Context) (*types.Header, error) {
+	if !c.readFinalized || c.finalizedUnsupported.Load() {
+		return c.header(ctx, nil)
+	}
+	head, err := c.header(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err == nil {
+		return head, nil
+	}
+	if !finalizedTagRejected(err) {
+		return nil, err
+	}
+	if c.finalizedUnsupported.CompareAndSwap(false, true) {
+		logger.Warnf("RPC does not support the finalized block tag (%v); reading at the latest block", err)
+	}
+	return c.header(ctx, nil)
+}
+
+// finalizedTagRejected reports whether err means the endpoint does not serve the
+// finalized tag, as opposed to a transient transport failure: it returned a JSON-RPC
+// error for the request or no block at all.
+func finalizedTagRejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.Is(err, ethereum.NotFound) || errors.As(err, &rpcErr)
+}
+
+// readBlock returns the block number contract calls are pinned to, or nil to read at
+// latest.
+func (c *Client) readBlock(ctx context.Context) (*big.Int, error) {
+	if !c.readFinalized || c.finalizedUnsupported.Load() {
+		return nil, nil
+	}
+	number, _, err := c.LatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.finalizedUnsupported.Load() {
+		return nil, nil
+	}
+	return new(big.Int).SetUint64(number), nil
+}
@@ -0,0 +1,147 @@
+package aave
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// graphTopHoldersQuery orders by scaled balance, which ranks holders the same as their
+// current balance since every holder of a reserve shares one liquidity index.
+const graphTopHoldersQuery = `query ($aToken: String!, $first: Int!) {
+  userReserves(where: {reserve_: {aToken: $aToken}}, orderBy: scaledATokenBalance, orderDirection: desc, first: $first) {
+    user {
+      id
+    }
+  }
+}`
+
+const graphReserveQuery = `query ($aToken: String!) {
+  reserves(where: {aToken: $aToken}, first: 1) {
+    totalATokenSupply
+    decimals
+    supplyCap
+  }
+}`
+
+var graphHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// GraphReserve is the subset of an Aave subgraph reserve used as an RPC fallback.
+type GraphReserve struct {
+	TotalSupply *big.Int
+	Decimals    uint8
+	// SupplyCap is in whole tokens; zero means uncapped.
+	SupplyCap *big.Int
+}
+
+// GraphReserve reads an aToken's reserve from an Aave subgraph. The subgraph indexes with
+// some delay, so callers should treat the result as potentially stale and only use it
+// when the RPC is unavailable.
+func (c *Client) GraphReserve(ctx context.Context, graphURL string, aToken common.Address) (*GraphReserve, error) {
+	var data struct {
+		Reserves []struct {
+			TotalATokenSupply string `json:"totalATokenSupply"`
+			Decimals          uint8  `json:"decimals"`
+			SupplyCap         string `json:"supplyCap"`
+		} `json:"reserves"`
+	}
+	vars := map[string]any{"aToken": strings.ToLower(aToken.Hex())}
+	if err := graphQuery(ctx, graphURL, graphReserveQuery, vars, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Reserves) == 0 {
+		return nil, fmt.Errorf("graph has no reserve for aToken %s", aToken.Hex())
+	}
+
+	reserve := data.Reserves[0]
+	supply, ok := new(big.Int).SetString(reserve.TotalATokenSupply, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid graph totalATokenSupply %q", reserve.TotalATokenSupply)
+	}
+	supplyCap := new(big.Int)
+	if reserve.SupplyCap != "" {
+		if _, ok := supplyCap.SetString(reserve.SupplyCap, 10); !ok {
+			return nil, fmt.Errorf("invalid graph supplyCap %q", reserve.SupplyCap)
+		}
+	}
+
+	return &GraphReserve{TotalSupply: supply, Decimals: reserve.Decimals, SupplyCap: supplyCap}, nil
+}
+
+// GraphTopHolders returns up to n addresses with the largest aToken balances according to
+// an Aave subgraph, largest first. The subgraph lags the chain, so callers should read
+// the balances themselves rather than trust its ranking to be exact.
+func (c *Client) GraphTopHolders(ctx context.Context, graphURL string, aToken common.Address, n int) ([]common.Address, error) {
+	var data struct {
+		UserReserves []struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"userReserves"`
+	}
+	vars := map[string]any{"aToken": strings.ToLower(aToken.Hex()), "first": n}
+	if err := graphQuery(ctx, graphURL, graphTopHoldersQuery, vars, &data); err != nil {
+		return nil, err
+	}
+
+	holders := make([]common.Address, 0, len(data.UserReserves))
+	for _, reserve := range data.UserReserves {
+		if !common.IsHexAddress(reserve.User.ID) {
+			return nil, fmt.Errorf("invalid graph user id %q", reserve.User.ID)
+		}
+		holders = append(holders, common.HexToAddress(reserve.User.ID))
+	}
+	return holders, nil
+}
+
+// graphQuery posts a GraphQL query and decodes the response's data into out, reporting
+// the first GraphQL error if there is one.
+func graphQuery(ctx context.Context, graphURL, query string, vars map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("marshal graph query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, graphURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build graph request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := graphHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send graph request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("graph endpoint returned status %s", resp.Status)
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode graph response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("graph query error: %s", result.Errors[0].Message)
+	}
+	if len(result.Data) == 0 {
+		return fmt.Errorf("graph response has no data")
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("decode graph response: %w", err)
+	}
+	return nil
+}
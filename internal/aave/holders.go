@@ -0,0 +1,52 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// transferTopic is the topic of the ERC20 Transfer(address,address,uint256) event.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// TransferRecipients returns the distinct addresses that received token in Transfer
+// events between blocks from and to, inclusive. Mints are transfers from the zero
+// address, so every account that ever held a balance appears as a recipient.
+func (c *Client) TransferRecipients(ctx context.Context, token common.Address, from, to uint64) ([]common.Address, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	logger.Debugf("eth_getLogs %s Transfer blocks %d-%d", token.Hex(), from, to)
+	logs, err := c.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{token},
+		Topics:    [][]common.Hash{{transferTopic}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s Transfer logs %d-%d: %w", token.Hex(), from, to, err)
+	}
+
+	seen := make(map[common.Address]struct{}, len(logs))
+	recipients := make([]common.Address, 0, len(logs))
+	for _, log := range logs {
+		if len(log.Topics) < 3 {
+			// ERC721-style or malformed events without an indexed recipient.
+			continue
+		}
+		recipient := common.BytesToAddress(log.Topics[2].Bytes())
+		if recipient == (common.Address{}) {
+			continue
+		}
+		if _, ok := seen[recipient]; ok {
+			continue
+		}
+		seen[recipient] = struct{}{}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,436 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const poolABIJSON = `[
+    {
+        "inputs": [],
+        "name": "getReservesList",
+        "outputs": [
+            {
+                "internalType": "address[]",
+                "name": "",
+                "type": "address[]"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {
+                "internalType": "address",
+                "name": "asset",
+                "type": "address"
+            }
+        ],
+        "name": "getReserveData",
+        "outputs": [
+            {
+                "components": [
+                    {
+                        "components": [
+                            {
+                                "internalType": "uint256",
+                                "name": "data",
+                                "type": "uint256"
+                            }
+                        ],
+                        "internalType": "struct DataTypes.ReserveConfigurationMap",
+                        "name": "configuration",
+                        "type": "tuple"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "liquidityIndex",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "currentLiquidityRate",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "variableBorrowIndex",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "currentVariableBorrowRate",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "currentStableBorrowRate",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint40",
+                        "name": "lastUpdateTimestamp",
+                        "type": "uint40"
+                    },
+                    {
+                        "internalType": "uint16",
+                        "name": "id",
+                        "type": "uint16"
+                    },
+                    {
+                        "internalType": "address",
+                        "name": "aTokenAddress",
+                        "type": "address"
+                    },
+                    {
+                        "internalType": "address",
+                        "name": "stableDebtTokenAddress",
+                        "type": "address"
+                    },
+                    {
+                        "internalType": "address",
+                        "name": "variableDebtTokenAddress",
+                        "type": "address"
+                    },
+                    {
+                        "internalType": "address",
+                        "name": "interestRateStrategyAddress",
+                        "type": "address"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "accruedToTreasury",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "unbacked",
+                        "type": "uint128"
+                    },
+                    {
+                        "internalType": "uint128",
+                        "name": "isolationModeTotalDebt",
+                        "type": "uint128"
+                    }
+                ],
+                "internalType": "struct DataTypes.ReserveData",
+                "name": "",
+                "type": "tuple"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {
+                "internalType": "address",
+                "name": "asset",
+                "type": "address"
+            }
+        ],
+        "name": "getConfiguration",
+        "outputs": [
+            {
+                "components": [
+                    {
+                        "internalType": "uint256",
+                        "name": "data",
+                        "type": "uint256"
+                    }
+                ],
+                "internalType": "struct DataTypes.ReserveConfigurationMap",
+                "name": "",
+                "type": "tuple"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    },
+    {
+        "inputs": [
+            {
+                "internalType": "address",
+                "name": "asset",
+                "type": "address"
+            }
+        ],
+        "name": "getReserveNormalizedIncome",
+        "outputs": [
+            {
+                "internalType": "uint256",
+                "name": "",
+                "type": "uint256"
+            }
+        ],
+        "stateMutability": "view",
+        "type": "function"
+    }
+]`
+
+// LiquidityIndex returns the reserve's current normalized income from the Pool, i.e. the
+// liquidity index including interest accrued since the last update, in RAY (1e27) units.
+func (c *Client) LiquidityIndex(ctx context.Context, pool, underlying common.Address) (*big.Int, error) {
+	payload, err := c.poolABI.Pack("getReserveNormalizedIncome", underlying)
+	if err != nil {
+		return nil, fmt.Errorf("pack getReserveNormalizedIncome call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &pool, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("call getReserveNormalizedIncome: %w", err)
+	}
+
+	values, err := c.poolABI.Unpack("getReserveNormalizedIncome", raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack getReserveNormalizedIncome: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected getReserveNormalizedIncome result length: %d", len(values))
+	}
+
+	index, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected getReserveNormalizedIncome type %T", values[0])
+	}
+
+	return new(big.Int).Set(index), nil
+}
+
+// ray is Aave's fixed-point unit for indexes (1e27).
+var ray = new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+
+// ActualSupply returns an aToken's index-adjusted supply: scaledTotalSupply multiplied by
+// the reserve's current normalized income, rounded half up like Aave's rayMul. Unlike
+// totalSupply it is derived from the Pool's live index rather than the token contract.
+func (c *Client) ActualSupply(ctx context.Context, pool, aToken, underlying common.Address) (*big.Int, error) {
+	scaled, err := c.ScaledTotalSupply(ctx, aToken)
+	if err != nil {
+		return nil, err
+	}
+	index, err := c.LiquidityIndex(ctx, pool, underlying)
+	if err != nil {
+		return nil, err
+	}
+
+	product := new(big.Int).Mul(scaled, index)
+	product.Add(product, new(big.Int).Rsh(ray, 1))
+	return product.Quo(product, ray), nil
+}
+
+// reservePausedBit is the position of the paused flag in Aave v3's ReserveConfigurationMap.
+const reservePausedBit = 60
+
+// ReservesList returns the underlying assets of every reserve listed on the Pool.
+func (c *Client) ReservesList(ctx context.Context, pool common.Address) ([]common.Address, error) {
+	payload, err := c.poolABI.Pack("getReservesList")
+	if err != nil {
+		return nil, fmt.Errorf("pack getReservesList call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &pool, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("call getReservesList: %w", err)
+	}
+
+	values, err := c.poolABI.Unpack("getReservesList", raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack getReservesList: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected getReservesList result length: %d", len(values))
+	}
+
+	reserves, ok := values[0].([]common.Address)
+	if !ok {
+		return nil, fmt.Errorf("unexpected getReservesList type %T", values[0])
+	}
+
+	return reserves, nil
+}
+
+// ReserveConfiguration returns the packed ReserveConfigurationMap bitmap for a reserve.
+func (c *Client) ReserveConfiguration(ctx context.Context, pool, underlying common.Address) (*big.Int, error) {
+	payload, err := c.poolABI.Pack("getConfiguration", underlying)
+	if err != nil {
+		return nil, fmt.Errorf("pack getConfiguration call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &pool, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("call getConfiguration: %w", err)
+	}
+
+	values, err := c.poolABI.Unpack("getConfiguration", raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack getConfiguration: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected getConfiguration result length: %d", len(values))
+	}
+
+	configuration, ok := abi.ConvertType(values[0], new(struct{ Data *big.Int })).(*struct{ Data *big.Int })
+	if !ok || configuration.Data == nil {
+		return nil, fmt.Errorf("unexpected getConfiguration type %T", values[0])
+	}
+
+	return new(big.Int).Set(configuration.Data), nil
+}
+
+// ReservePaused reports whether the paused flag is set in a reserve configuration bitmap.
+func ReservePaused(configuration *big.Int) bool {
+	return configuration.Bit(reservePausedBit) == 1
+}
+
+// reserveData mirrors Aave v3's DataTypes.ReserveData field for field, as abi.ConvertType
+// matches struct fields by position.
+type reserveData struct {
+	Configuration               struct{ Data *big.Int }
+	LiquidityIndex              *big.Int
+	CurrentLiquidityRate        *big.Int
+	VariableBorrowIndex         *big.Int
+	CurrentVariableBorrowRate   *big.Int
+	CurrentStableBorrowRate     *big.Int
+	LastUpdateTimestamp         *big.Int
+	Id                          uint16
+	ATokenAddress               common.Address
+	StableDebtTokenAddress      common.Address
+	VariableDebtTokenAddress    common.Address
+	InterestRateStrategyAddress common.Address
+	AccruedToTreasury           *big.Int
+	Unbacked                    *big.Int
+	IsolationModeTotalDebt      *big.Int
+}
+
+// reserveData reads the reserve's full DataTypes.ReserveData from the Pool.
+func (c *Client) reserveData(ctx context.Context, pool, underlying common.Address) (*reserveData, error) {
+	payload, err := c.poolABI.Pack("getReserveData", underlying)
+	if err != nil {
+		return nil, fmt.Errorf("pack getReserveData call: %w", err)
+	}
+
+	call := ethereum.CallMsg{To: &pool, Data: payload}
+	raw, err := c.callContract(ctx, call)
+	if err != nil {
+		return nil, fmt.Errorf("call getReserveData: %w", err)
+	}
+
+	values, err := c.poolABI.Unpack("getReserveData", raw)
+	if err != nil {
+		return nil, fmt.Errorf("unpack getReserveData: %w", err)
+	}
+
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected getReserveData result length: %d", len(values))
+	}
+
+	data, ok := abi.ConvertType(values[0], new(reserveData)).(*reserveData)
+	if !ok || data.Configuration.Data == nil || data.AccruedToTreasury == nil || data.IsolationModeTotalDebt == nil {
+		return nil, fmt.Errorf("unexpected getReserveData type %T", values[0])
+	}
+
+	return data, nil
+}
+
+// AccruedToTreasury returns the protocol fees the reserve has accrued but not yet minted
+// to the treasury, as stored by the Pool (scaled by the liquidity index).
+func (c *Client) AccruedToTreasury(ctx context.Context, pool, underlying common.Address) (*big.Int, error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(data.AccruedToTreasury), nil
+}
+
+// ReserveRates are a reserve's current annual rates as percentages (5 == 5%).
+type ReserveRates struct {
+	LiquidityRate      *big.Rat
+	VariableBorrowRate *big.Rat
+}
+
+// Rates returns the reserve's current liquidity (supply) rate and variable borrow rate
+// from the Pool's reserve data.
+func (c *Client) Rates(ctx context.Context, pool, underlying common.Address) (*ReserveRates, error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return nil, err
+	}
+	if data.CurrentLiquidityRate == nil || data.CurrentVariableBorrowRate == nil {
+		return nil, fmt.Errorf("%w: getReserveData returned no rates", ErrUnexpectedResult)
+	}
+	return &ReserveRates{
+		LiquidityRate:      RayToPercent(data.CurrentLiquidityRate),
+		VariableBorrowRate: RayToPercent(data.CurrentVariableBorrowRate),
+	}, nil
+}
+
+// RayToPercent converts a RAY (1e27) fixed-point rate to a percentage, exactly.
+func RayToPercent(v *big.Int) *big.Rat {
+	return new(big.Rat).SetFrac(new(big.Int).Mul(v, big.NewInt(100)), ray)
+}
+
+// ATokenAddress returns the aToken of the reserve for the given underlying asset.
+func (c *Client) ATokenAddress(ctx context.Context, pool, underlying common.Address) (common.Address, error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return data.ATokenAddress, nil
+}
+
+// Debt ceiling position in Aave v3's ReserveConfigurationMap (bits 212-251).
+const (
+	debtCeilingStartBit = 212
+	debtCeilingBits     = 40
+)
+
+// DebtCeilingDecimals is the fixed-point precision of isolation-mode debt and ceilings.
+const DebtCeilingDecimals = 2
+
+// IsolationDebt returns the isolation-mode total debt and the debt ceiling of a reserve,
+// both in USD with DebtCeilingDecimals decimals. A zero ceiling means the asset is not
+// isolated.
+func (c *Client) IsolationDebt(ctx context.Context, pool, underlying common.Address) (*big.Int, *big.Int, error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), debtCeilingBits), big.NewInt(1))
+	ceiling := new(big.Int).Rsh(data.Configuration.Data, debtCeilingStartBit)
+	ceiling.And(ceiling, mask)
+
+	return new(big.Int).Set(data.IsolationModeTotalDebt), ceiling, nil
+}
+
+// eMode category position in Aave v3's ReserveConfigurationMap (bits 168-175).
+const (
+	eModeCategoryStartBit = 168
+	eModeCategoryBits     = 8
+)
+
+// EModeCategory returns the efficiency-mode category ID of a reserve, zero when it is in
+// no category. Pools from v3.2 on track eMode membership per category instead and leave
+// these bits at zero.
+func (c *Client) EModeCategory(ctx context.Context, pool, underlying common.Address) (uint8, error) {
+	data, err := c.reserveData(ctx, pool, underlying)
+	if err != nil {
+		return 0, err
+	}
+
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), eModeCategoryBits), big.NewInt(1))
+	category := new(big.Int).Rsh(data.Configuration.Data, eModeCategoryStartBit)
+	return uint8(category.And(category, mask).Uint64()), nil
+}
@@ -0,0 +1,29 @@
+package aave
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/time/rate"
+)
+
+// SetRateLimit makes every RPC request issued by the client wait on a shared token bucket
+// allowing requestsPerSecond with the given burst. It must be called before the client is
+// shared between goroutines.
+func (c *Client) SetRateLimit(requestsPerSecond float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+}
+
+// wait blocks until the rate limiter admits another request or the context is done.
+func (c *Client) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+	return nil
+}
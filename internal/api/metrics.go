@@ -0,0 +1,116 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"aave-cap-alerts/internal/monitor"
+)
+
+// writeSourceMetrics renders the current metrics of source.
+func writeSourceMetrics(w io.Writer, source StatusSource) {
+	var queue *monitor.QueueStats
+	if stats, ok := source.NotificationQueue(); ok {
+		queue = &stats
+	}
+	writeMetrics(w, source.Assets(), queue, source.Notifiers())
+}
+
+// writeMetrics renders watcher state in the Prometheus text exposition format. Supplies
+// are written as exact integers in base units; Prometheus parses them as floats.
+func writeMetrics(w io.Writer, statuses []monitor.AssetStatus, queue *monitor.QueueStats, notifiers []monitor.NotifierStats) {
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_last_total_supply Last observed value of the tracked metric, in base units.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_last_total_supply gauge")
+	for _, s := range statuses {
+		if s.LastTotalSupply != nil {
+			fmt.Fprintf(w, "aave_cap_alerts_last_total_supply{%s} %s\n", labels(s), *s.LastTotalSupply)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_target_total_supply Target threshold, in base units.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_target_total_supply gauge")
+	for _, s := range statuses {
+		if s.TargetTotalSupply != nil {
+			fmt.Fprintf(w, "aave_cap_alerts_target_total_supply{%s} %s\n", labels(s), *s.TargetTotalSupply)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_last_check_timestamp_seconds Unix time of the last completed check.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_last_check_timestamp_seconds gauge")
+	for _, s := range statuses {
+		if s.LastCheck != nil {
+			fmt.Fprintf(w, "aave_cap_alerts_last_check_timestamp_seconds{%s} %d\n", labels(s), s.LastCheck.Unix())
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_check_error Whether the last check failed (1) or succeeded (0).")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_check_error gauge")
+	for _, s := range statuses {
+		failed := 0
+		if s.LastError != "" {
+			failed = 1
+		}
+		fmt.Fprintf(w, "aave_cap_alerts_check_error{%s} %d\n", labels(s), failed)
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_check_duration_seconds Duration of the last completed check.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_check_duration_seconds gauge")
+	for _, s := range statuses {
+		if s.LastCheckSeconds != nil {
+			fmt.Fprintf(w, "aave_cap_alerts_check_duration_seconds{%s} %s\n", labels(s), strconv.FormatFloat(*s.LastCheckSeconds, 'g', -1, 64))
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_check_overruns_total Checks that took longer than the asset's poll interval.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_check_overruns_total counter")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "aave_cap_alerts_check_overruns_total{%s} %d\n", labels(s), s.CheckOverruns)
+	}
+
+	writeNotifierMetrics(w, notifiers)
+
+	if queue == nil {
+		return
+	}
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_notification_queue_depth Events waiting in the notification queue.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_notification_queue_depth gauge")
+	fmt.Fprintf(w, "aave_cap_alerts_notification_queue_depth %d\n", queue.Depth)
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_notification_queue_in_flight Queued events currently being delivered.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_notification_queue_in_flight gauge")
+	fmt.Fprintf(w, "aave_cap_alerts_notification_queue_in_flight %d\n", queue.InFlight)
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_notification_queue_dropped_total Events dropped by the drop_oldest overflow policy.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_notification_queue_dropped_total counter")
+	fmt.Fprintf(w, "aave_cap_alerts_notification_queue_dropped_total %d\n", queue.Dropped)
+}
+
+// writeNotifierMetrics renders the per-notifier delivery histogram and outcome counters.
+func writeNotifierMetrics(w io.Writer, notifiers []monitor.NotifierStats) {
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_notifier_duration_seconds Time spent delivering an event to a notifier.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_notifier_duration_seconds histogram")
+	for _, n := range notifiers {
+		name := labelEscaper.Replace(n.Name)
+		for i, bound := range monitor.NotifierDurationBuckets {
+			fmt.Fprintf(w, "aave_cap_alerts_notifier_duration_seconds_bucket{notifier=\"%s\",le=\"%s\"} %d\n",
+				name, strconv.FormatFloat(bound, 'g', -1, 64), n.BucketCounts[i])
+		}
+		fmt.Fprintf(w, "aave_cap_alerts_notifier_duration_seconds_bucket{notifier=\"%s\",le=\"+Inf\"} %d\n", name, n.Count)
+		fmt.Fprintf(w, "aave_cap_alerts_notifier_duration_seconds_sum{notifier=\"%s\"} %s\n", name, strconv.FormatFloat(n.DurationSum, 'f', -1, 64))
+		fmt.Fprintf(w, "aave_cap_alerts_notifier_duration_seconds_count{notifier=\"%s\"} %d\n", name, n.Count)
+	}
+
+	fmt.Fprintln(w, "# HELP aave_cap_alerts_notifier_deliveries_total Notifier delivery attempts by outcome.")
+	fmt.Fprintln(w, "# TYPE aave_cap_alerts_notifier_deliveries_total counter")
+	for _, n := range notifiers {
+		name := labelEscaper.Replace(n.Name)
+		fmt.Fprintf(w, "aave_cap_alerts_notifier_deliveries_total{notifier=\"%s\",result=\"success\"} %d\n", name, n.Successes)
+		fmt.Fprintf(w, "aave_cap_alerts_notifier_deliveries_total{notifier=\"%s\",result=\"failure\"} %d\n", name, n.Failures)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(s monitor.AssetStatus) string {
+	return fmt.Sprintf(`asset="%s",address="%s"`, labelEscaper.Replace(s.Name), s.Address)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"aave-cap-alerts/internal/logging"
+	"aave-cap-alerts/internal/monitor"
+)
+
+var logger = logging.New("api")
+
+// StatusSource exposes live watcher state to the API.
+type StatusSource interface {
+	Assets() []monitor.AssetStatus
+	// NotificationQueue reports queue state; ok is false when the queue is disabled.
+	NotificationQueue() (stats monitor.QueueStats, ok bool)
+	// Notifiers reports per-notifier delivery latency and outcomes.
+	Notifiers() []monitor.NotifierStats
+}
+
+// AssetController lets the API mute and unmute watchers at runtime.
+type AssetController interface {
+	SetPaused(address string, paused bool) error
+}
+
+// Server multiplexes the read-only introspection, health, and metrics endpoints on a
+// single listener.
+type Server struct {
+	httpServer *http.Server
+}
+
+// statusResponse is the body of /api/status.
+type statusResponse struct {
+	Ready  bool                  `json:"ready"`
+	Assets []monitor.AssetStatus `json:"assets"`
+}
+
+// NewServer builds a server listening on addr that serves /api/assets, /api/status,
+// /healthz, /readyz, and /metrics. When both control and token are set it also serves
+// POST /api/assets/{address}/pause and /resume, which require the token as a bearer
+// credential.
+func NewServer(addr string, source StatusSource, control AssetController, token string) *Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/assets", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, source.Assets())
+	}))
+	mux.HandleFunc("/api/status", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		assets := source.Assets()
+		writeJSON(w, statusResponse{Ready: ready(assets), Assets: assets})
+	}))
+	mux.HandleFunc("/healthz", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	}))
+	mux.HandleFunc("/readyz", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		if !ready(source.Assets()) {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}))
+	mux.HandleFunc("/metrics", getOnly(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeSourceMetrics(w, source)
+	}))
+
+	if control != nil && token != "" {
+		mux.HandleFunc("POST /api/assets/{address}/pause", requireToken(token, pauseHandler(control, true)))
+		mux.HandleFunc("POST /api/assets/{address}/resume", requireToken(token, pauseHandler(control, false)))
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Run serves requests until the context is cancelled, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("api server: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown api server: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("api server: %w", err)
+	}
+	return nil
+}
+
+func pauseHandler(control AssetController, paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		address := r.PathValue("address")
+		if err := control.SetPaused(address, paused); err != nil {
+			if errors.Is(err, monitor.ErrUnknownAsset) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{"address": address, "paused": paused})
+	}
+}
+
+// requireToken rejects requests that do not carry "Authorization: Bearer <token>".
+func requireToken(token string, handler http.HandlerFunc) http.HandlerFunc {
+	want := []byte("Bearer " + token)
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// ready reports whether every watcher has observed its metric at least once.
+func ready(assets []monitor.AssetStatus) bool {
+	for _, asset := range assets {
+		if asset.LastTotalSupply == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func getOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		logger.Warnf("api: encode response: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTextfileInterval is how often the metrics textfile is rewritten by default.
+const DefaultTextfileInterval = 15 * time.Second
+
+// TextfileWriter periodically writes the same metrics as /metrics to a file, for
+// node_exporter's textfile collector on hosts where a scrape port cannot be opened.
+type TextfileWriter struct {
+	path     string
+	interval time.Duration
+	source   StatusSource
+}
+
+// NewTextfileWriter builds a writer for path, which should end in .prom for the
+// collector to pick it up.
+func NewTextfileWriter(path string, interval time.Duration, source StatusSource) *TextfileWriter {
+	if interval <= 0 {
+		interval = DefaultTextfileInterval
+	}
+	return &TextfileWriter{path: path, interval: interval, source: source}
+}
+
+// Run writes the file immediately and then every interval until the context is
+// cancelled. Failed writes are logged and retried on the next tick.
+func (t *TextfileWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.write(); err != nil {
+			logger.Warnf("write metrics textfile: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// write renders the metrics to a temporary file in the target directory and renames it
+// over the target, so the collector never reads a partial file.
+func (t *TextfileWriter) write() error {
+	var buf bytes.Buffer
+	writeSourceMetrics(&buf, t.source)
+
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), "."+filepath.Base(t.path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", tmp.Name(), err)
+	}
+	// CreateTemp uses 0600; the collector usually runs as another user.
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), t.path); err != nil {
+		return fmt.Errorf("rename to %s: %w", t.path, err)
+	}
+	return nil
+}
@@ -10,37 +10,732 @@ import (
 
 // Config models the YAML configuration file that drives the monitor.
 type Config struct {
-	RPCURL        string        `yaml:"rpc_url"`
-	PollInterval  string        `yaml:"poll_interval"`
-	Assets        []AssetConfig `yaml:"assets"`
-	Notifications Notifications `yaml:"notifications"`
+	RPCURL string `yaml:"rpc_url"`
+	// ExpectedChainID, when set, must match eth_chainId on rpc_url and confirm_rpc_url.
+	ExpectedChainID uint64 `yaml:"expected_chain_id"`
+	PollInterval    string `yaml:"poll_interval"`
+	// LogLevel is error, warn, info (default), or debug; LogLevels overrides it per module
+	// (main, monitor, aave, api).
+	LogLevel  string            `yaml:"log_level"`
+	LogLevels map[string]string `yaml:"log_levels"`
+	DialRetry RetryConfig       `yaml:"dial_retry"`
+	// MetadataRetry retries one-time metadata reads (decimals, supply cap, underlying
+	// asset, symbols) within a check instead of waiting for the next poll.
+	MetadataRetry RetryConfig     `yaml:"metadata_retry"`
+	GraphURL      string          `yaml:"graph_url"`
+	ExplorerURL   string          `yaml:"explorer_url"`
+	Contracts     ContractsConfig `yaml:"contracts"`
+	PoolAddress   string          `yaml:"pool_address"`
+	RPCMethods    []string        `yaml:"required_rpc_methods"`
+	HTTPAddr      string          `yaml:"http_addr"`
+	APIAddr       string          `yaml:"api_addr"`
+	// TextfilePath periodically writes the /metrics output to a file for node_exporter's
+	// textfile collector, every TextfileInterval (default 15s).
+	TextfilePath     string `yaml:"textfile_path"`
+	TextfileInterval string `yaml:"textfile_interval"`
+	// APIToken enables the runtime pause/resume endpoints and is required as a bearer token.
+	APIToken string    `yaml:"api_token"`
+	RPC      RPCConfig `yaml:"rpc"`
+	// ConfirmRPCURL is a second provider used to re-read the value before target_reached
+	// alerts; ConfirmTolerancePct is the accepted difference and ConfirmOnDisagreement
+	// is "suppress" (default) or "flag".
+	ConfirmRPCURL         string `yaml:"confirm_rpc_url"`
+	ConfirmTolerancePct   string `yaml:"confirm_tolerance_pct"`
+	ConfirmOnDisagreement string `yaml:"confirm_on_disagreement"`
+	// StartupConcurrency bounds how many initial asset checks run at once (0 = unbounded);
+	// StartupStagger delays each asset's initial check by its position times this duration.
+	StartupConcurrency int    `yaml:"startup_concurrency"`
+	StartupStagger     string `yaml:"startup_stagger"`
+	// StrictStartup makes the process exit if any asset's first check fails.
+	StrictStartup bool `yaml:"strict_startup"`
+	// SchedulerWorkers, when positive, checks all assets from that many worker goroutines
+	// fed by a due-time queue instead of one goroutine per asset.
+	SchedulerWorkers int `yaml:"scheduler_workers"`
+	// DecimalsRecheckInterval re-reads token decimals this often and alerts on a change;
+	// empty disables the recheck.
+	DecimalsRecheckInterval string `yaml:"decimals_recheck_interval"`
+	// VerifyUnderlyingDecimals compares each aToken's decimals with its underlying's on
+	// the first read; a mismatch is a warning, or fails the check with StrictStartup.
+	VerifyUnderlyingDecimals bool `yaml:"verify_underlying_decimals"`
+	// StatePath keeps per-asset state, such as all-time highs, in this file across
+	// restarts; empty keeps it in memory only.
+	StatePath string `yaml:"state_path"`
+	// Snapshot periodically writes all watcher state to one file and restores it at
+	// startup.
+	Snapshot SnapshotConfig `yaml:"snapshot"`
+	// HA elects one leader among replicas; only the leader sends notifications.
+	HA            HAConfig             `yaml:"ha"`
+	ProtocolPause *ProtocolPauseConfig `yaml:"protocol_pause"`
+	// Pairs watch two correlated tokens for supply divergence.
+	Pairs []PairConfig `yaml:"pairs"`
+	// Aggregates sum the supplies of the same asset across chains.
+	Aggregates    []AggregateConfig `yaml:"aggregates"`
+	CapSource     *CapSourceConfig  `yaml:"cap_source"`
+	Assets        []AssetConfig     `yaml:"assets"`
+	Notifications Notifications     `yaml:"notifications"`
 }
 
+// Values accepted by Config.ConfirmOnDisagreement.
+const (
+	ConfirmSuppress = "suppress"
+	ConfirmFlag     = "flag"
+)
+
+// ContractsConfig lists the protocol contracts of the deployment being monitored, so forks
+// can point at their own addresses. PoolAddress and CapSource.Address remain accepted as
+// older spellings of Pool and PoolDataProvider.
+type ContractsConfig struct {
+	Pool             string `yaml:"pool"`
+	PoolDataProvider string `yaml:"pool_data_provider"`
+	Oracle           string `yaml:"oracle"`
+	PoolConfigurator string `yaml:"pool_configurator"`
+	// Multicall3 makes batched reads go through the Multicall3 contract at this address
+	// instead of JSON-RPC batch requests.
+	Multicall3 string `yaml:"multicall3"`
+}
+
+// RetryConfig bounds a retry loop with exponential backoff, such as how long startup keeps
+// retrying an unreachable RPC endpoint.
+type RetryConfig struct {
+	MaxAttempts    int    `yaml:"max_attempts"`
+	InitialBackoff string `yaml:"initial_backoff"`
+	MaxBackoff     string `yaml:"max_backoff"`
+}
+
+// RPCConfig tunes how the monitor uses the RPC endpoint. RateLimit caps requests per
+// second across all watchers (0 disables the limit); Burst defaults to 1.
+type RPCConfig struct {
+	RateLimit float64 `yaml:"rate_limit"`
+	Burst     int     `yaml:"burst"`
+	// ReadFinalized reads contract state at the finalized block, falling back to latest
+	// when the endpoint does not support the tag.
+	ReadFinalized bool `yaml:"read_finalized"`
+}
+
+// ProtocolPauseConfig enables the protocol-wide pause watcher, which polls the Pool at
+// pool_address. PollInterval defaults to the global poll interval.
+type ProtocolPauseConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	PollInterval string `yaml:"poll_interval"`
+}
+
+// PairConfig compares the total supplies of two tokens, normalized by their decimals, and
+// alerts when |first-second| / max(first, second) exceeds DivergencePct. PollInterval
+// defaults to the global poll interval.
+type PairConfig struct {
+	Name          string `yaml:"name"`
+	First         string `yaml:"first"`
+	Second        string `yaml:"second"`
+	DivergencePct string `yaml:"divergence_pct"`
+	PollInterval  string `yaml:"poll_interval"`
+}
+
+// AggregateConfig sums the decimals-normalized total supplies of its members, typically
+// the same asset on several chains, and alerts when the combined figure crosses
+// ThresholdTokens (whole tokens) from below. PollInterval defaults to the global poll
+// interval.
+type AggregateConfig struct {
+	Name            string                  `yaml:"name"`
+	ThresholdTokens string                  `yaml:"threshold_tokens"`
+	Members         []AggregateMemberConfig `yaml:"members"`
+	PollInterval    string                  `yaml:"poll_interval"`
+}
+
+// AggregateMemberConfig is one token in an aggregate, read through RPCURL (default: the
+// main rpc_url). It is given by Address, or by the underlying Symbol of a reserve in the
+// Pool at PoolAddress on that chain, whose aToken is then used. Chain labels the member
+// in events and defaults to its position.
+type AggregateMemberConfig struct {
+	Chain       string `yaml:"chain"`
+	RPCURL      string `yaml:"rpc_url"`
+	Address     string `yaml:"address"`
+	Symbol      string `yaml:"symbol"`
+	PoolAddress string `yaml:"pool_address"`
+}
+
+// ConcentrationConfig computes the share of an asset's total supply held by its TopN
+// (default 10) largest holders and alerts when it reaches ThresholdPct. Holders come from
+// Source: "events" scans Transfer logs from FromBlock in BlockRange-sized chunks (default
+// 10000), "subgraph" asks the Aave subgraph at GraphURL (default: the asset's graph_url)
+// for the largest positions. Both read current balances on-chain. PollInterval defaults
+// to one hour.
+type ConcentrationConfig struct {
+	ThresholdPct string `yaml:"concentration_threshold"`
+	TopN         int    `yaml:"top_n"`
+	Source       string `yaml:"source"`
+	GraphURL     string `yaml:"graph_url"`
+	FromBlock    uint64 `yaml:"from_block"`
+	BlockRange   uint64 `yaml:"block_range"`
+	PollInterval string `yaml:"poll_interval"`
+}
+
+// AdaptiveConfig sends the first VerboseChanges (default 3) supply changes within Window
+// (default 1h) as usual, then folds further changes into a digest sent every
+// DigestInterval (default 15m) until none has been seen for QuietPeriod (default: Window).
+type AdaptiveConfig struct {
+	VerboseChanges int    `yaml:"verbose_changes"`
+	Window         string `yaml:"window"`
+	DigestInterval string `yaml:"digest_interval"`
+	QuietPeriod    string `yaml:"quiet_period"`
+}
+
+// Values accepted by ConcentrationConfig.Source.
+const (
+	HolderSourceEvents   = "events"
+	HolderSourceSubgraph = "subgraph"
+)
+
 // AssetConfig describes a single aToken that should be monitored.
 type AssetConfig struct {
-	Name             string `yaml:"name"`
-	Address          string `yaml:"address"`
-	TargetCapTokens  string `yaml:"target_cap_tokens"`
-	NotifyOnIncrease *bool  `yaml:"notify_on_increase"`
-	NotifyOnDecrease *bool  `yaml:"notify_on_decrease"`
-	PollInterval     string `yaml:"poll_interval"`
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	// Symbol selects the asset by its underlying token's symbol instead of Address; it is
+	// resolved through the Pool's reserve list at startup.
+	Symbol string `yaml:"symbol"`
+	// Labels are free-form key/value tags carried on the asset's events and matched by
+	// notifier selectors.
+	Labels          map[string]string `yaml:"labels"`
+	TargetCapTokens string            `yaml:"target_cap_tokens"`
+	// TargetDirection is "above" (default) to alert when the value rises to the target,
+	// or "below" to alert when it falls to it, such as a drain toward a minimum.
+	TargetDirection string `yaml:"target_direction"`
+	// CapURL is fetched every CapURLTTL (default 5m) for the target, in the same raw
+	// formats as target_cap_tokens, which then only applies until the first fetch.
+	CapURL    string `yaml:"cap_url"`
+	CapURLTTL string `yaml:"cap_url_ttl"`
+	// CapToleranceTokens shifts the target by whole tokens: positive fires early, negative
+	// requires a margin above the target.
+	CapToleranceTokens string `yaml:"cap_tolerance_tokens"`
+	// CapETAWarn fires a cap_eta event when, at the rate seen over recent polls, the
+	// target would be reached within this duration.
+	CapETAWarn string `yaml:"cap_eta_warn"`
+	// AlertLevels are absolute levels in whole tokens; crossing any of them in either
+	// direction fires a level_crossed event.
+	AlertLevels []string `yaml:"alert_levels"`
+	// CapLevels escalate as the value approaches the target: each level fires a cap_level
+	// event, delivered only to its own notifiers, when the value reaches LevelPct percent
+	// of the target.
+	CapLevels []CapLevelConfig `yaml:"cap_levels"`
+	// TrackATH fires a supply_ath event whenever the tracked value exceeds the highest
+	// value seen since startup.
+	TrackATH         bool  `yaml:"track_ath"`
+	NotifyOnIncrease *bool `yaml:"notify_on_increase"`
+	NotifyOnDecrease *bool `yaml:"notify_on_decrease"`
+	// Triggers lists the enabled trigger types. When empty it is derived from
+	// notify_on_increase, notify_on_decrease, emode_change, cap_eta_warn, debt_ceiling_pct,
+	// and the always-on cap_reached.
+	Triggers      []string `yaml:"triggers"`
+	NotifyOnFirst bool     `yaml:"notify_on_first_observation"`
+	// Shadow evaluates triggers as usual but only logs what would have been sent.
+	Shadow       bool   `yaml:"shadow"`
+	PollInterval string `yaml:"poll_interval"`
+	UseSupplyCap bool   `yaml:"use_supply_cap"`
+	Track        string `yaml:"track"`
+	SupplyMetric string `yaml:"supply_metric"`
+	Holder       string `yaml:"holder"`
+	// DenominateIn is "underlying" to convert the tracked value of a wrapper token to
+	// the wrapped asset's units with ExchangeRate before any threshold is evaluated.
+	DenominateIn string             `yaml:"denominate_in"`
+	ExchangeRate ExchangeRateConfig `yaml:"exchange_rate"`
+	GraphURL     string             `yaml:"graph_url"`
+	ExplorerURL  string             `yaml:"explorer_url"`
+	IndexJumpPct string             `yaml:"index_jump_pct"`
+	// DebtCeilingPct alerts when isolation-mode debt reaches this percentage of the
+	// reserve's debt ceiling.
+	DebtCeilingPct string `yaml:"debt_ceiling_pct"`
+	// LiquidityRatePct and BorrowRatePct alert when the reserve's liquidity rate or
+	// variable borrow rate rises to this annual percentage.
+	LiquidityRatePct string `yaml:"liquidity_rate_pct"`
+	BorrowRatePct    string `yaml:"borrow_rate_pct"`
+	// Concentration alerts when the top holders' share of the supply reaches a threshold.
+	Concentration *ConcentrationConfig `yaml:"concentration"`
+	// TreasuryThreshold alerts when the reserve's accruedToTreasury crosses this value.
+	TreasuryThreshold string `yaml:"treasury_threshold"`
+	// EModeChange alerts when the reserve's efficiency-mode category changes; the
+	// state_change trigger replaces it when Triggers is set.
+	EModeChange      bool   `yaml:"emode_change"`
+	BaselineDeadband string `yaml:"baseline_deadband"`
+	// Reference pins the value increase_pct and decrease_pct compare against instead of
+	// the previous poll: session_start, daily (midnight UTC), or fixed (ReferenceValue).
+	Reference        string `yaml:"reference"`
+	ReferenceValue   string `yaml:"reference_value"`
+	MaxAlertsPerHour int    `yaml:"max_alerts_per_hour"`
+	CoalesceWindow   string `yaml:"coalesce_window"`
+	// ResumeGrace is how old a baseline restored from the snapshot may be and still be
+	// alerted against straight away; an older one waits for a second, confirming read.
+	ResumeGrace string `yaml:"resume_grace"`
+	// Adaptive switches the asset to periodic digests of its supply changes while it is
+	// busy.
+	Adaptive *AdaptiveConfig `yaml:"adaptive"`
+	// ReportSubthresholdChanges reports supply changes that fire no trigger instead of
+	// only logging them: "notify" sends each one as a subthreshold_change event, "digest"
+	// sends their accumulated drift as one event every SubthresholdDigestInterval
+	// (default 1h).
+	ReportSubthresholdChanges  string `yaml:"report_subthreshold_changes"`
+	SubthresholdDigestInterval string `yaml:"subthreshold_digest_interval"`
+	// MessageTemplateFile is a text/template file used for this asset's messages in
+	// place of the per-type and default templates.
+	MessageTemplateFile string `yaml:"message_template_file"`
+}
+
+// Values accepted by AssetConfig.Track.
+const (
+	TrackTotalSupply   = "total_supply"
+	TrackHolderBalance = "holder_balance"
+)
+
+// Values accepted by AssetConfig.TargetDirection.
+const (
+	TargetAbove = "above"
+	TargetBelow = "below"
+)
+
+// Values accepted in AssetConfig.Triggers.
+const (
+	TriggerIncreasePct   = "increase_pct"
+	TriggerDecreasePct   = "decrease_pct"
+	TriggerCapReached    = "cap_reached"
+	TriggerFloorBreached = "floor_breached"
+	TriggerVelocity      = "velocity"
+	TriggerStateChange   = "state_change"
+	TriggerUtilization   = "utilization"
+)
+
+// Values accepted by AssetConfig.ReportSubthresholdChanges.
+const (
+	SubthresholdNotify = "notify"
+	SubthresholdDigest = "digest"
+)
+
+// Values accepted by AssetConfig.Reference.
+const (
+	ReferenceSessionStart = "session_start"
+	ReferenceDaily        = "daily"
+	ReferenceFixed        = "fixed"
+)
+
+// CapLevelConfig is one escalation level of an asset's target. Notifiers are referenced
+// by Name() and replace the notification routes for this level's events.
+type CapLevelConfig struct {
+	LevelPct  string   `yaml:"level_pct"`
+	Notifiers []string `yaml:"notifiers"`
+}
+
+// ExchangeRateConfig is the wrapper method used by denominate_in: underlying. Method is a
+// signature taking the amount, like the default convertToAssets(uint256), or taking no
+// arguments and returning a rate applied as amount * rate / Scale (default 1e18).
+// Decimals are the underlying's, defaulting to the wrapper's.
+type ExchangeRateConfig struct {
+	Method   string `yaml:"method"`
+	Scale    string `yaml:"scale"`
+	Decimals *uint8 `yaml:"decimals"`
+}
+
+// Values accepted by AssetConfig.DenominateIn.
+const (
+	DenominateWrapper    = "wrapper"
+	DenominateUnderlying = "underlying"
+)
+
+// Values accepted by AssetConfig.SupplyMetric.
+const (
+	SupplyMetricTotal  = "total_supply"
+	SupplyMetricScaled = "scaled_total_supply"
+	SupplyMetricActual = "actual_supply"
+)
+
+// CapSourceConfig points at the contract that reports reserve supply caps.
+// ABI and Method default to the Aave v3 data provider's getReserveCaps.
+type CapSourceConfig struct {
+	Address string `yaml:"address"`
+	ABI     string `yaml:"abi"`
+	Method  string `yaml:"method"`
+	Output  string `yaml:"output"`
 }
 
 // Notifications holds optional downstream integrations.
 type Notifications struct {
-	Telegram *TelegramConfig `yaml:"telegram"`
-	JSONRPC  *JSONRPCConfig  `yaml:"json_rpc"`
+	Telegram  *TelegramConfig `yaml:"telegram"`
+	JSONRPC   *JSONRPCConfig  `yaml:"json_rpc"`
+	Stdout    bool            `yaml:"stdout"`
+	OpsGenie  *OpsGenieConfig `yaml:"opsgenie"`
+	SQLite    *SQLiteConfig   `yaml:"sqlite"`
+	AMQP      *AMQPConfig     `yaml:"amqp"`
+	GRPC      *GRPCConfig     `yaml:"grpc"`
+	Nostr     *NostrConfig    `yaml:"nostr"`
+	Slack     *SlackConfig    `yaml:"slack"`
+	Email     *EmailConfig    `yaml:"email"`
+	Webhook   *WebhookConfig  `yaml:"webhook"`
+	Templates TemplateConfig  `yaml:"templates"`
+	Percent   PercentConfig   `yaml:"percent"`
+	// ShowRaw appends exact base-unit values to human-readable amounts (default true).
+	ShowRaw *bool `yaml:"show_raw"`
+	// AmountFormat is grouped (default), full or siground; SignificantFigures sets the
+	// precision of siground (default 3).
+	AmountFormat       string `yaml:"amount_format"`
+	SignificantFigures *int   `yaml:"significant_figures"`
+	// IncludeSparkline adds a trend line of recent samples to built-in messages.
+	IncludeSparkline bool          `yaml:"include_sparkline"`
+	Routes           []RouteConfig `yaml:"routes"`
+	// UserAgent overrides the User-Agent sent by HTTP notifiers
+	// (default "aave-cap-alerts/<version>").
+	UserAgent string `yaml:"user_agent"`
+	// FailureFallback names notifiers that receive an event when every notifier it was
+	// routed to failed to deliver it.
+	FailureFallback []string `yaml:"failure_fallback"`
+	// Queue buffers events between checks and notifiers; disabled unless Size is set.
+	Queue QueueConfig `yaml:"queue"`
+	// ShutdownSummary sends the summary logged at shutdown to the notifiers as a
+	// shutdown_summary event.
+	ShutdownSummary bool `yaml:"shutdown_summary"`
+	// NotifyOnStartup sends one monitoring_started event once the service has started,
+	// after the strict startup checks when those are enabled.
+	NotifyOnStartup bool `yaml:"notify_on_startup"`
+	// QuietHours holds back non-critical events during a daily window.
+	QuietHours *QuietHoursConfig `yaml:"quiet_hours"`
+	// Incidents attaches incident IDs that tie a breach to its recovery across channels.
+	Incidents IncidentsConfig `yaml:"incidents"`
+}
+
+// Selectors returns the configured notifier selectors keyed by notifier name.
+func (n Notifications) Selectors() map[string]string {
+	selectors := make(map[string]string)
+	if n.Telegram != nil && n.Telegram.Selector != "" {
+		selectors["telegram"] = n.Telegram.Selector
+	}
+	if n.JSONRPC != nil && n.JSONRPC.Selector != "" {
+		selectors["json_rpc"] = n.JSONRPC.Selector
+	}
+	if n.OpsGenie != nil && n.OpsGenie.Selector != "" {
+		selectors["opsgenie"] = n.OpsGenie.Selector
+	}
+	if n.SQLite != nil && n.SQLite.Selector != "" {
+		selectors["sqlite"] = n.SQLite.Selector
+	}
+	if n.AMQP != nil && n.AMQP.Selector != "" {
+		selectors["amqp"] = n.AMQP.Selector
+	}
+	if n.GRPC != nil && n.GRPC.Selector != "" {
+		selectors["grpc"] = n.GRPC.Selector
+	}
+	if n.Nostr != nil && n.Nostr.Selector != "" {
+		selectors["nostr"] = n.Nostr.Selector
+	}
+	if n.Slack != nil && n.Slack.Selector != "" {
+		selectors["slack"] = n.Slack.Selector
+	}
+	if n.Email != nil && n.Email.Selector != "" {
+		selectors["email"] = n.Email.Selector
+	}
+	if n.Webhook != nil && n.Webhook.Selector != "" {
+		selectors["webhook"] = n.Webhook.Selector
+	}
+	return selectors
+}
+
+// QueueConfig configures the notification queue. Workers defaults to 1 and Overflow to
+// "block". PersistPath, when set, keeps pending events in that file across restarts.
+type QueueConfig struct {
+	Size        int    `yaml:"size"`
+	Workers     int    `yaml:"workers"`
+	Overflow    string `yaml:"overflow"`
+	PersistPath string `yaml:"persist_path"`
+}
+
+// SnapshotConfig enables the full state snapshot, written every Interval (default 5m)
+// and on shutdown. Backend selects where it is kept: "file" (the default) at Path, or
+// one value per asset in Redis or S3.
+type SnapshotConfig struct {
+	Backend  string               `yaml:"backend"`
+	Path     string               `yaml:"path"`
+	Interval string               `yaml:"interval"`
+	Redis    *SnapshotRedisConfig `yaml:"redis"`
+	S3       *SnapshotS3Config    `yaml:"s3"`
+}
+
+// HAConfig enables leader election between replicas through Mode's lock: "redis" or
+// "kubernetes" (a coordination.k8s.io Lease). Identity defaults to the hostname and
+// LeaseDuration to 15s.
+type HAConfig struct {
+	Mode          string              `yaml:"mode"`
+	Identity      string              `yaml:"identity"`
+	LeaseDuration string              `yaml:"lease_duration"`
+	Redis         *HARedisConfig      `yaml:"redis"`
+	Kubernetes    *HAKubernetesConfig `yaml:"kubernetes"`
+}
+
+// HA lock backends.
+const (
+	HAModeRedis      = "redis"
+	HAModeKubernetes = "kubernetes"
+)
+
+// HARedisConfig holds the leader lock as Key (default "aave-cap-alerts:leader") in the
+// Redis at URL.
+type HARedisConfig struct {
+	URL string `yaml:"url"`
+	Key string `yaml:"key"`
+}
+
+// HAKubernetesConfig holds the leader lock as the Lease LeaseName (default
+// "aave-cap-alerts") in Namespace (default: the pod's own).
+type HAKubernetesConfig struct {
+	Namespace string `yaml:"namespace"`
+	LeaseName string `yaml:"lease_name"`
+}
+
+// Snapshot backends.
+const (
+	SnapshotBackendFile  = "file"
+	SnapshotBackendRedis = "redis"
+	SnapshotBackendS3    = "s3"
+)
+
+// Shared reports whether the snapshot is kept in Redis or S3 rather than a local file.
+func (s SnapshotConfig) Shared() bool {
+	return s.Backend == SnapshotBackendRedis || s.Backend == SnapshotBackendS3
+}
+
+// SnapshotRedisConfig keeps the snapshot in Redis at URL
+// (redis://[user:password@]host[:port][/db], or rediss:// for TLS), under keys starting
+// with KeyPrefix (default "aave-cap-alerts:").
+type SnapshotRedisConfig struct {
+	URL       string `yaml:"url"`
+	KeyPrefix string `yaml:"key_prefix"`
+}
+
+// SnapshotS3Config keeps the snapshot as objects in Bucket under Prefix (default
+// "aave-cap-alerts/"). Endpoint overrides AWS's for S3-compatible services; empty
+// credentials are read from the standard AWS environment variables.
+type SnapshotS3Config struct {
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	Prefix          string `yaml:"prefix"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+}
+
+// QuietHoursConfig is a daily window, Start to End as HH:MM in Timezone (default UTC),
+// during which events of EventTypes (default: every non-critical type) are held and
+// delivered when it ends. target_reached and protocol_pause are always delivered.
+type QuietHoursConfig struct {
+	Start      string   `yaml:"start"`
+	End        string   `yaml:"end"`
+	Timezone   string   `yaml:"timezone"`
+	EventTypes []string `yaml:"event_types"`
+}
+
+// IncidentsConfig enables incident IDs; StatePath keeps open incidents across restarts.
+type IncidentsConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	StatePath string `yaml:"state_path"`
+}
+
+// Values accepted by QueueConfig.Overflow.
+const (
+	QueueOverflowBlock      = "block"
+	QueueOverflowDropOldest = "drop_oldest"
+)
+
+// PercentConfig controls how percentages are displayed in messages. Decimals defaults to 2
+// and Rounding to half_up; half_even and down are also accepted.
+type PercentConfig struct {
+	Decimals *int   `yaml:"decimals"`
+	Rounding string `yaml:"rounding"`
 }
 
-// TelegramConfig configures Telegram bot notifications.
+// RouteConfig sends matching events to a group of notifiers, referenced by their
+// Name() in priority order. Empty Assets or EventTypes match everything. Mode is "all" (default) or "first_success", which stops after the first
+// notifier that delivers successfully.
+type RouteConfig struct {
+	Assets     []string `yaml:"assets"`
+	EventTypes []string `yaml:"event_types"`
+	Notifiers  []string `yaml:"notifiers"`
+	Mode       string   `yaml:"mode"`
+}
+
+// Values accepted by RouteConfig.Mode.
+const (
+	RouteModeAll          = "all"
+	RouteModeFirstSuccess = "first_success"
+)
+
+// TemplateConfig customizes message wording with Go text/template strings rendered
+// against the event. ByType is keyed by event type name (see notify.EventTypes) and falls
+// back to Default, then to the built-in format.
+type TemplateConfig struct {
+	Default string            `yaml:"default"`
+	ByType  map[string]string `yaml:"by_type"`
+}
+
+// TelegramConfig configures Telegram bot notifications. ChatRouting sends matching events
+// to other chats; the first matching rule wins and ChatID is the fallback.
 type TelegramConfig struct {
-	BotToken string `yaml:"bot_token"`
-	ChatID   string `yaml:"chat_id"`
+	BotToken    string              `yaml:"bot_token"`
+	ChatID      string              `yaml:"chat_id"`
+	ChatRouting []TelegramChatRoute `yaml:"chat_routing"`
+	Verbosity   string              `yaml:"verbosity"`
+	// MessageTemplateFile replaces the default template for this notifier.
+	MessageTemplateFile string `yaml:"message_template_file"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// TelegramChatRoute selects a chat by asset (name or address) and event type. Empty
+// Assets or EventTypes match everything.
+type TelegramChatRoute struct {
+	Assets     []string `yaml:"assets"`
+	EventTypes []string `yaml:"event_types"`
+	ChatID     string   `yaml:"chat_id"`
+}
+
+// OpsGenieConfig configures OpsGenie alert creation. APIURL defaults to the US endpoint;
+// EU accounts should use https://api.eu.opsgenie.com/v2/alerts. EventTypes restricts which
+// event types create alerts (all when empty).
+type OpsGenieConfig struct {
+	APIKey     string   `yaml:"api_key"`
+	APIURL     string   `yaml:"api_url"`
+	Priority   string   `yaml:"priority"`
+	EventTypes []string `yaml:"event_types"`
+	Verbosity  string   `yaml:"verbosity"`
+	// MessageTemplateFile replaces the default template for this notifier.
+	MessageTemplateFile string `yaml:"message_template_file"`
+	// SubjectTemplate is a Go template over the event for the alert's title; the default
+	// is the asset name followed by the trigger reasons.
+	SubjectTemplate string `yaml:"subject_template"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// SQLiteConfig stores every event in a SQLite database file, created if missing. Chain is
+// an optional label saved with each row.
+type SQLiteConfig struct {
+	Path  string `yaml:"path"`
+	Chain string `yaml:"chain"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// AMQPConfig publishes every event as JSON to Exchange on the AMQP broker at URI, with
+// a routing key rendered from the RoutingKey template (default "{{.Type}}").
+type AMQPConfig struct {
+	URI        string `yaml:"uri"`
+	Exchange   string `yaml:"exchange"`
+	RoutingKey string `yaml:"routing_key"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
 }
 
-// JSONRPCConfig configures a custom JSON-RPC callback.
+// GRPCConfig calls the Notifier.Notify RPC (internal/notify/notifypb/notify.proto) at
+// Address for every event. Timeout (default 10s) is each call's deadline, and calls are
+// tried up to MaxAttempts (default 3, at most 5) times while the server is unavailable.
+// Without a tls block the connection is plaintext.
+type GRPCConfig struct {
+	Address     string         `yaml:"address"`
+	Timeout     string         `yaml:"timeout"`
+	MaxAttempts int            `yaml:"max_attempts"`
+	TLS         *GRPCTLSConfig `yaml:"tls"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// SlackConfig posts every event to a Slack incoming webhook as a Block Kit message.
+type SlackConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// NostrConfig publishes every event as a text note signed with PrivateKey (hex or
+// nsec) to the first of Relays that accepts it.
+type NostrConfig struct {
+	PrivateKey string   `yaml:"private_key"`
+	Relays     []string `yaml:"relays"`
+	Verbosity  string   `yaml:"verbosity"`
+	// MessageTemplateFile replaces the default template for this notifier.
+	MessageTemplateFile string `yaml:"message_template_file"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// GRPCTLSConfig enables TLS for the gRPC notifier. CAFile replaces the system roots,
+// CertFile and KeyFile present a client certificate, and ServerName overrides the name
+// verified on the server's certificate.
+type GRPCTLSConfig struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// EmailConfig sends every event by SMTP to Recipients. Port 465 uses implicit TLS and
+// 587 STARTTLS; Username and Password are optional for relays that need no login.
+type EmailConfig struct {
+	Host       string   `yaml:"host"`
+	Port       int      `yaml:"port"`
+	Username   string   `yaml:"username"`
+	Password   string   `yaml:"password"`
+	From       string   `yaml:"from"`
+	Recipients []string `yaml:"recipients"`
+	Verbosity  string   `yaml:"verbosity"`
+	// MessageTemplateFile replaces the default template for this notifier.
+	MessageTemplateFile string `yaml:"message_template_file"`
+	// SubjectTemplate is a Go template over the event for the subject line; the default
+	// is "Aave supply change: <asset>".
+	SubjectTemplate string `yaml:"subject_template"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// WebhookConfig POSTs every event to URL with a body rendered from BodyTemplate, a Go
+// text/template over the event. ContentType defaults to application/json.
+type WebhookConfig struct {
+	URL          string `yaml:"url"`
+	BodyTemplate string `yaml:"body_template"`
+	ContentType  string `yaml:"content_type"`
+	// SigningSecret, when set, signs each body with HMAC-SHA256 in SignatureHeader
+	// (default X-Signature) and adds X-Timestamp. SignTimestamp signs
+	// "<X-Timestamp>.<body>" instead of the body alone.
+	SigningSecret   string `yaml:"signing_secret"`
+	SignatureHeader string `yaml:"signature_header"`
+	SignTimestamp   bool   `yaml:"sign_timestamp"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
+}
+
+// JSONRPCConfig configures a custom JSON-RPC callback. Format is "flat" (default), which
+// posts {"message": ...} and is not actually JSON-RPC, or "jsonrpc2", which sends a
+// JSON-RPC 2.0 request calling Method with the message and event as params, or
+// "cloudevents", which sends a CloudEvents 1.0 JSON envelope. Verbosity
+// defaults to compact, the one-line summary.
 type JSONRPCConfig struct {
-	URL string `yaml:"url"`
+	URL       string `yaml:"url"`
+	Format    string `yaml:"format"`
+	Method    string `yaml:"method"`
+	Verbosity string `yaml:"verbosity"`
+	// SigningSecret, when set, signs each body with HMAC-SHA256 in SignatureHeader
+	// (default X-Signature) and adds X-Timestamp. SignTimestamp signs
+	// "<X-Timestamp>.<body>" instead of the body alone.
+	SigningSecret   string `yaml:"signing_secret"`
+	SignatureHeader string `yaml:"signature_header"`
+	SignTimestamp   bool   `yaml:"sign_timestamp"`
+	// MessageTemplateFile replaces the default template for this notifier.
+	MessageTemplateFile string `yaml:"message_template_file"`
+	// Selector limits this notifier to events whose asset labels match, e.g.
+	// "risk_tier=high,team=stablecoins".
+	Selector string `yaml:"selector"`
 }
 
 // Load reads and parses the YAML configuration file.
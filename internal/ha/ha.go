@@ -0,0 +1,105 @@
+// Package ha elects one leader among replicas of the service through a shared lock, so
+// only that replica sends notifications.
+package ha
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"aave-cap-alerts/internal/logging"
+)
+
+var logger = logging.New("ha")
+
+// Lock is a lease at most one replica holds at a time. It expires unless renewed.
+type Lock interface {
+	// TryAcquire takes the lock, or renews it when this replica already holds it, and
+	// reports whether this replica holds it now.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives the lock up if this replica holds it.
+	Release(ctx context.Context) error
+}
+
+// Elector campaigns for a Lock every retry period and tracks whether this replica leads.
+// A leader that cannot reach the lock keeps leading until the lease it last renewed is
+// about to expire, so a brief backend outage does not leave the replicas leaderless.
+type Elector struct {
+	lock     Lock
+	ttl      time.Duration
+	retry    time.Duration
+	onChange func(leader bool)
+
+	leader      atomic.Bool
+	lastRenewed time.Time
+	now         func() time.Time
+}
+
+// NewElector builds an elector for a lock whose lease lasts ttl, retrying every third of
+// it. onChange, which may be nil, is called from the elector's goroutine whenever
+// leadership is gained or lost.
+func NewElector(lock Lock, ttl time.Duration, onChange func(leader bool)) *Elector {
+	return &Elector{lock: lock, ttl: ttl, retry: ttl / 3, onChange: onChange, now: time.Now}
+}
+
+// IsLeader reports whether this replica currently leads. It is safe for concurrent use.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Campaign makes one attempt to acquire or renew the lock. Run calls it every retry
+// period; calling it once before Run settles leadership before the first checks.
+func (e *Elector) Campaign(ctx context.Context) {
+	held, err := e.lock.TryAcquire(ctx)
+	now := e.now()
+	switch {
+	case err == nil:
+		if held {
+			e.lastRenewed = now
+		}
+	case e.leader.Load() && now.Sub(e.lastRenewed) < e.ttl-e.retry:
+		logger.Warnf("renew leader lock: %v; still leading until the lease expires", err)
+		held = true
+	default:
+		logger.Warnf("acquire leader lock: %v", err)
+		held = false
+	}
+	e.set(held)
+}
+
+// Run campaigns every retry period until ctx is cancelled, then releases the lock so
+// another replica can take over without waiting for it to expire.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if e.leader.Load() {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := e.lock.Release(releaseCtx); err != nil {
+					logger.Warnf("release leader lock: %v", err)
+				}
+				cancel()
+			}
+			e.set(false)
+			return
+		case <-ticker.C:
+			e.Campaign(ctx)
+		}
+	}
+}
+
+func (e *Elector) set(leader bool) {
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		logger.Infof("became leader; sending notifications")
+	} else {
+		logger.Infof("no longer leader; standing by")
+	}
+	if e.onChange != nil {
+		e.onChange(leader)
+	}
+}
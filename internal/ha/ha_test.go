@@ -0,0 +1,132 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLock struct {
+	held bool
+	err  error
+}
+
+func (l *fakeLock) TryAcquire(context.Context) (bool, error) { return l.held, l.err }
+func (l *fakeLock) Release(context.Context) error            { return nil }
+
+func TestElectorKeepsLeadingThroughBriefOutage(t *testing.T) {
+	lock := &fakeLock{held: true}
+	var changes []bool
+	e := NewElector(lock, 30*time.Second, func(leader bool) { changes = append(changes, leader) })
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	e.Campaign(ctx)
+	if !e.IsLeader() {
+		t.Fatal("expected leadership")
+	}
+	lock.err = errors.New("connection refused")
+	now = now.Add(10 * time.Second)
+	e.Campaign(ctx)
+	if !e.IsLeader() {
+		t.Fatal("lost leadership while the lease was still valid")
+	}
+	now = now.Add(15 * time.Second)
+	e.Campaign(ctx)
+	if e.IsLeader() {
+		t.Fatal("still leading after the lease could have expired")
+	}
+
+	lock.err, lock.held = nil, false
+	e.Campaign(ctx)
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("changes = %v, want [true false]", changes)
+	}
+}
+
+// fakeLeaseAPI serves one Lease with resourceVersion checks.
+func fakeLeaseAPI(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	var stored *lease
+	version := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(stored)
+		case http.MethodPost, http.MethodPut:
+			var l lease
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &l); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if (r.Method == http.MethodPost && stored != nil) ||
+				(r.Method == http.MethodPut && (stored == nil || l.Metadata.ResourceVersion != stored.Metadata.ResourceVersion)) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			version++
+			l.Metadata.ResourceVersion = strconv.Itoa(version)
+			stored = &l
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			}
+			json.NewEncoder(w).Encode(stored)
+		}
+	}))
+}
+
+func TestLeaseLockHandsOverAfterExpiry(t *testing.T) {
+	srv := fakeLeaseAPI(t)
+	defer srv.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	a := newLeaseLock(srv.URL, tokenFile, srv.Client(), "alerts", "aave-cap-alerts", "pod-a", 15*time.Second)
+	b := newLeaseLock(srv.URL, tokenFile, srv.Client(), "alerts", "aave-cap-alerts", "pod-b", 15*time.Second)
+	a.now, b.now = clock, clock
+	ctx := context.Background()
+
+	step := func(l *LeaseLock, want bool) {
+		t.Helper()
+		held, err := l.TryAcquire(ctx)
+		if err != nil || held != want {
+			t.Fatalf("%s at %s: held %v, %v; want %v", l.identity, now.Format(time.TimeOnly), held, err, want)
+		}
+	}
+	step(a, true)  // creates the Lease
+	step(b, false) // held by a
+	now = now.Add(10 * time.Second)
+	step(a, true) // renews
+	now = now.Add(20 * time.Second)
+	step(b, true) // a's lease expired
+	step(a, false)
+
+	if err := b.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	step(a, true) // released, so no wait for expiry
+}
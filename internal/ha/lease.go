@@ -0,0 +1,226 @@
+package ha
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// serviceAccountDir holds the in-cluster credentials Kubernetes mounts into every pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// microTime is the layout of the Lease's MicroTime fields.
+const microTime = "2006-01-02T15:04:05.000000Z07:00"
+
+// LeaseLock is a Lock held as a coordination.k8s.io/v1 Lease, the object Kubernetes'
+// own controllers elect leaders with. Updates carry the Lease's resourceVersion, so two
+// replicas racing for an expired Lease cannot both win.
+type LeaseLock struct {
+	apiURL    string
+	tokenFile string
+	client    *http.Client
+
+	namespace string
+	name      string
+	identity  string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewInClusterLeaseLock builds a lock on the Lease name in namespace (default: the pod's
+// own), held as identity for ttl at a time, using the pod's service account. The
+// account needs get, create and update on leases in that namespace.
+func NewInClusterLeaseLock(namespace, name, identity string, ttl time.Duration) (*LeaseLock, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes pod: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are unset")
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("read service account CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("service account CA has no certificates")
+	}
+	if namespace == "" {
+		data, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("read pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	return newLeaseLock("https://"+net.JoinHostPort(host, port), serviceAccountDir+"/token", client, namespace, name, identity, ttl), nil
+}
+
+func newLeaseLock(apiURL, tokenFile string, client *http.Client, namespace, name, identity string, ttl time.Duration) *LeaseLock {
+	return &LeaseLock{apiURL: apiURL, tokenFile: tokenFile, client: client, namespace: namespace, name: name, identity: identity, ttl: ttl, now: time.Now}
+}
+
+type lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   leaseMetadata `json:"metadata"`
+	Spec       leaseSpec     `json:"spec"`
+}
+
+type leaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+	LeaseTransitions     int    `json:"leaseTransitions,omitempty"`
+}
+
+// expired reports whether the holder's lease has run out at now.
+func (s leaseSpec) expired(now time.Time) bool {
+	if s.HolderIdentity == "" {
+		return true
+	}
+	renewed, err := time.Parse(time.RFC3339Nano, s.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(s.LeaseDurationSeconds) * time.Second))
+}
+
+// TryAcquire implements Lock. It creates the Lease when missing, renews it when this
+// identity holds it, and takes it over once the current holder's lease has expired. A
+// conflicting write means another replica got there first.
+func (l *LeaseLock) TryAcquire(ctx context.Context) (bool, error) {
+	now := l.now().UTC()
+	current, found, err := l.get(ctx)
+	if err != nil {
+		return false, err
+	}
+	spec := leaseSpec{
+		HolderIdentity:       l.identity,
+		LeaseDurationSeconds: max(1, int(l.ttl.Round(time.Second)/time.Second)),
+		AcquireTime:          now.Format(microTime),
+		RenewTime:            now.Format(microTime),
+	}
+	if !found {
+		status, err := l.write(ctx, http.MethodPost, l.collectionPath(), lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   leaseMetadata{Name: l.name, Namespace: l.namespace},
+			Spec:       spec,
+		})
+		return status == http.StatusCreated, err
+	}
+
+	if current.Spec.HolderIdentity == l.identity {
+		spec.AcquireTime = current.Spec.AcquireTime
+		spec.LeaseTransitions = current.Spec.LeaseTransitions
+	} else if current.Spec.expired(now) {
+		spec.LeaseTransitions = current.Spec.LeaseTransitions + 1
+	} else {
+		return false, nil
+	}
+	current.Spec = spec
+	status, err := l.write(ctx, http.MethodPut, l.objectPath(), current)
+	return status == http.StatusOK, err
+}
+
+// Release implements Lock by clearing the holder, which lets any replica take the Lease
+// at its next attempt.
+func (l *LeaseLock) Release(ctx context.Context) error {
+	current, found, err := l.get(ctx)
+	if err != nil || !found || current.Spec.HolderIdentity != l.identity {
+		return err
+	}
+	current.Spec = leaseSpec{LeaseDurationSeconds: 1, LeaseTransitions: current.Spec.LeaseTransitions}
+	_, err = l.write(ctx, http.MethodPut, l.objectPath(), current)
+	return err
+}
+
+func (l *LeaseLock) collectionPath() string {
+	return "/apis/coordination.k8s.io/v1/namespaces/" + l.namespace + "/leases"
+}
+
+func (l *LeaseLock) objectPath() string {
+	return l.collectionPath() + "/" + l.name
+}
+
+func (l *LeaseLock) get(ctx context.Context) (lease, bool, error) {
+	resp, body, err := l.request(ctx, http.MethodGet, l.objectPath(), nil)
+	if err != nil {
+		return lease{}, false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return lease{}, false, nil
+	default:
+		return lease{}, false, fmt.Errorf("get lease %s/%s: %s: %s", l.namespace, l.name, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var current lease
+	if err := json.Unmarshal(body, &current); err != nil {
+		return lease{}, false, fmt.Errorf("parse lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	return current, true, nil
+}
+
+// write sends the Lease and returns the response status. A conflict is not an error: it
+// means another replica wrote first.
+func (l *LeaseLock) write(ctx context.Context, method, path string, obj lease) (int, error) {
+	payload, err := json.Marshal(obj)
+	if err != nil {
+		return 0, fmt.Errorf("encode lease: %w", err)
+	}
+	resp, body, err := l.request(ctx, method, path, payload)
+	if err != nil {
+		return 0, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusConflict:
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, fmt.Errorf("write lease %s/%s: %s: %s", l.namespace, l.name, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (l *LeaseLock) request(ctx context.Context, method, path string, payload []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, l.apiURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("build lease request: %w", err)
+	}
+	// The projected token is rotated, so it is read for every request.
+	token, err := os.ReadFile(l.tokenFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read service account token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read lease response: %w", err)
+	}
+	return resp, body, nil
+}
@@ -0,0 +1,53 @@
+package ha
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"aave-cap-alerts/internal/kv"
+)
+
+// renewScript extends the lock's expiry only while it still holds this replica's ID.
+const renewScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`
+
+// releaseScript deletes the lock only while it still holds this replica's ID.
+const releaseScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`
+
+// RedisLock is a Lock held as a Redis key whose value is the holder's identity and whose
+// expiry is the lease.
+type RedisLock struct {
+	client   *kv.Redis
+	key      string
+	identity string
+	ttl      time.Duration
+}
+
+// NewRedisLock builds a lock on key, held as identity for ttl at a time.
+func NewRedisLock(client *kv.Redis, key, identity string, ttl time.Duration) *RedisLock {
+	return &RedisLock{client: client, key: key, identity: identity, ttl: ttl}
+}
+
+// TryAcquire implements Lock: it renews the key if it holds this identity, and otherwise
+// sets it only if absent.
+func (l *RedisLock) TryAcquire(ctx context.Context) (bool, error) {
+	ms := strconv.FormatInt(l.ttl.Milliseconds(), 10)
+	renewed, err := l.client.Do(ctx, "EVAL", renewScript, "1", l.key, l.identity, ms)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := renewed.(int64); n == 1 {
+		return true, nil
+	}
+	set, err := l.client.Do(ctx, "SET", l.key, l.identity, "NX", "PX", ms)
+	if err != nil {
+		return false, err
+	}
+	return set == "OK", nil
+}
+
+// Release implements Lock.
+func (l *RedisLock) Release(ctx context.Context) error {
+	_, err := l.client.Do(ctx, "EVAL", releaseScript, "1", l.key, l.identity)
+	return err
+}
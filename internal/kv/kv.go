@@ -0,0 +1,17 @@
+// Package kv provides the small key/value stores used for shared state: Redis, spoken
+// over its RESP protocol, and S3-compatible object storage.
+package kv
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by Get for a key that has no value.
+var ErrNotFound = errors.New("key not found")
+
+// Store gets and puts opaque values by key.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+}
@@ -0,0 +1,125 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis serves AUTH, SELECT, GET and SET from memory.
+func fakeRedis(t *testing.T, password string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	data := map[string]string{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				authed := password == ""
+				for {
+					reply, err := readReply(r)
+					if err != nil {
+						return
+					}
+					args := make([]string, 0)
+					for _, a := range reply.([]any) {
+						args = append(args, a.(string))
+					}
+					mu.Lock()
+					var out string
+					switch {
+					case args[0] == "AUTH":
+						authed = args[len(args)-1] == password
+						out = "+OK\r\n"
+						if !authed {
+							out = "-WRONGPASS invalid password\r\n"
+						}
+					case !authed:
+						out = "-NOAUTH Authentication required.\r\n"
+					case args[0] == "SELECT":
+						out = "+OK\r\n"
+					case args[0] == "SET":
+						data[args[1]] = args[2]
+						out = "+OK\r\n"
+					case args[0] == "GET":
+						v, ok := data[args[1]]
+						out = "$-1\r\n"
+						if ok {
+							out = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+						}
+					default:
+						out = "-ERR unknown command\r\n"
+					}
+					mu.Unlock()
+					conn.Write([]byte(out))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRedisGetPut(t *testing.T) {
+	addr := fakeRedis(t, "secret")
+	c, err := NewRedis("redis://:secret@" + addr + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing key: %v, want ErrNotFound", err)
+	}
+	value := "line one\r\nline two"
+	if err := c.Put(ctx, "k", []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Get(ctx, "k")
+	if err != nil || string(got) != value {
+		t.Fatalf("get = %q, %v", got, err)
+	}
+	var redisErr RedisError
+	if _, err := c.Do(ctx, "FLUSHALL"); !errors.As(err, &redisErr) {
+		t.Fatalf("unknown command: %v, want a RedisError", err)
+	}
+	// An error reply leaves the connection usable.
+	if _, err := c.Get(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedisRejectsBadPassword(t *testing.T) {
+	addr := fakeRedis(t, "secret")
+	c, _ := NewRedis("redis://:wrong@" + addr)
+	if _, err := c.Get(context.Background(), "k"); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("err = %v, want WRONGPASS", err)
+	}
+}
+
+func TestNewRedisValidatesURL(t *testing.T) {
+	for _, raw := range []string{"http://localhost", "redis://", "redis://localhost/db"} {
+		if _, err := NewRedis(raw); err == nil {
+			t.Errorf("%q: expected an error", raw)
+		}
+	}
+	c, err := NewRedis("rediss://user:pw@cache.internal/3")
+	if err != nil || c.addr != "cache.internal:6379" || c.db != 3 || !c.tls || c.username != "user" {
+		t.Fatalf("parsed %+v, %v", c, err)
+	}
+}
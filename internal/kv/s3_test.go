@@ -0,0 +1,75 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestS3GetPut(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth, path = r.Header.Get("Authorization"), r.URL.EscapedPath()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewS3(S3Config{Bucket: "state", Region: "eu-west-1", Endpoint: srv.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "assets/0xabc|0xdef.json"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing object: %v, want ErrNotFound", err)
+	}
+	if err := s.Put(ctx, "assets/0xabc|0xdef.json", []byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(ctx, "assets/0xabc|0xdef.json")
+	if err != nil || string(got) != `{"a":1}` {
+		t.Fatalf("get = %s, %v", got, err)
+	}
+	if path != "/state/assets/0xabc%7C0xdef.json" {
+		t.Errorf("path = %s", path)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240501/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("authorization = %s", auth)
+	}
+}
+
+func TestNewS3RequiresCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := NewS3(S3Config{Bucket: "state", Region: "us-east-1"}); err == nil {
+		t.Fatal("expected an error without credentials")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	s, err := NewS3(S3Config{Bucket: "state", Region: "us-east-1"})
+	if err != nil || s.endpoint.Host != "s3.us-east-1.amazonaws.com" {
+		t.Fatalf("s3 = %v, %v", s, err)
+	}
+}
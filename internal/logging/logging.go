@@ -0,0 +1,102 @@
+// Package logging adds levels and per-module verbosity on top of the standard log
+// package. Each package creates its own Logger with New; Configure sets the default
+// level and per-module overrides once at startup.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Level orders log severity; a logger emits messages at or below its level.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var levelNames = map[Level]string{
+	LevelError: "error",
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel converts a configured level name. An empty name is info.
+func ParseLevel(name string) (Level, error) {
+	if name == "" {
+		return LevelInfo, nil
+	}
+	for level, n := range levelNames {
+		if strings.EqualFold(name, n) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (use error, warn, info, or debug)", name)
+}
+
+var (
+	mu           sync.RWMutex
+	defaultLevel = LevelInfo
+	moduleLevels = map[string]Level{}
+)
+
+// Configure sets the default level and per-module overrides for every logger.
+func Configure(level Level, modules map[string]Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultLevel = level
+	moduleLevels = make(map[string]Level, len(modules))
+	for module, l := range modules {
+		moduleLevels[module] = l
+	}
+}
+
+// Logger writes messages for one module, such as "monitor" or "aave".
+type Logger struct {
+	module string
+}
+
+// New returns the logger for a module.
+func New(module string) *Logger {
+	return &Logger{module: module}
+}
+
+// Enabled reports whether messages at level are emitted for this module.
+func (l *Logger) Enabled(level Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	threshold, ok := moduleLevels[l.module]
+	if !ok {
+		threshold = defaultLevel
+	}
+	return level <= threshold
+}
+
+func (l *Logger) logf(level Level, format string, args ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	log.Printf("%s %s: %s", strings.ToUpper(level.String()), l.module, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a failure that needs attention.
+func (l *Logger) Errorf(format string, args ...any) { l.logf(LevelError, format, args...) }
+
+// Warnf logs a recoverable problem.
+func (l *Logger) Warnf(format string, args ...any) { l.logf(LevelWarn, format, args...) }
+
+// Infof logs a notable event.
+func (l *Logger) Infof(format string, args ...any) { l.logf(LevelInfo, format, args...) }
+
+// Debugf logs routine detail, such as the outcome of every check.
+func (l *Logger) Debugf(format string, args ...any) { l.logf(LevelDebug, format, args...) }
@@ -0,0 +1,30 @@
+package logging
+
+import "testing"
+
+func TestModuleOverride(t *testing.T) {
+	t.Cleanup(func() { Configure(LevelInfo, nil) })
+
+	Configure(LevelWarn, map[string]Level{"monitor": LevelDebug})
+	if !New("monitor").Enabled(LevelDebug) {
+		t.Fatalf("monitor override to debug not applied")
+	}
+	if New("aave").Enabled(LevelInfo) {
+		t.Fatalf("aave should follow the warn default")
+	}
+	if !New("aave").Enabled(LevelError) {
+		t.Fatalf("errors should be enabled at warn")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := ParseLevel(""); err != nil || level != LevelInfo {
+		t.Fatalf("empty level = %v, %v; want info", level, err)
+	}
+	if level, err := ParseLevel("DEBUG"); err != nil || level != LevelDebug {
+		t.Fatalf("DEBUG = %v, %v; want debug", level, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatalf("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// Defaults for the adaptive notification mode.
+const (
+	defaultAdaptiveVerbose        = 3
+	defaultAdaptiveWindow         = time.Hour
+	defaultAdaptiveDigestInterval = 15 * time.Minute
+)
+
+// adaptiveMode sends an asset's supply change events in detail until it becomes active,
+// meaning more than verbose changes within window, then folds them into a digest sent
+// every digestInterval. After quietPeriod without a change the asset is quiet again: the
+// open digest is sent and per-change events resume. Only supply_increase and
+// supply_decrease are digested; every other event type is always sent as it happens.
+type adaptiveMode struct {
+	verbose        int
+	window         time.Duration
+	digestInterval time.Duration
+	quietPeriod    time.Duration
+
+	// changes holds the times of recent change events, oldest first, within window.
+	changes    []time.Time
+	active     bool
+	lastChange time.Time
+	digest     *supplyDigest
+}
+
+// supplyDigest accumulates the change events held while an asset is active.
+type supplyDigest struct {
+	since time.Time
+	first notify.SupplyChangeEvent
+	last  notify.SupplyChangeEvent
+	count int
+}
+
+// newAdaptiveMode parses an asset's adaptive block; it returns nil when the mode is off.
+func newAdaptiveMode(name string, cfg *config.AdaptiveConfig) (*adaptiveMode, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	m := &adaptiveMode{
+		verbose:        defaultAdaptiveVerbose,
+		window:         defaultAdaptiveWindow,
+		digestInterval: defaultAdaptiveDigestInterval,
+	}
+	if cfg.VerboseChanges < 0 {
+		return nil, fmt.Errorf("asset %s adaptive.verbose_changes must not be negative", name)
+	}
+	if cfg.VerboseChanges > 0 {
+		m.verbose = cfg.VerboseChanges
+	}
+	for _, field := range []struct {
+		key  string
+		raw  string
+		dest *time.Duration
+	}{
+		{"window", cfg.Window, &m.window},
+		{"digest_interval", cfg.DigestInterval, &m.digestInterval},
+		{"quiet_period", cfg.QuietPeriod, &m.quietPeriod},
+	} {
+		if field.raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse asset %s adaptive.%s: %w", name, field.key, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("asset %s adaptive.%s must be positive", name, field.key)
+		}
+		*field.dest = d
+	}
+	if m.quietPeriod == 0 {
+		m.quietPeriod = m.window
+	}
+	return m, nil
+}
+
+// digestible reports whether events of this type are folded into digests.
+func digestible(eventType notify.EventType) bool {
+	return eventType == notify.EventSupplyIncrease || eventType == notify.EventSupplyDecrease
+}
+
+// record counts a change event at now and reports whether it should be held for the
+// digest rather than sent.
+func (m *adaptiveMode) record(now time.Time) bool {
+	cutoff := now.Add(-m.window)
+	kept := m.changes[:0]
+	for _, at := range m.changes {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	m.changes = append(kept, now)
+	m.lastChange = now
+	if !m.active && len(m.changes) > m.verbose {
+		m.active = true
+	}
+	return m.active
+}
+
+// hold adds an event to the open digest, opening one if needed.
+func (m *adaptiveMode) hold(event notify.SupplyChangeEvent) {
+	if m.digest == nil {
+		m.digest = &supplyDigest{since: event.ObservedAt, first: event}
+	}
+	m.digest.last = event
+	m.digest.count++
+}
+
+// due returns the digest to send at now, if any, and whether the asset has just become
+// quiet again. A digest is due every digestInterval while active and when the asset goes
+// quiet.
+func (m *adaptiveMode) due(now time.Time) (*supplyDigest, bool) {
+	quiet := m.active && now.Sub(m.lastChange) >= m.quietPeriod
+	if quiet {
+		m.active = false
+		m.changes = m.changes[:0]
+	}
+	if m.digest == nil || (!quiet && now.Sub(m.digest.since) < m.digestInterval) {
+		return nil, quiet
+	}
+	digest := m.digest
+	m.digest = nil
+	return digest, quiet
+}
+
+// notifyAdaptive sends a change event, or holds it for the digest while the asset is
+// active.
+func (a *assetWatcher) notifyAdaptive(ctx context.Context, d *dispatcher, event notify.SupplyChangeEvent) {
+	if a.adaptive == nil || !digestible(event.Type) {
+		a.notify(ctx, d, event)
+		return
+	}
+	wasActive := a.adaptive.active
+	if !a.adaptive.record(event.ObservedAt) {
+		a.notify(ctx, d, event)
+		return
+	}
+	if !wasActive {
+		logger.Infof("asset %s active (more than %d changes in %s): switching to digests every %s",
+			a.name, a.adaptive.verbose, a.adaptive.window, a.adaptive.digestInterval)
+	}
+	a.adaptive.hold(event)
+}
+
+// flushAdaptive sends the open digest when it is due and ends the active period once the
+// asset has been quiet long enough. It is called on every check.
+func (a *assetWatcher) flushAdaptive(ctx context.Context, d *dispatcher, now time.Time) {
+	if a.adaptive == nil {
+		return
+	}
+	digest, quiet := a.adaptive.due(now)
+	if quiet {
+		logger.Infof("asset %s quiet for %s: resuming per-change notifications", a.name, a.adaptive.quietPeriod)
+	}
+	if digest == nil {
+		return
+	}
+	a.notify(ctx, d, digestEvent(digest, a.metric()))
+}
+
+// digestEvent summarizes a digest as one event running from the first held change's old
+// value to the last one's new value.
+func digestEvent(digest *supplyDigest, metric string) notify.SupplyChangeEvent {
+	event := digest.last
+	event.Type = notify.EventSupplyDigest
+	event.OldTotalSupply = cloneBigInt(digest.first.OldTotalSupply)
+	event.Change = relativeChange(digest.first.OldTotalSupply, digest.last.NewTotalSupply)
+	event.CoalescedChanges = digest.count
+	event.TriggerReasons = []string{fmt.Sprintf("%d %s change(s) since %s: %s -> %s",
+		digest.count, metric, digest.since.UTC().Format(time.RFC3339),
+		digest.first.OldTotalSupply.String(), digest.last.NewTotalSupply.String())}
+	return event
+}
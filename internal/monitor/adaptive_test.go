@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestAdaptiveModeDigestsBusyPeriods(t *testing.T) {
+	m, err := newAdaptiveMode("USDC", &config.AdaptiveConfig{VerboseChanges: 2, Window: "1h", DigestInterval: "10m", QuietPeriod: "30m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	change := func(minute int, oldValue, newValue int64) notify.SupplyChangeEvent {
+		return notify.SupplyChangeEvent{
+			Type:           notify.EventSupplyIncrease,
+			OldTotalSupply: big.NewInt(oldValue),
+			NewTotalSupply: big.NewInt(newValue),
+			ObservedAt:     start.Add(time.Duration(minute) * time.Minute),
+		}
+	}
+
+	for i, held := range []bool{false, false, true, true} {
+		event := change(i, int64(100+i), int64(101+i))
+		if got := m.record(event.ObservedAt); got != held {
+			t.Fatalf("change %d held = %v, want %v", i, got, held)
+		}
+		if held {
+			m.hold(event)
+		}
+	}
+	if digest, quiet := m.due(start.Add(5 * time.Minute)); digest != nil || quiet {
+		t.Fatalf("digest before the interval: %v, quiet %v", digest, quiet)
+	}
+	digest, quiet := m.due(start.Add(12 * time.Minute))
+	if digest == nil || quiet {
+		t.Fatalf("expected a digest after the interval, quiet %v", quiet)
+	}
+	event := digestEvent(digest, "total supply")
+	if event.Type != notify.EventSupplyDigest || event.CoalescedChanges != 2 ||
+		event.OldTotalSupply.Int64() != 102 || event.NewTotalSupply.Int64() != 104 {
+		t.Errorf("digest event = %s %d changes %s -> %s", event.Type, event.CoalescedChanges, event.OldTotalSupply, event.NewTotalSupply)
+	}
+
+	m.hold(change(20, 104, 105))
+	digest, quiet = m.due(start.Add(60 * time.Minute))
+	if digest == nil || !quiet || m.active {
+		t.Fatalf("expected the open digest to flush on going quiet, got %v quiet %v active %v", digest, quiet, m.active)
+	}
+	if m.record(start.Add(61 * time.Minute)) {
+		t.Error("first change after going quiet should be sent in full")
+	}
+}
+
+func TestNewAdaptiveModeValidatesConfig(t *testing.T) {
+	if m, err := newAdaptiveMode("USDC", nil); m != nil || err != nil {
+		t.Errorf("nil config = %v, %v; want off", m, err)
+	}
+	m, err := newAdaptiveMode("USDC", &config.AdaptiveConfig{Window: "2h"})
+	if err != nil || m.verbose != defaultAdaptiveVerbose || m.quietPeriod != 2*time.Hour {
+		t.Errorf("defaults = %+v, %v", m, err)
+	}
+	for _, cfg := range []config.AdaptiveConfig{{VerboseChanges: -1}, {Window: "soon"}, {DigestInterval: "0s"}} {
+		if _, err := newAdaptiveMode("USDC", &cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
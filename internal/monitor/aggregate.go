@@ -0,0 +1,259 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// aggregateWatcher sums the supplies of the same asset on several chains, each member
+// read through its own RPC client, and notifies when the combined figure crosses the
+// threshold from below. Supplies are normalized to the largest member decimals so the sum
+// stays exact.
+type aggregateWatcher struct {
+	name            string
+	members         []*aggregateMember
+	thresholdTokens *big.Rat
+	pollInterval    time.Duration
+	retry           metadataRetry
+
+	// above records which side of the threshold the last combined reading was on; nil
+	// until the first reading.
+	above *bool
+}
+
+// aggregateMember is one token of an aggregate. client is nil until SetChainClients
+// provides it for rpcURL; an empty rpcURL reads through the main client.
+type aggregateMember struct {
+	chain  string
+	rpcURL string
+	client *aave.Client
+	// address is the token; with a symbol it is resolved from pool on the first check.
+	address        *common.Address
+	symbol         string
+	pool           common.Address
+	decimalsLoaded bool
+	decimals       uint8
+}
+
+// newAggregateWatchers builds the aggregate watchers from the configuration.
+func newAggregateWatchers(aggregates []config.AggregateConfig, defaultPoll time.Duration, retry metadataRetry) ([]*aggregateWatcher, error) {
+	watchers := make([]*aggregateWatcher, 0, len(aggregates))
+	for i, ac := range aggregates {
+		name := ac.Name
+		if name == "" {
+			name = fmt.Sprintf("aggregate %d", i)
+		}
+		threshold, err := parseTokenAmount(ac.ThresholdTokens)
+		if err != nil {
+			return nil, fmt.Errorf("%s threshold_tokens: %w", name, err)
+		}
+		if threshold == nil || threshold.Sign() <= 0 {
+			return nil, fmt.Errorf("%s threshold_tokens must be positive", name)
+		}
+		if len(ac.Members) < 2 {
+			return nil, fmt.Errorf("%s needs at least two members", name)
+		}
+		g := &aggregateWatcher{
+			name:            name,
+			thresholdTokens: threshold,
+			pollInterval:    defaultPoll,
+			retry:           retry,
+		}
+		for j, mc := range ac.Members {
+			member, err := newAggregateMember(j, mc)
+			if err != nil {
+				return nil, fmt.Errorf("%s member %d: %w", name, j, err)
+			}
+			g.members = append(g.members, member)
+		}
+		if ac.PollInterval != "" {
+			interval, err := time.ParseDuration(ac.PollInterval)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s poll interval: %w", name, err)
+			}
+			if interval <= 0 {
+				return nil, fmt.Errorf("%s poll interval must be positive", name)
+			}
+			g.pollInterval = interval
+		}
+		watchers = append(watchers, g)
+	}
+	return watchers, nil
+}
+
+func newAggregateMember(i int, mc config.AggregateMemberConfig) (*aggregateMember, error) {
+	m := &aggregateMember{chain: mc.Chain, rpcURL: mc.RPCURL, symbol: mc.Symbol}
+	if m.chain == "" {
+		m.chain = fmt.Sprintf("member %d", i)
+	}
+	switch {
+	case mc.Address != "" && mc.Symbol != "":
+		return nil, fmt.Errorf("set either symbol or address, not both")
+	case mc.Address != "":
+		if !common.IsHexAddress(mc.Address) {
+			return nil, fmt.Errorf("address is not a valid hex string")
+		}
+		addr := common.HexToAddress(mc.Address)
+		m.address = &addr
+	case mc.Symbol != "":
+		if !common.IsHexAddress(mc.PoolAddress) {
+			return nil, fmt.Errorf("symbol requires a valid pool_address")
+		}
+		m.pool = common.HexToAddress(mc.PoolAddress)
+	default:
+		return nil, fmt.Errorf("address or symbol must be provided")
+	}
+	return m, nil
+}
+
+// aggregateRPCURLs returns the distinct RPC URLs aggregate members read through, other
+// than the main one.
+func aggregateRPCURLs(aggregates []*aggregateWatcher) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, g := range aggregates {
+		for _, m := range g.members {
+			if m.rpcURL != "" && !seen[m.rpcURL] {
+				seen[m.rpcURL] = true
+				urls = append(urls, m.rpcURL)
+			}
+		}
+	}
+	return urls
+}
+
+// ChainRPCURLs returns the RPC URLs that cross-chain aggregates need clients for.
+func (s *Service) ChainRPCURLs() []string {
+	return aggregateRPCURLs(s.aggregates)
+}
+
+// SetChainClients provides the clients for the URLs returned by ChainRPCURLs. It must be
+// called before Run and fails if any of them is missing.
+func (s *Service) SetChainClients(clients map[string]*aave.Client) error {
+	for _, g := range s.aggregates {
+		for _, m := range g.members {
+			if m.rpcURL == "" {
+				continue
+			}
+			client, ok := clients[m.rpcURL]
+			if !ok {
+				return fmt.Errorf("%s %s: no client for %s", g.name, m.chain, m.rpcURL)
+			}
+			m.client = client
+		}
+	}
+	return nil
+}
+
+func (g *aggregateWatcher) run(ctx context.Context, client *aave.Client, d *dispatcher) {
+	ticker := time.NewTicker(g.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.check(ctx, client, d); err != nil && ctx.Err() == nil {
+			logger.Warnf("%s check failed: %v", g.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *aggregateWatcher) check(ctx context.Context, mainClient *aave.Client, d *dispatcher) error {
+	supplies := make([]*big.Int, len(g.members))
+	var decimals uint8
+	for i, m := range g.members {
+		client := m.client
+		if client == nil {
+			client = mainClient
+		}
+		if err := m.load(ctx, client, g.retry); err != nil {
+			return fmt.Errorf("%s: %w", m.chain, err)
+		}
+		supply, err := client.TotalSupply(ctx, *m.address)
+		if err != nil {
+			return fmt.Errorf("%s: fetch %s totalSupply: %w", m.chain, m.address.Hex(), err)
+		}
+		supplies[i] = supply
+		decimals = max(decimals, m.decimals)
+	}
+
+	combined := new(big.Int)
+	contributions := make([]notify.ChainContribution, len(g.members))
+	for i, m := range g.members {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-m.decimals)), nil)
+		combined.Add(combined, new(big.Int).Mul(supplies[i], scale))
+		contributions[i] = notify.ChainContribution{
+			Chain:    m.chain,
+			Address:  m.address.Hex(),
+			Supply:   supplies[i],
+			Decimals: m.decimals,
+		}
+	}
+
+	above := tokensRat(combined, decimals).Cmp(g.thresholdTokens) >= 0
+	previous := g.above
+	g.above = &above
+	logger.Debugf("%s combined supply %s tokens", g.name, tokensString(combined, decimals))
+	if previous == nil || *previous || !above {
+		return nil
+	}
+
+	parts := make([]string, len(contributions))
+	for i, c := range contributions {
+		parts[i] = fmt.Sprintf("%s %s", c.Chain, tokensString(c.Supply, c.Decimals))
+	}
+	reason := fmt.Sprintf("combined supply reached %s tokens (threshold %s): %s", tokensString(combined, decimals),
+		g.thresholdTokens.FloatString(2), strings.Join(parts, ", "))
+	logger.Infof("%s %s", g.name, reason)
+	d.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventAggregateThreshold,
+		AssetName:      g.name,
+		NewTotalSupply: combined,
+		Decimals:       decimals,
+		Contributions:  contributions,
+		Source:         notify.SourceRPC,
+		TriggerReasons: []string{reason},
+		ObservedAt:     time.Now(),
+	})
+	return nil
+}
+
+// load resolves the member's token from its symbol and reads its decimals, once.
+func (m *aggregateMember) load(ctx context.Context, client *aave.Client, retry metadataRetry) error {
+	if m.address == nil {
+		registry, err := reserveRegistry(ctx, client, m.pool, retry)
+		if err != nil {
+			return err
+		}
+		matches := registry[strings.ToUpper(m.symbol)]
+		if len(matches) != 1 {
+			return fmt.Errorf("symbol %s matches %d reserves in pool %s, want exactly one", m.symbol, len(matches), m.pool.Hex())
+		}
+		m.address = &matches[0]
+		logger.Infof("aggregate member %s symbol %s resolved to aToken %s", m.chain, m.symbol, m.address.Hex())
+	}
+	if !m.decimalsLoaded {
+		err := retry.do(ctx, m.chain+" decimals read", func() (err error) {
+			m.decimals, err = client.Decimals(ctx, *m.address)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("fetch decimals: %w", err)
+		}
+		m.decimalsLoaded = true
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNewAggregateWatchersValidatesMembers(t *testing.T) {
+	pool := "0x87870Bca3F3fD6335C3F4ce8392D69350B4fA4E2"
+	usdc := config.AggregateMemberConfig{Chain: "ethereum", Address: "0x98C23E9d8f34FEFb1B7BD6a91B7FF122F4e16F5c"}
+	arbitrum := config.AggregateMemberConfig{Chain: "arbitrum", RPCURL: "https://arb.example", Symbol: "USDC", PoolAddress: pool}
+	tests := []struct {
+		name    string
+		cfg     config.AggregateConfig
+		wantErr bool
+	}{
+		{"address and symbol members", config.AggregateConfig{ThresholdTokens: "1000000", Members: []config.AggregateMemberConfig{usdc, arbitrum}}, false},
+		{"missing threshold", config.AggregateConfig{Members: []config.AggregateMemberConfig{usdc, arbitrum}}, true},
+		{"single member", config.AggregateConfig{ThresholdTokens: "1", Members: []config.AggregateMemberConfig{usdc}}, true},
+		{"symbol without pool", config.AggregateConfig{ThresholdTokens: "1", Members: []config.AggregateMemberConfig{usdc, {Symbol: "USDC"}}}, true},
+		{"address and symbol", config.AggregateConfig{ThresholdTokens: "1", Members: []config.AggregateMemberConfig{usdc, {Address: usdc.Address, Symbol: "USDC", PoolAddress: pool}}}, true},
+	}
+	for _, tt := range tests {
+		_, err := newAggregateWatchers([]config.AggregateConfig{tt.cfg}, time.Minute, metadataRetry{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetChainClientsCoversEveryRPCURL(t *testing.T) {
+	aggregates, err := newAggregateWatchers([]config.AggregateConfig{{
+		ThresholdTokens: "1",
+		Members: []config.AggregateMemberConfig{
+			{Address: common.HexToAddress("0x1").Hex()},
+			{RPCURL: "https://arb.example", Address: common.HexToAddress("0x2").Hex()},
+			{RPCURL: "https://base.example", Address: common.HexToAddress("0x3").Hex()},
+			{RPCURL: "https://arb.example", Address: common.HexToAddress("0x4").Hex()},
+		},
+	}}, time.Minute, metadataRetry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{aggregates: aggregates}
+	if got, want := s.ChainRPCURLs(), []string{"https://arb.example", "https://base.example"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChainRPCURLs() = %v, want %v", got, want)
+	}
+	if err := s.SetChainClients(map[string]*aave.Client{"https://arb.example": {}}); err == nil {
+		t.Error("expected an error for the missing base client")
+	}
+}
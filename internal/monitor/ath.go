@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+// allTimeHigh is the highest reading a watcher has seen since monitoring began, or
+// since it started when no state_path is configured.
+type allTimeHigh struct {
+	value *big.Int
+	at    time.Time
+}
+
+// checkATH fires a supply_ath event when the reading exceeds the highest value seen so
+// far. Without a persisted record the first reading only seeds it.
+func (a *assetWatcher) checkATH(ctx context.Context, d *dispatcher, value *big.Int, source string, obs observation) {
+	previous := a.ath
+	if previous != nil && value.Cmp(previous.value) <= 0 {
+		return
+	}
+	a.ath = &allTimeHigh{value: new(big.Int).Set(value), at: obs.observedAt}
+	if a.state != nil {
+		a.state.saveATH(a.stateKey(), a.ath)
+	}
+	if previous == nil {
+		return
+	}
+
+	reason := fmt.Sprintf("%s reached a new all-time high: %s (previous %s at %s)",
+		a.metric(), value.String(), previous.value.String(), previous.at.UTC().Format(time.RFC3339))
+	logger.Infof("asset %s %s", a.name, reason)
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventSupplyATH,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		OldTotalSupply:    cloneBigInt(a.lastTotalSupply),
+		NewTotalSupply:    new(big.Int).Set(value),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            source,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		PreviousATH:       new(big.Int).Set(previous.value),
+		PreviousATHAt:     previous.at,
+		TriggerReasons:    []string{reason},
+		ObservedAt:        obs.observedAt,
+	})
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+)
+
+// Backfill seeds each watcher's baseline with its value at the last block mined at or
+// before since, so the first check reports what changed over that window instead of
+// just recording the current value. It must run before Run. Only total_supply and
+// holder_balance assets can be backfilled; others keep the usual first observation.
+// Reading old state requires an archive node.
+func (s *Service) Backfill(ctx context.Context, since time.Time) error {
+	block, err := s.client.BlockByTimestamp(ctx, since)
+	if err != nil {
+		return fmt.Errorf("resolve block for %s: %w", since.UTC().Format(time.RFC3339), err)
+	}
+	number := new(big.Int).SetUint64(block)
+	logger.Infof("backfill: using block %d as the baseline for %s", block, since.UTC().Format(time.RFC3339))
+
+	for _, a := range s.assets {
+		if a.supplyMetric != config.SupplyMetricTotal {
+			logger.Warnf("asset %s backfill skipped: not supported for supply_metric %s", a.name, a.supplyMetric)
+			continue
+		}
+
+		var value *big.Int
+		if a.holder != nil {
+			value, err = s.client.BalanceOfAt(ctx, a.address, *a.holder, number)
+		} else {
+			value, err = s.client.TotalSupplyAt(ctx, a.address, number)
+		}
+		if err != nil {
+			return fmt.Errorf("asset %s backfill at block %d: %w", a.name, block, err)
+		}
+
+		a.lastTotalSupply = value
+		// A backfilled baseline is read fresh, so it needs no confirmation.
+		a.unconfirmedResume = false
+		logger.Infof("asset %s backfill baseline %s %s at block %d", a.name, a.metric(), value.String(), block)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// capLevel is a percentage of the target that escalates to its own notifiers.
+type capLevel struct {
+	pct       *big.Rat
+	notifiers []string
+	// reached records whether the last reading was at or above the level; nil until the
+	// first reading with a known target.
+	reached *bool
+}
+
+// newCapLevels parses an asset's cap_levels. Each notifier must be one of the configured
+// notifier names.
+func newCapLevels(name string, cfgs []config.CapLevelConfig, notifierNames map[string]bool) ([]*capLevel, error) {
+	levels := make([]*capLevel, 0, len(cfgs))
+	for i, lc := range cfgs {
+		pct, err := parsePercent(lc.LevelPct)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s cap_levels[%d].level_pct: %w", name, i, err)
+		}
+		if pct == nil || pct.Sign() <= 0 {
+			return nil, fmt.Errorf("asset %s cap_levels[%d].level_pct must be positive", name, i)
+		}
+		if len(lc.Notifiers) == 0 {
+			return nil, fmt.Errorf("asset %s cap_levels[%d] must list at least one notifier", name, i)
+		}
+		for _, n := range lc.Notifiers {
+			if !notifierNames[n] {
+				return nil, fmt.Errorf("asset %s cap_levels[%d]: notifier %q is not configured", name, i, n)
+			}
+		}
+		levels = append(levels, &capLevel{pct: pct, notifiers: lc.Notifiers})
+	}
+	return levels, nil
+}
+
+// threshold is the level applied to target, truncated to base units.
+func (l *capLevel) threshold(target *big.Int) *big.Int {
+	scaled := new(big.Rat).Mul(new(big.Rat).SetInt(target), l.pct)
+	scaled.Quo(scaled, big.NewRat(100, 1))
+	return new(big.Int).Quo(scaled.Num(), scaled.Denom())
+}
+
+// checkCapLevels fires a cap_level event, routed to the level's notifiers, for each level
+// the value has reached since the previous reading. Falling back below a level re-arms
+// it. The first reading only records the state.
+func (a *assetWatcher) checkCapLevels(ctx context.Context, d *dispatcher, value *big.Int, source string, obs observation) {
+	if a.targetTotalSupply == nil {
+		return
+	}
+	for _, level := range a.capLevels {
+		threshold := level.threshold(a.targetTotalSupply)
+		reached := value.Cmp(threshold) >= 0
+		previous := level.reached
+		level.reached = &reached
+		if previous == nil || *previous == reached {
+			continue
+		}
+		if !reached {
+			logger.Infof("asset %s %s fell below %s%% of target; level re-armed", a.name, a.metric(), level.pct.RatString())
+			continue
+		}
+
+		reason := fmt.Sprintf("%s reached %s%% of target %s (%s)", a.metric(), level.pct.RatString(),
+			a.targetTotalSupply.String(), threshold.String())
+		logger.Infof("asset %s %s", a.name, reason)
+		a.notify(ctx, d, notify.SupplyChangeEvent{
+			Type:              notify.EventCapLevel,
+			AssetName:         a.name,
+			AssetAddress:      a.address.Hex(),
+			ExplorerURL:       a.explorerLink(),
+			Holder:            a.holderHex(),
+			OldTotalSupply:    cloneBigInt(a.lastTotalSupply),
+			NewTotalSupply:    new(big.Int).Set(value),
+			TargetTotalSupply: new(big.Int).Set(a.targetTotalSupply),
+			Decimals:          a.decimals,
+			Source:            source,
+			BlockNumber:       obs.blockNumber,
+			BlockTimestamp:    obs.blockTime,
+			TriggerReasons:    []string{reason},
+			Notifiers:         level.notifiers,
+			ObservedAt:        obs.observedAt,
+		})
+	}
+}
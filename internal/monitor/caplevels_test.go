@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestCapLevelsRouteEachLevelToItsNotifiers(t *testing.T) {
+	slack := &fakeNotifier{name: "slack"}
+	pager := &fakeNotifier{name: "pager"}
+	sms := &fakeNotifier{name: "sms"}
+	// The catch-all route must not receive cap_level events.
+	d, err := newDispatcher([]notify.Notifier{slack, pager, sms}, []config.RouteConfig{
+		{Notifiers: []string{"slack"}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{"slack": true, "pager": true, "sms": true}
+	levels, err := newCapLevels("USDC", []config.CapLevelConfig{
+		{LevelPct: "80", Notifiers: []string{"slack"}},
+		{LevelPct: "95", Notifiers: []string{"pager"}},
+		{LevelPct: "100", Notifiers: []string{"pager", "sms"}},
+	}, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &assetWatcher{name: "USDC", targetTotalSupply: big.NewInt(1000), capLevels: levels, supplyMetric: config.SupplyMetricTotal}
+
+	steps := []struct {
+		supply            int64
+		slack, pager, sms int
+	}{
+		{700, 0, 0, 0},  // first reading only records the state
+		{850, 1, 0, 0},  // 80%
+		{960, 1, 1, 0},  // 95%
+		{1000, 1, 2, 1}, // 100%
+		{900, 1, 2, 1},  // falls below 95% and 100%, re-arming them
+		{970, 1, 3, 1},  // 95% again
+	}
+	for i, step := range steps {
+		a.checkCapLevels(context.Background(), d, big.NewInt(step.supply), notify.SourceRPC, observation{})
+		a.lastTotalSupply = big.NewInt(step.supply)
+		if slack.calls != step.slack || pager.calls != step.pager || sms.calls != step.sms {
+			t.Fatalf("step %d (%d): calls slack=%d pager=%d sms=%d, want %d %d %d",
+				i, step.supply, slack.calls, pager.calls, sms.calls, step.slack, step.pager, step.sms)
+		}
+	}
+}
+
+func TestNamedNotifiersFallBackToRoutes(t *testing.T) {
+	slack := &fakeNotifier{name: "slack"}
+	d, err := newDispatcher([]notify.Notifier{slack}, []config.RouteConfig{{Notifiers: []string{"slack"}}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A notifier removed by a reload leaves the event with the usual routes.
+	d.dispatch(context.Background(), notify.SupplyChangeEvent{Type: notify.EventCapLevel, Notifiers: []string{"removed"}})
+	if slack.calls != 1 {
+		t.Fatalf("slack calls = %d, want 1", slack.calls)
+	}
+}
+
+func TestNewCapLevelsValidatesConfig(t *testing.T) {
+	names := map[string]bool{"slack": true}
+	for _, cfg := range []config.CapLevelConfig{
+		{LevelPct: "", Notifiers: []string{"slack"}},
+		{LevelPct: "0", Notifiers: []string{"slack"}},
+		{LevelPct: "eighty", Notifiers: []string{"slack"}},
+		{LevelPct: "80"},
+		{LevelPct: "80", Notifiers: []string{"sms"}},
+	} {
+		if _, err := newCapLevels("USDC", []config.CapLevelConfig{cfg}, names); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
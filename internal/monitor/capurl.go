@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultCapURLTTL is how long a cap fetched from cap_url is used before it is fetched
+// again, when cap_url_ttl is unset.
+const defaultCapURLTTL = 5 * time.Minute
+
+var capURLClient = &http.Client{Timeout: 10 * time.Second}
+
+// capURL keeps an asset's target in sync with an external risk-limit service.
+type capURL struct {
+	url       string
+	ttl       time.Duration
+	fetchedAt time.Time
+}
+
+// refreshCapURL fetches the target from cap_url once the cached value is older than the
+// TTL. A failed fetch keeps the last known target, or target_cap_tokens before any fetch
+// succeeded, and is retried on the next check.
+func (a *assetWatcher) refreshCapURL(ctx context.Context, now time.Time) {
+	if !a.capURL.fetchedAt.IsZero() && now.Sub(a.capURL.fetchedAt) < a.capURL.ttl {
+		return
+	}
+	target, err := fetchCap(ctx, a.capURL.url)
+	if err != nil {
+		logger.Warnf("asset %s cap_url fetch failed: %v; keeping target %s", a.name, err, optionalValue(a.targetTotalSupply))
+		return
+	}
+	if a.targetTotalSupply == nil || target.Cmp(a.targetTotalSupply) != 0 {
+		logger.Infof("asset %s target from cap_url: %s -> %s", a.name, optionalValue(a.targetTotalSupply), target.String())
+	}
+	a.targetTotalSupply = target
+	a.capURL.fetchedAt = now
+}
+
+// fetchCap GETs url and parses the body as a threshold: a bare number in raw base units,
+// optionally JSON-quoted, in any format target_cap_tokens accepts.
+func fetchCap(ctx context.Context, url string) (*big.Int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build cap request: %w", err)
+	}
+	resp, err := capURLClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send cap request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("cap endpoint returned status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, fmt.Errorf("read cap response: %w", err)
+	}
+	target, err := parseThreshold(strings.Trim(strings.TrimSpace(string(body)), `"`))
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("cap endpoint returned an empty body")
+	}
+	return target, nil
+}
+
+func optionalValue(v *big.Int) string {
+	if v == nil {
+		return "none"
+	}
+	return v.String()
+}
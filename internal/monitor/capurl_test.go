@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCapURLKeepsLastValueOnFailure(t *testing.T) {
+	body, status := `"1.5e3"`, http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	a := &assetWatcher{name: "USDC", targetTotalSupply: big.NewInt(100), capURL: &capURL{url: srv.URL, ttl: time.Minute}}
+	now := time.Now()
+	a.refreshCapURL(context.Background(), now)
+	if a.targetTotalSupply.Int64() != 1500 {
+		t.Fatalf("target = %s, want 1500", a.targetTotalSupply)
+	}
+
+	body = "2000"
+	a.refreshCapURL(context.Background(), now.Add(30*time.Second))
+	if a.targetTotalSupply.Int64() != 1500 {
+		t.Fatalf("target refetched within the TTL: %s", a.targetTotalSupply)
+	}
+
+	status = http.StatusInternalServerError
+	a.refreshCapURL(context.Background(), now.Add(2*time.Minute))
+	if a.targetTotalSupply.Int64() != 1500 {
+		t.Fatalf("failed fetch changed the target to %s", a.targetTotalSupply)
+	}
+
+	status = http.StatusOK
+	a.refreshCapURL(context.Background(), now.Add(3*time.Minute))
+	if a.targetTotalSupply.Int64() != 2000 {
+		t.Fatalf("target = %s after recovery, want 2000", a.targetTotalSupply)
+	}
+}
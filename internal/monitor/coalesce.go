@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"math/big"
+	"time"
+)
+
+// pendingChange accumulates successive changes seen inside a coalesce window.
+type pendingChange struct {
+	since time.Time
+	last  *big.Int
+	count int
+}
+
+// coalesce records an observed value against the coalesce window. It returns true, along
+// with the number of distinct changes seen, once the window that opened with the first
+// change has elapsed; until then the caller should hold off notifying.
+func (a *assetWatcher) coalesce(value *big.Int, now time.Time) (bool, int) {
+	if a.pending == nil {
+		if value.Cmp(a.lastTotalSupply) == 0 {
+			return false, 0
+		}
+		a.pending = &pendingChange{since: now, last: new(big.Int).Set(value), count: 1}
+		return false, 0
+	}
+
+	if value.Cmp(a.pending.last) != 0 {
+		a.pending.last = new(big.Int).Set(value)
+		a.pending.count++
+	}
+	if now.Sub(a.pending.since) < a.coalesceWindow {
+		return false, 0
+	}
+
+	count := a.pending.count
+	a.pending = nil
+	return true, count
+}
+
+// flushTimer fires when an open coalesce window is due to close, or never when there is
+// none, so the net movement is reported promptly even with a slow poll interval.
+func (a *assetWatcher) flushTimer() <-chan time.Time {
+	if a.pending == nil {
+		return nil
+	}
+	return time.After(time.Until(a.pending.since.Add(a.coalesceWindow)))
+}
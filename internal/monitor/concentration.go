@@ -0,0 +1,264 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// Defaults for concentration checks. Enumerating holders and reading their balances
+// costs far more than a supply read, so concentration polls much less often than the
+// asset itself.
+const (
+	defaultConcentrationPoll = time.Hour
+	defaultConcentrationTopN = 10
+	defaultHolderBlockRange  = 10000
+)
+
+// concentrationWatcher computes the share of an asset's total supply held by its largest
+// holders and notifies when it rises to the threshold. It runs in its own goroutine on
+// its own interval, separate from the asset's supply checks.
+type concentrationWatcher struct {
+	asset        *assetWatcher
+	source       string
+	graphURL     string
+	topN         int
+	thresholdPct *big.Rat
+	pollInterval time.Duration
+	blockRange   uint64
+
+	// nextBlock is the first block not yet scanned for Transfer events, and holders every
+	// recipient seen so far with a non-zero balance at the last check.
+	nextBlock uint64
+	holders   map[common.Address]struct{}
+	// above records which side of the threshold the last reading was on; nil until the
+	// first reading.
+	above *bool
+}
+
+// holderBalance is one holder's current balance.
+type holderBalance struct {
+	holder  common.Address
+	balance *big.Int
+}
+
+// newConcentrationWatcher builds the concentration check for asset, falling back to
+// graphURL for the subgraph source.
+func newConcentrationWatcher(asset *assetWatcher, cfg *config.ConcentrationConfig, graphURL string) (*concentrationWatcher, error) {
+	threshold, err := parsePercent(cfg.ThresholdPct)
+	if err != nil {
+		return nil, fmt.Errorf("asset %s concentration_threshold: %w", asset.name, err)
+	}
+	if threshold == nil || threshold.Sign() <= 0 || threshold.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, fmt.Errorf("asset %s concentration_threshold must be above 0 and at most 100", asset.name)
+	}
+	c := &concentrationWatcher{
+		asset:        asset,
+		source:       cfg.Source,
+		topN:         defaultConcentrationTopN,
+		thresholdPct: threshold,
+		pollInterval: defaultConcentrationPoll,
+		blockRange:   defaultHolderBlockRange,
+	}
+	if cfg.TopN < 0 {
+		return nil, fmt.Errorf("asset %s concentration top_n must not be negative", asset.name)
+	}
+	if cfg.TopN > 0 {
+		c.topN = cfg.TopN
+	}
+
+	switch cfg.Source {
+	case config.HolderSourceEvents:
+		if cfg.GraphURL != "" {
+			return nil, fmt.Errorf("asset %s concentration graph_url is only valid with source: %s", asset.name, config.HolderSourceSubgraph)
+		}
+		if cfg.BlockRange > 0 {
+			c.blockRange = cfg.BlockRange
+		}
+		c.nextBlock = cfg.FromBlock
+		c.holders = make(map[common.Address]struct{})
+	case config.HolderSourceSubgraph:
+		if cfg.FromBlock != 0 || cfg.BlockRange != 0 {
+			return nil, fmt.Errorf("asset %s concentration from_block and block_range are only valid with source: %s", asset.name, config.HolderSourceEvents)
+		}
+		c.graphURL = graphURL
+		if cfg.GraphURL != "" {
+			c.graphURL = cfg.GraphURL
+		}
+		if c.graphURL == "" {
+			return nil, fmt.Errorf("asset %s concentration source %s requires a graph_url", asset.name, config.HolderSourceSubgraph)
+		}
+	default:
+		return nil, fmt.Errorf("asset %s concentration source must be %s or %s", asset.name, config.HolderSourceEvents, config.HolderSourceSubgraph)
+	}
+
+	if cfg.PollInterval != "" {
+		interval, err := time.ParseDuration(cfg.PollInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse asset %s concentration poll interval: %w", asset.name, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("asset %s concentration poll interval must be positive", asset.name)
+		}
+		c.pollInterval = interval
+	}
+	return c, nil
+}
+
+func (c *concentrationWatcher) run(ctx context.Context, client *aave.Client, d *dispatcher) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.check(ctx, client, d); err != nil && ctx.Err() == nil {
+			logger.Warnf("asset %s concentration check failed: %v", c.asset.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *concentrationWatcher) check(ctx context.Context, client *aave.Client, d *dispatcher) error {
+	a := c.asset
+	candidates, err := c.candidates(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var decimals uint8
+	err = a.retry.do(ctx, a.name+" decimals read", func() (err error) {
+		decimals, err = client.Decimals(ctx, a.address)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("fetch decimals: %w", err)
+	}
+	total, err := client.TotalSupply(ctx, a.address)
+	if err != nil {
+		return fmt.Errorf("fetch totalSupply: %w", err)
+	}
+
+	balances := make([]holderBalance, 0, len(candidates))
+	for _, holder := range candidates {
+		balance, err := client.BalanceOf(ctx, a.address, holder)
+		if err != nil {
+			return fmt.Errorf("fetch balance of %s: %w", holder.Hex(), err)
+		}
+		if balance.Sign() == 0 {
+			// Emptied accounts reappear in later Transfer logs if they receive again.
+			delete(c.holders, holder)
+			continue
+		}
+		balances = append(balances, holderBalance{holder: holder, balance: balance})
+	}
+
+	top, share := topHolderShare(balances, total, c.topN)
+	above := share.Cmp(new(big.Rat).Quo(c.thresholdPct, big.NewRat(100, 1))) >= 0
+	previous := c.above
+	c.above = &above
+	logger.Debugf("asset %s top %d holders hold %s%% of supply", a.name, len(top), new(big.Rat).Mul(share, big.NewRat(100, 1)).FloatString(4))
+	if previous == nil || *previous || !above {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(top))
+	for _, h := range top {
+		addresses = append(addresses, h.holder.Hex())
+	}
+	reason := fmt.Sprintf("top %d holders hold %s%% of supply (threshold %s%%)", len(top),
+		new(big.Rat).Mul(share, big.NewRat(100, 1)).FloatString(2), c.thresholdPct.FloatString(2))
+	if a.paused.Load() {
+		logger.Infof("asset %s paused: suppressing %s: %s", a.name, notify.EventConcentration, reason)
+		return nil
+	}
+	if a.shadow {
+		logger.Infof("asset %s shadow: would notify %s: %s", a.name, notify.EventConcentration, reason)
+		return nil
+	}
+	logger.Infof("asset %s %s", a.name, reason)
+	d.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventConcentration,
+		AssetName:      a.name,
+		AssetAddress:   a.address.Hex(),
+		Labels:         a.labels,
+		ExplorerURL:    a.explorerLink(),
+		NewTotalSupply: total,
+		Decimals:       decimals,
+		Concentration:  share,
+		TopHolders:     addresses,
+		Source:         notify.SourceRPC,
+		TriggerReasons: []string{reason, fmt.Sprintf("top holders: %s", strings.Join(addresses, ", "))},
+		ObservedAt:     time.Now(),
+	})
+	a.counters.notified.Add(1)
+	return nil
+}
+
+// candidates returns the addresses whose balances are read this check. The events
+// source scans Transfer logs up to the current block, keeping what it has scanned if a
+// chunk fails; the subgraph source asks for the topN largest positions.
+func (c *concentrationWatcher) candidates(ctx context.Context, client *aave.Client) ([]common.Address, error) {
+	a := c.asset
+	if c.source == config.HolderSourceSubgraph {
+		holders, err := client.GraphTopHolders(ctx, c.graphURL, a.address, c.topN)
+		if err != nil {
+			return nil, fmt.Errorf("fetch top holders from subgraph: %w", err)
+		}
+		return holders, nil
+	}
+
+	head, _, err := client.LatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for c.nextBlock <= head {
+		to := min(c.nextBlock+c.blockRange-1, head)
+		recipients, err := client.TransferRecipients(ctx, a.address, c.nextBlock, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, recipient := range recipients {
+			c.holders[recipient] = struct{}{}
+		}
+		c.nextBlock = to + 1
+	}
+
+	holders := make([]common.Address, 0, len(c.holders))
+	for holder := range c.holders {
+		holders = append(holders, holder)
+	}
+	return holders, nil
+}
+
+// topHolderShare returns the n largest balances, largest first, and the share of total
+// they hold. The share is zero when total is zero.
+func topHolderShare(balances []holderBalance, total *big.Int, n int) ([]holderBalance, *big.Rat) {
+	sorted := append([]holderBalance(nil), balances...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].balance.Cmp(sorted[j].balance) > 0
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	if total.Sign() == 0 {
+		return sorted, new(big.Rat)
+	}
+	held := new(big.Int)
+	for _, h := range sorted {
+		held.Add(held, h.balance)
+	}
+	return sorted, new(big.Rat).SetFrac(held, total)
+}
@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+)
+
+func TestTopHolderShareTakesLargestBalances(t *testing.T) {
+	balances := []holderBalance{
+		{common.HexToAddress("0x01"), big.NewInt(10)},
+		{common.HexToAddress("0x02"), big.NewInt(40)},
+		{common.HexToAddress("0x03"), big.NewInt(25)},
+		{common.HexToAddress("0x04"), big.NewInt(5)},
+	}
+
+	top, share := topHolderShare(balances, big.NewInt(100), 2)
+	if len(top) != 2 || top[0].holder != common.HexToAddress("0x02") || top[1].holder != common.HexToAddress("0x03") {
+		t.Fatalf("top = %v, want 0x02 then 0x03", top)
+	}
+	if share.Cmp(big.NewRat(65, 100)) != 0 {
+		t.Errorf("share = %s, want 13/20", share.RatString())
+	}
+
+	top, share = topHolderShare(balances, big.NewInt(100), 10)
+	if len(top) != 4 || share.Cmp(big.NewRat(80, 100)) != 0 {
+		t.Errorf("fewer holders than n: got %d holders, share %s", len(top), share.RatString())
+	}
+
+	if _, share := topHolderShare(nil, new(big.Int), 10); share.Sign() != 0 {
+		t.Errorf("zero supply share = %s, want 0", share.RatString())
+	}
+}
+
+func TestNewConcentrationWatcherValidatesSource(t *testing.T) {
+	asset := &assetWatcher{name: "USDC"}
+	tests := []struct {
+		name    string
+		cfg     config.ConcentrationConfig
+		graph   string
+		wantErr bool
+	}{
+		{"events", config.ConcentrationConfig{ThresholdPct: "40", Source: config.HolderSourceEvents, FromBlock: 100}, "", false},
+		{"subgraph uses asset graph_url", config.ConcentrationConfig{ThresholdPct: "40", Source: config.HolderSourceSubgraph}, "https://graph.example", false},
+		{"subgraph without graph_url", config.ConcentrationConfig{ThresholdPct: "40", Source: config.HolderSourceSubgraph}, "", true},
+		{"subgraph with from_block", config.ConcentrationConfig{ThresholdPct: "40", Source: config.HolderSourceSubgraph, FromBlock: 1}, "https://graph.example", true},
+		{"missing source", config.ConcentrationConfig{ThresholdPct: "40"}, "", true},
+		{"missing threshold", config.ConcentrationConfig{Source: config.HolderSourceEvents}, "", true},
+		{"threshold above 100", config.ConcentrationConfig{ThresholdPct: "101", Source: config.HolderSourceEvents}, "", true},
+	}
+	for _, tt := range tests {
+		c, err := newConcentrationWatcher(asset, &tt.cfg, tt.graph)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (c.topN != defaultConcentrationTopN || c.pollInterval != defaultConcentrationPoll) {
+			t.Errorf("%s: defaults not applied: top_n %d, poll %s", tt.name, c.topN, c.pollInterval)
+		}
+	}
+}
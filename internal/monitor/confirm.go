@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+)
+
+// confirmer re-reads values from a second RPC provider before high-severity alerts are
+// sent, so a single endpoint returning bad data cannot page on its own.
+type confirmer struct {
+	client *aave.Client
+	// tolerance is the largest accepted difference as a percentage of the primary read.
+	tolerance *big.Rat
+	// flag sends disagreeing alerts with a warning instead of suppressing them.
+	flag bool
+}
+
+func newConfirmer(cfg *config.Config) (*confirmer, error) {
+	if cfg.ConfirmRPCURL == "" {
+		if cfg.ConfirmTolerancePct != "" || cfg.ConfirmOnDisagreement != "" {
+			return nil, fmt.Errorf("confirm_tolerance_pct and confirm_on_disagreement require confirm_rpc_url")
+		}
+		return nil, nil
+	}
+
+	tolerance, err := parsePercent(cfg.ConfirmTolerancePct)
+	if err != nil {
+		return nil, fmt.Errorf("confirm_tolerance_pct: %w", err)
+	}
+	if tolerance == nil {
+		tolerance = new(big.Rat)
+	}
+
+	c := &confirmer{tolerance: tolerance}
+	switch cfg.ConfirmOnDisagreement {
+	case "", config.ConfirmSuppress:
+	case config.ConfirmFlag:
+		c.flag = true
+	default:
+		return nil, fmt.Errorf("confirm_on_disagreement %q is not supported (use %s or %s)",
+			cfg.ConfirmOnDisagreement, config.ConfirmSuppress, config.ConfirmFlag)
+	}
+	return c, nil
+}
+
+// SetConfirmClient attaches the client for confirm_rpc_url. It must be called before Run.
+func (s *Service) SetConfirmClient(client *aave.Client) error {
+	if s.confirm == nil {
+		return fmt.Errorf("confirm_rpc_url is not configured")
+	}
+	s.confirm.client = client
+	return nil
+}
+
+// agrees reports whether the confirm read is within tolerance of the primary read.
+func (c *confirmer) agrees(primary, confirmed *big.Int) bool {
+	diff := new(big.Int).Sub(primary, confirmed)
+	diff.Abs(diff)
+	// diff/|primary| <= tolerance/100, cross-multiplied to stay exact.
+	lhs := new(big.Rat).SetInt(new(big.Int).Mul(diff, big.NewInt(100)))
+	rhs := new(big.Rat).Mul(c.tolerance, new(big.Rat).SetInt(new(big.Int).Abs(primary)))
+	return lhs.Cmp(rhs) <= 0
+}
+
+// confirmReading re-reads the tracked value from the confirm RPC. It returns extra trigger
+// reasons to attach and whether the alert may be sent. A disagreement suppresses the alert
+// unless the flag policy is set; a failed confirm read never blocks an alert.
+func (a *assetWatcher) confirmReading(ctx context.Context, primary *big.Int) ([]string, bool) {
+	c := a.confirm
+	if c == nil || c.client == nil {
+		return nil, true
+	}
+
+	confirmed, _, _, err := a.readTracked(ctx, c.client, &graphFallback{})
+	if err != nil {
+		logger.Warnf("asset %s confirmation read failed: %v", a.name, err)
+		return []string{fmt.Sprintf("unconfirmed: confirmation RPC read failed: %v", err)}, true
+	}
+	if c.agrees(primary, confirmed) {
+		return nil, true
+	}
+
+	logger.Warnf("asset %s confirmation RPC disagrees: primary %s, confirm %s", a.name, primary.String(), confirmed.String())
+	if !c.flag {
+		return nil, false
+	}
+	return []string{fmt.Sprintf("warning: confirmation RPC disagrees: primary %s, confirm %s", primary.String(), confirmed.String())}, true
+}
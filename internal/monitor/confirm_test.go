@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestConfirmerAgrees(t *testing.T) {
+	tests := []struct {
+		tolerance *big.Rat
+		primary   int64
+		confirmed int64
+		want      bool
+	}{
+		{new(big.Rat), 1000, 1000, true},
+		{new(big.Rat), 1000, 1001, false},
+		{big.NewRat(1, 10), 10000, 10010, true},
+		{big.NewRat(1, 10), 10000, 10011, false},
+		{big.NewRat(1, 10), 10000, 9990, true},
+		{big.NewRat(1, 10), 10000, 9989, false},
+		{big.NewRat(5, 1), 0, 0, true},
+		{big.NewRat(5, 1), 0, 1, false},
+	}
+	for _, tt := range tests {
+		c := &confirmer{tolerance: tt.tolerance}
+		if got := c.agrees(big.NewInt(tt.primary), big.NewInt(tt.confirmed)); got != tt.want {
+			t.Errorf("agrees(%d, %d) with %s%% = %v, want %v", tt.primary, tt.confirmed, tt.tolerance.RatString(), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"fmt"
+
+	"aave-cap-alerts/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// contractSet holds the resolved protocol contract addresses; nil means not configured.
+type contractSet struct {
+	pool         *common.Address
+	dataProvider *common.Address
+	oracle       *common.Address
+	configurator *common.Address
+	multicall3   *common.Address
+}
+
+// resolveContracts validates the contracts section and folds in the older top-level
+// pool_address, which must agree with contracts.pool when both are set.
+func resolveContracts(cfg *config.Config) (contractSet, error) {
+	var set contractSet
+	fields := []struct {
+		name  string
+		value string
+		dst   **common.Address
+	}{
+		{"contracts.pool", cfg.Contracts.Pool, &set.pool},
+		{"contracts.pool_data_provider", cfg.Contracts.PoolDataProvider, &set.dataProvider},
+		{"contracts.oracle", cfg.Contracts.Oracle, &set.oracle},
+		{"contracts.pool_configurator", cfg.Contracts.PoolConfigurator, &set.configurator},
+		{"contracts.multicall3", cfg.Contracts.Multicall3, &set.multicall3},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if !common.IsHexAddress(f.value) {
+			return contractSet{}, fmt.Errorf("%s is not a valid hex string", f.name)
+		}
+		addr := common.HexToAddress(f.value)
+		*f.dst = &addr
+	}
+
+	if cfg.PoolAddress != "" {
+		if !common.IsHexAddress(cfg.PoolAddress) {
+			return contractSet{}, fmt.Errorf("pool_address is not a valid hex string")
+		}
+		addr := common.HexToAddress(cfg.PoolAddress)
+		if set.pool != nil && *set.pool != addr {
+			return contractSet{}, fmt.Errorf("pool_address %s conflicts with contracts.pool %s", addr.Hex(), set.pool.Hex())
+		}
+		set.pool = &addr
+	}
+
+	return set, nil
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// underlyingDecimalsCheck compares an aToken's decimals with its underlying's, which
+// Aave keeps equal. A mismatch means the address is probably not the aToken it was meant
+// to be, even though its supply reads fine.
+type underlyingDecimalsCheck struct {
+	// strict turns a mismatch or a failed comparison into a check error.
+	strict bool
+	done   bool
+}
+
+// verifyUnderlyingDecimals runs the underlying decimals comparison once, after the
+// token's decimals are first read. Without strict a problem is only logged.
+func (a *assetWatcher) verifyUnderlyingDecimals(ctx context.Context, client *aave.Client, decimals uint8) error {
+	c := a.underlyingDecimals
+	if c == nil || c.done {
+		return nil
+	}
+	c.done = true
+
+	err := a.compareUnderlyingDecimals(ctx, client, decimals)
+	if err == nil {
+		return nil
+	}
+	if c.strict {
+		return err
+	}
+	logger.Warnf("asset %s %v", a.name, err)
+	return nil
+}
+
+func (a *assetWatcher) compareUnderlyingDecimals(ctx context.Context, client *aave.Client, decimals uint8) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return fmt.Errorf("verify underlying decimals: %w", err)
+	}
+	var underlyingDecimals uint8
+	err = a.retry.do(ctx, "asset "+a.name+" underlying decimals read", func() (err error) {
+		underlyingDecimals, err = client.Decimals(ctx, underlying)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("verify underlying decimals: %w", err)
+	}
+	if underlyingDecimals != decimals {
+		return fmt.Errorf("decimals %d differ from underlying %s decimals %d; check the configured address",
+			decimals, underlying.Hex(), underlyingDecimals)
+	}
+	logger.Debugf("asset %s decimals %d match underlying %s", a.name, decimals, underlying.Hex())
+	return nil
+}
+
+// recheckDecimals re-reads the token decimals once the recheck interval has elapsed and
+// fires a decimals event if they no longer match the cached value. A change usually means
+// a proxy upgrade or a misconfigured address, and every formatted amount after it would
+// otherwise be silently wrong.
+func (a *assetWatcher) recheckDecimals(ctx context.Context, client *aave.Client, d *dispatcher) error {
+	now := time.Now()
+	if now.Sub(a.lastDecimalsCheck) < a.decimalsRecheck {
+		return nil
+	}
+	a.lastDecimalsCheck = now
+
+	decimals, err := client.RefreshDecimals(ctx, a.address)
+	if err != nil {
+		return fmt.Errorf("recheck decimals: %w", err)
+	}
+	if decimals == a.tokenDecimals() {
+		return nil
+	}
+
+	previous := a.tokenDecimals()
+	a.setTokenDecimals(decimals)
+	logger.Warnf("asset %s decimals changed: %d -> %d", a.name, previous, decimals)
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventDecimalsChanged,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		NewTotalSupply:    cloneBigInt(a.lastTotalSupply),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            notify.SourceRPC,
+		TriggerReasons: []string{
+			fmt.Sprintf("token decimals changed from %d to %d; possible contract upgrade or misconfiguration", previous, decimals),
+		},
+		ObservedAt: a.observedAt(now),
+	})
+	return nil
+}
@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyUnderlyingDecimals(t *testing.T) {
+	client := newFakeChain(t, 6, big.NewInt(1000))
+	underlying := common.HexToAddress("0x2")
+
+	a := &assetWatcher{name: "USDC", address: common.HexToAddress("0x1"), underlying: &underlying,
+		underlyingDecimals: &underlyingDecimalsCheck{strict: true}}
+	if err := a.verifyUnderlyingDecimals(context.Background(), client, 6); err != nil {
+		t.Errorf("matching decimals: %v", err)
+	}
+	if err := (&assetWatcher{name: "USDC", address: common.HexToAddress("0x1"), underlying: &underlying,
+		underlyingDecimals: &underlyingDecimalsCheck{strict: true}}).verifyUnderlyingDecimals(context.Background(), client, 18); err == nil {
+		t.Error("expected a mismatch error in strict mode")
+	}
+
+	// The fake chain has no UNDERLYING_ASSET_ADDRESS, so the comparison cannot run.
+	strict := &assetWatcher{name: "USDC", address: common.HexToAddress("0x1"), underlyingDecimals: &underlyingDecimalsCheck{strict: true}}
+	if err := strict.verifyUnderlyingDecimals(context.Background(), client, 6); err == nil {
+		t.Error("expected an error in strict mode when the underlying cannot be read")
+	}
+	lenient := &assetWatcher{name: "USDC", address: common.HexToAddress("0x1"), underlyingDecimals: &underlyingDecimalsCheck{}}
+	if err := lenient.verifyUnderlyingDecimals(context.Background(), client, 6); err != nil {
+		t.Errorf("without strict a failed comparison should only warn: %v", err)
+	}
+	if !lenient.underlyingDecimals.done {
+		t.Error("the comparison should only run once")
+	}
+}
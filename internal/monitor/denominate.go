@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+)
+
+// denomination converts a wrapper token's tracked value to underlying units, so targets,
+// levels, and percentage triggers all apply to the underlying amount.
+type denomination struct {
+	rate *aave.ExchangeRate
+	// decimals overrides the underlying's decimals; nil means the wrapper's.
+	decimals        *uint8
+	wrapperDecimals uint8
+}
+
+// newDenomination parses denominate_in and exchange_rate; it returns nil when values are
+// tracked in the wrapper's own units.
+func newDenomination(name string, cfg config.AssetConfig) (*denomination, error) {
+	switch cfg.DenominateIn {
+	case "", config.DenominateWrapper:
+		if cfg.ExchangeRate != (config.ExchangeRateConfig{}) {
+			return nil, fmt.Errorf("asset %s exchange_rate requires denominate_in: %s", name, config.DenominateUnderlying)
+		}
+		return nil, nil
+	case config.DenominateUnderlying:
+	default:
+		return nil, fmt.Errorf("asset %s denominate_in %q is not supported", name, cfg.DenominateIn)
+	}
+
+	scale, err := parseThreshold(cfg.ExchangeRate.Scale)
+	if err != nil {
+		return nil, fmt.Errorf("asset %s exchange_rate.scale: %w", name, err)
+	}
+	rate, err := aave.NewExchangeRate(cfg.ExchangeRate.Method, scale)
+	if err != nil {
+		return nil, fmt.Errorf("asset %s exchange_rate: %w", name, err)
+	}
+	return &denomination{rate: rate, decimals: cfg.ExchangeRate.Decimals}, nil
+}
+
+// setTokenDecimals records the watched token's decimals. With a denomination the
+// tracked value is in underlying units, so a.decimals holds the underlying's decimals.
+func (a *assetWatcher) setTokenDecimals(decimals uint8) {
+	a.decimals = decimals
+	if a.denomination == nil {
+		return
+	}
+	a.denomination.wrapperDecimals = decimals
+	if a.denomination.decimals != nil {
+		a.decimals = *a.denomination.decimals
+	}
+}
+
+// tokenDecimals returns the watched token's own decimals.
+func (a *assetWatcher) tokenDecimals() uint8 {
+	if a.denomination != nil {
+		return a.denomination.wrapperDecimals
+	}
+	return a.decimals
+}
+
+// readTracked reads the tracked value and, with a denomination, converts it to underlying
+// units. wrapper is the unconverted value, nil without a denomination.
+func (a *assetWatcher) readTracked(ctx context.Context, client *aave.Client, fallback *graphFallback) (value, wrapper *big.Int, source string, err error) {
+	value, source, err = a.readSupply(ctx, client, fallback)
+	if err != nil || a.denomination == nil {
+		return value, nil, source, err
+	}
+	underlying, err := client.ToUnderlying(ctx, a.address, a.denomination.rate, value)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("convert %s to underlying: %w", a.metric(), err)
+	}
+	return underlying, value, source, nil
+}
@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"testing"
+
+	"aave-cap-alerts/internal/config"
+)
+
+func TestNewDenominationValidatesConfig(t *testing.T) {
+	six := uint8(6)
+	tests := []struct {
+		name    string
+		cfg     config.AssetConfig
+		want    bool
+		wantErr bool
+	}{
+		{"wrapper units", config.AssetConfig{}, false, false},
+		{"default ERC-4626 method", config.AssetConfig{DenominateIn: config.DenominateUnderlying}, true, false},
+		{"rate method with scale", config.AssetConfig{DenominateIn: config.DenominateUnderlying,
+			ExchangeRate: config.ExchangeRateConfig{Method: "exchangeRateStored()", Scale: "1e16", Decimals: &six}}, true, false},
+		{"rate method with default scale", config.AssetConfig{DenominateIn: config.DenominateUnderlying,
+			ExchangeRate: config.ExchangeRateConfig{Method: "getExchangeRate()"}}, true, false},
+		{"scale with amount method", config.AssetConfig{DenominateIn: config.DenominateUnderlying,
+			ExchangeRate: config.ExchangeRateConfig{Scale: "1e18"}}, false, true},
+		{"unsupported signature", config.AssetConfig{DenominateIn: config.DenominateUnderlying,
+			ExchangeRate: config.ExchangeRateConfig{Method: "rate(address)"}}, false, true},
+		{"exchange_rate without denominate_in", config.AssetConfig{
+			ExchangeRate: config.ExchangeRateConfig{Method: "getExchangeRate()"}}, false, true},
+		{"unknown denomination", config.AssetConfig{DenominateIn: "usd"}, false, true},
+	}
+	for _, tt := range tests {
+		d, err := newDenomination("wstETH", tt.cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if (d != nil) != tt.want {
+			t.Errorf("%s: denomination = %v, want set %v", tt.name, d, tt.want)
+		}
+	}
+}
+
+func TestDenominationKeepsTokenDecimalsSeparate(t *testing.T) {
+	eighteen := uint8(18)
+	a := &assetWatcher{denomination: &denomination{decimals: &eighteen}}
+	a.setTokenDecimals(8)
+	if a.decimals != 18 || a.tokenDecimals() != 8 {
+		t.Errorf("decimals = %d, token decimals = %d; want 18 and 8", a.decimals, a.tokenDecimals())
+	}
+
+	a = &assetWatcher{denomination: &denomination{}}
+	a.setTokenDecimals(6)
+	if a.decimals != 6 || a.tokenDecimals() != 6 {
+		t.Errorf("without an override: decimals = %d, token decimals = %d; want 6 and 6", a.decimals, a.tokenDecimals())
+	}
+}
@@ -0,0 +1,372 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// dispatcher fans events out to notifiers. Without routes every notifier receives every
+// event; with routes, each matching route delivers to its own notifier group.
+type dispatcher struct {
+	// set is swapped as a whole when notifiers are reloaded; each delivery uses the set
+	// current when it started.
+	set atomic.Pointer[notifierSet]
+	// queue, when set, makes dispatch enqueue and leaves delivery to the queue workers.
+	queue *notificationQueue
+	// quiet, when set, holds back non-critical events during quiet hours.
+	quiet *quietHours
+	stats *notifierStats
+	// leading, when set, reports whether this replica is the HA leader; events raised on
+	// a standby replica are dropped.
+	leading func() bool
+}
+
+// notifierSet is the notifiers, routes, and fallbacks built from one notifications section.
+type notifierSet struct {
+	notifiers []notify.Notifier
+	routes    []route
+	fallback  []notify.Notifier
+	// selectors limits notifiers, by name, to events whose asset labels match.
+	selectors map[string]labelSelector
+
+	// mu guards active, the deliveries using this set, and retired, set once a reload
+	// has replaced it. A retired set closes its notifiers when the last delivery ends.
+	mu      sync.Mutex
+	active  int
+	retired bool
+}
+
+type route struct {
+	assets       map[string]struct{}
+	eventTypes   map[notify.EventType]struct{}
+	notifiers    []notify.Notifier
+	firstSuccess bool
+}
+
+func newDispatcher(notifiers []notify.Notifier, routeCfgs []config.RouteConfig, fallbackNames []string, selectorExprs map[string]string) (*dispatcher, error) {
+	set, err := newNotifierSet(notifiers, routeCfgs, fallbackNames, selectorExprs)
+	if err != nil {
+		return nil, err
+	}
+	d := &dispatcher{stats: newNotifierStats()}
+	d.set.Store(set)
+	return d, nil
+}
+
+// acquire registers a delivery on the set; it fails once the set has been retired.
+func (s *notifierSet) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retired {
+		return false
+	}
+	s.active++
+	return true
+}
+
+// release ends a delivery and closes the notifiers if it was the last one on a retired
+// set.
+func (s *notifierSet) release() {
+	s.mu.Lock()
+	s.active--
+	drained := s.retired && s.active == 0
+	s.mu.Unlock()
+	if drained {
+		closeNotifiers(s.notifiers)
+	}
+}
+
+// retire marks a replaced set and closes its notifiers as soon as no delivery is using
+// it.
+func (s *notifierSet) retire() {
+	s.mu.Lock()
+	s.retired = true
+	drained := s.active == 0
+	s.mu.Unlock()
+	if drained {
+		closeNotifiers(s.notifiers)
+	}
+}
+
+func newNotifierSet(notifiers []notify.Notifier, routeCfgs []config.RouteConfig, fallbackNames []string, selectorExprs map[string]string) (*notifierSet, error) {
+	byName := make(map[string]notify.Notifier, len(notifiers))
+	for _, n := range notifiers {
+		if _, dup := byName[n.Name()]; dup {
+			return nil, fmt.Errorf("notifier name %q is used by more than one notifier", n.Name())
+		}
+		byName[n.Name()] = n
+	}
+
+	routes := make([]route, 0, len(routeCfgs))
+	for i, rc := range routeCfgs {
+		r := route{
+			assets:     make(map[string]struct{}, len(rc.Assets)),
+			eventTypes: make(map[notify.EventType]struct{}, len(rc.EventTypes)),
+		}
+
+		switch rc.Mode {
+		case "", config.RouteModeAll:
+		case config.RouteModeFirstSuccess:
+			r.firstSuccess = true
+		default:
+			return nil, fmt.Errorf("route %d: unknown mode %q", i, rc.Mode)
+		}
+
+		for _, asset := range rc.Assets {
+			r.assets[strings.ToLower(asset)] = struct{}{}
+		}
+		for _, name := range rc.EventTypes {
+			eventType, err := notify.ParseEventType(name)
+			if err != nil {
+				return nil, fmt.Errorf("route %d: %w", i, err)
+			}
+			r.eventTypes[eventType] = struct{}{}
+		}
+
+		if len(rc.Notifiers) == 0 {
+			return nil, fmt.Errorf("route %d: at least one notifier must be listed", i)
+		}
+		for _, name := range rc.Notifiers {
+			n, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("route %d: notifier %q is not configured", i, name)
+			}
+			r.notifiers = append(r.notifiers, n)
+		}
+
+		routes = append(routes, r)
+	}
+
+	fallback := make([]notify.Notifier, 0, len(fallbackNames))
+	for _, name := range fallbackNames {
+		n, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("failure_fallback: notifier %q is not configured", name)
+		}
+		fallback = append(fallback, n)
+	}
+
+	selectors := make(map[string]labelSelector, len(selectorExprs))
+	for name, expr := range selectorExprs {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("selector: notifier %q is not configured", name)
+		}
+		selector, err := parseSelector(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s selector: %w", name, err)
+		}
+		selectors[name] = selector
+	}
+
+	return &notifierSet{notifiers: notifiers, routes: routes, fallback: fallback, selectors: selectors}, nil
+}
+
+func (r route) matches(event notify.SupplyChangeEvent) bool {
+	if len(r.assets) > 0 {
+		_, byName := r.assets[strings.ToLower(event.AssetName)]
+		_, byAddr := r.assets[strings.ToLower(event.AssetAddress)]
+		if !byName && !byAddr {
+			return false
+		}
+	}
+	if len(r.eventTypes) > 0 {
+		if _, ok := r.eventTypes[event.Type]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// dispatch hands the event to the notification queue when one is configured and
+// otherwise delivers it immediately. Events held for quiet hours are dispatched when the
+// window ends; a standby replica drops them.
+func (d *dispatcher) dispatch(ctx context.Context, event notify.SupplyChangeEvent) {
+	if d.leading != nil && !d.leading() {
+		logger.Debugf("asset %s %s event not sent: standing by for the leader", event.AssetName, event.Type)
+		return
+	}
+	if d.quiet != nil && d.quiet.hold(event, time.Now()) {
+		return
+	}
+	if d.queue != nil {
+		d.queue.push(ctx, event)
+		return
+	}
+	d.deliverEvent(ctx, event)
+}
+
+// deliverEvent delivers the event. A notifier reached through several matching routes is
+// only called once per event. When notifiers were attempted but none succeeded, the
+// failure is logged distinctly and the event is handed to the fallback notifiers.
+func (d *dispatcher) deliverEvent(ctx context.Context, event notify.SupplyChangeEvent) {
+	set := d.acquireSet()
+	defer set.release()
+	attempted, delivered := d.deliver(ctx, set, event)
+	if attempted == 0 || delivered > 0 || ctx.Err() != nil {
+		return
+	}
+
+	logger.Errorf("asset %s ALERT DELIVERY FAILED: all %d notifier(s) failed for %s event", event.AssetName, attempted, event.Type)
+	if len(set.fallback) == 0 {
+		return
+	}
+
+	escalated := event
+	escalated.TriggerReasons = append(append([]string{}, event.TriggerReasons...),
+		fmt.Sprintf("alert delivery failed on all %d notifier(s)", attempted))
+	for _, n := range set.fallback {
+		if ctx.Err() != nil {
+			return
+		}
+		if !set.accepts(n, event) {
+			continue
+		}
+		d.send(ctx, n, escalated)
+	}
+}
+
+// acquireSet returns the current notifier set with a delivery registered on it. A set
+// retired between loading and acquiring it is no longer current, so the load is retried.
+func (d *dispatcher) acquireSet() *notifierSet {
+	for {
+		if set := d.set.Load(); set.acquire() {
+			return set
+		}
+	}
+}
+
+// deliver sends the event to its notifiers and reports how many were tried and how many
+// succeeded. An event that names its notifiers goes only to them.
+func (d *dispatcher) deliver(ctx context.Context, set *notifierSet, event notify.SupplyChangeEvent) (attempted, delivered int) {
+	if named := set.named(event); len(named) > 0 {
+		return d.deliverEach(ctx, set, named, event)
+	}
+	if len(set.routes) == 0 {
+		return d.deliverEach(ctx, set, set.notifiers, event)
+	}
+
+	// results records, per notifier instance, whether it delivered this event. A notifier
+	// already tried through an earlier route is not called again, but its success still
+	// ends a first_success route.
+	results := make(map[notify.Notifier]bool)
+	for _, r := range set.routes {
+		if !r.matches(event) {
+			continue
+		}
+		for _, n := range r.notifiers {
+			if ctx.Err() != nil {
+				return attempted, delivered
+			}
+			if !set.accepts(n, event) {
+				continue
+			}
+			ok, tried := results[n]
+			if !tried {
+				attempted++
+				ok = d.send(ctx, n, event)
+				results[n] = ok
+				if ok {
+					delivered++
+				}
+			}
+			if ok && r.firstSuccess {
+				break
+			}
+		}
+	}
+	return attempted, delivered
+}
+
+// deliverEach sends the event to each of notifiers its selector accepts.
+func (d *dispatcher) deliverEach(ctx context.Context, set *notifierSet, notifiers []notify.Notifier, event notify.SupplyChangeEvent) (attempted, delivered int) {
+	for _, n := range notifiers {
+		if ctx.Err() != nil {
+			return attempted, delivered
+		}
+		if !set.accepts(n, event) {
+			continue
+		}
+		attempted++
+		if d.send(ctx, n, event) {
+			delivered++
+		}
+	}
+	return attempted, delivered
+}
+
+// named returns the notifiers an event names in place of the routes. Names missing since
+// a notifier reload are logged; if none is left, the event is delivered as usual.
+func (s *notifierSet) named(event notify.SupplyChangeEvent) []notify.Notifier {
+	if len(event.Notifiers) == 0 {
+		return nil
+	}
+	var named []notify.Notifier
+	for _, name := range event.Notifiers {
+		found := false
+		for _, n := range s.notifiers {
+			if n.Name() == name {
+				named = append(named, n)
+				found = true
+				break
+			}
+		}
+		if !found {
+			logger.Warnf("asset %s %s event names notifier %q, which is not configured", event.AssetName, event.Type, name)
+		}
+	}
+	return named
+}
+
+// accepts reports whether the notifier's selector, if any, matches the event's labels.
+func (s *notifierSet) accepts(n notify.Notifier, event notify.SupplyChangeEvent) bool {
+	selector, ok := s.selectors[n.Name()]
+	return !ok || selector.matches(event.Labels)
+}
+
+// send delivers the event to one notifier and records how long it took and whether it
+// succeeded.
+func (d *dispatcher) send(ctx context.Context, n notify.Notifier, event notify.SupplyChangeEvent) bool {
+	notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	start := time.Now()
+	err := n.Notify(notifyCtx, event)
+	d.stats.record(n.Name(), time.Since(start), err == nil)
+	if err != nil {
+		logger.Warnf("asset %s notifier %s error: %v", event.AssetName, n.Name(), err)
+		return false
+	}
+	return true
+}
+
+// ReloadNotifiers replaces the notifiers, routes, and failure fallbacks with ones built
+// from a re-read notifications section. Watchers, baselines, and the notification queue
+// are untouched; deliveries already in progress finish with the previous notifiers, which
+// are closed, if they hold resources, once the last of those deliveries returns. On error
+// the current notifiers stay in place.
+func (s *Service) ReloadNotifiers(notifiers []notify.Notifier, cfg config.Notifications) error {
+	set, err := newNotifierSet(notifiers, cfg.Routes, cfg.FailureFallback, cfg.Selectors())
+	if err != nil {
+		return fmt.Errorf("notification routes: %w", err)
+	}
+	s.dispatcher.set.Swap(set).retire()
+	return nil
+}
+
+// closeNotifiers releases notifiers that hold resources, such as open database files.
+func closeNotifiers(notifiers []notify.Notifier) {
+	for _, n := range notifiers {
+		if closer, ok := n.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Warnf("close notifier %s: %v", n.Name(), err)
+			}
+		}
+	}
+}
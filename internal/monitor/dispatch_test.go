@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+type fakeNotifier struct {
+	name  string
+	fail  bool
+	calls int
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Notify(context.Context, notify.SupplyChangeEvent) error {
+	f.calls++
+	if f.fail {
+		return errors.New("fail")
+	}
+	return nil
+}
+
+func TestDispatchFirstSuccessHonorsEarlierDelivery(t *testing.T) {
+	a := &fakeNotifier{name: "a"}
+	b := &fakeNotifier{name: "b"}
+	d, err := newDispatcher([]notify.Notifier{a, b}, []config.RouteConfig{
+		{Notifiers: []string{"a"}},
+		{Notifiers: []string{"a", "b"}, Mode: config.RouteModeFirstSuccess},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.dispatch(context.Background(), notify.SupplyChangeEvent{Type: notify.EventSupplyIncrease})
+	if a.calls != 1 || b.calls != 0 {
+		t.Fatalf("calls a=%d b=%d, want a=1 b=0", a.calls, b.calls)
+	}
+}
+
+func TestDispatchFirstSuccessFallsThroughEarlierFailure(t *testing.T) {
+	a := &fakeNotifier{name: "a", fail: true}
+	b := &fakeNotifier{name: "b"}
+	d, err := newDispatcher([]notify.Notifier{a, b}, []config.RouteConfig{
+		{Notifiers: []string{"a"}},
+		{Notifiers: []string{"a", "b"}, Mode: config.RouteModeFirstSuccess},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.dispatch(context.Background(), notify.SupplyChangeEvent{Type: notify.EventSupplyIncrease})
+	if a.calls != 1 || b.calls != 1 {
+		t.Fatalf("calls a=%d b=%d, want a=1 b=1", a.calls, b.calls)
+	}
+}
+
+func TestNewDispatcherRejectsDuplicateNames(t *testing.T) {
+	_, err := newDispatcher([]notify.Notifier{&fakeNotifier{name: "x"}, &fakeNotifier{name: "x"}}, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected duplicate notifier name error")
+	}
+}
+
+func TestReloadNotifiersSwapsSet(t *testing.T) {
+	old := &fakeNotifier{name: "old"}
+	d, err := newDispatcher([]notify.Notifier{old}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{dispatcher: d}
+
+	replacement := &fakeNotifier{name: "new"}
+	if err := s.ReloadNotifiers([]notify.Notifier{replacement}, config.Notifications{
+		Routes: []config.RouteConfig{{Notifiers: []string{"missing"}}},
+	}); err == nil {
+		t.Fatalf("expected an error for a route naming an unknown notifier")
+	}
+	if err := s.ReloadNotifiers([]notify.Notifier{replacement}, config.Notifications{}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "USDC"})
+	if old.calls != 0 || replacement.calls != 1 {
+		t.Fatalf("calls old=%d new=%d, want 0 and 1", old.calls, replacement.calls)
+	}
+}
+
+// blockingNotifier holds each delivery until release is closed and records Close.
+type blockingNotifier struct {
+	started chan struct{}
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+func (b *blockingNotifier) Name() string { return "blocking" }
+
+func (b *blockingNotifier) Notify(context.Context, notify.SupplyChangeEvent) error {
+	close(b.started)
+	<-b.release
+	if b.closed.Load() {
+		return errors.New("notified after close")
+	}
+	return nil
+}
+
+func (b *blockingNotifier) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func TestReloadClosesPreviousSetAfterInFlightDelivery(t *testing.T) {
+	old := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{})}
+	d, err := newDispatcher([]notify.Notifier{old}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{dispatcher: d}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "USDC"})
+	}()
+	<-old.started
+	if err := s.ReloadNotifiers([]notify.Notifier{&fakeNotifier{name: "new"}}, config.Notifications{}); err != nil {
+		t.Fatal(err)
+	}
+	if old.closed.Load() {
+		t.Fatal("previous notifier closed while a delivery was still using it")
+	}
+	close(old.release)
+	<-done
+	if !old.closed.Load() {
+		t.Fatal("previous notifier not closed once its last delivery returned")
+	}
+	if stats := s.Notifiers(); len(stats) != 1 || stats[0].Successes != 1 {
+		t.Fatalf("stats = %+v, want the in-flight delivery to succeed", stats)
+	}
+}
+
+func TestDispatchRecordsNotifierStats(t *testing.T) {
+	ok := &fakeNotifier{name: "ok"}
+	bad := &fakeNotifier{name: "bad", fail: true}
+	d, err := newDispatcher([]notify.Notifier{ok, bad}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{dispatcher: d}
+
+	d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "USDC"})
+	d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "USDC"})
+
+	stats := s.Notifiers()
+	if len(stats) != 2 || stats[0].Name != "bad" || stats[1].Name != "ok" {
+		t.Fatalf("stats = %+v, want bad and ok sorted by name", stats)
+	}
+	if stats[0].Failures != 2 || stats[0].Successes != 0 || stats[1].Successes != 2 || stats[1].Count != 2 {
+		t.Fatalf("unexpected counts: %+v", stats)
+	}
+	if last := stats[1].BucketCounts[len(NotifierDurationBuckets)-1]; last != 2 {
+		t.Fatalf("largest bucket = %d, want 2", last)
+	}
+}
+
+func TestDispatchSkipsNotifiersWhoseSelectorDoesNotMatch(t *testing.T) {
+	stable := &fakeNotifier{name: "stable"}
+	all := &fakeNotifier{name: "all"}
+	d, err := newDispatcher([]notify.Notifier{stable, all}, nil, nil, map[string]string{"stable": "team=stablecoins"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "WETH", Labels: map[string]string{"team": "eth"}})
+	d.deliverEvent(context.Background(), notify.SupplyChangeEvent{AssetName: "USDC", Labels: map[string]string{"team": "stablecoins"}})
+	if stable.calls != 1 || all.calls != 2 {
+		t.Fatalf("calls stable=%d all=%d, want 1 and 2", stable.calls, all.calls)
+	}
+}
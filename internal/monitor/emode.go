@@ -0,0 +1,64 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// checkEMode reads the reserve's eMode category and fires an eMode event when it differs
+// from the previous reading. Moving into, out of, or between categories changes the
+// asset's LTV and liquidation parameters. The first reading only records the category.
+func (a *assetWatcher) checkEMode(ctx context.Context, client *aave.Client, d *dispatcher, totalSupply *big.Int, obs observation) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	category, err := client.EModeCategory(ctx, *a.pool, underlying)
+	if err != nil {
+		return fmt.Errorf("fetch eMode category: %w", err)
+	}
+
+	previous := a.eModeCategory
+	a.eModeCategory = &category
+	if previous == nil || *previous == category {
+		return nil
+	}
+
+	oldCategory := *previous
+	logger.Infof("asset %s eMode category changed: %d -> %d", a.name, oldCategory, category)
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventEModeChanged,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		NewTotalSupply:    new(big.Int).Set(totalSupply),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            notify.SourceRPC,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		OldEModeCategory:  &oldCategory,
+		NewEModeCategory:  &category,
+		TriggerReasons:    []string{eModeReason(oldCategory, category)},
+		ObservedAt:        obs.observedAt,
+	})
+	return nil
+}
+
+// eModeReason describes a category change; category 0 means no eMode.
+func eModeReason(oldCategory, newCategory uint8) string {
+	switch {
+	case oldCategory == 0:
+		return fmt.Sprintf("reserve entered eMode category %d", newCategory)
+	case newCategory == 0:
+		return fmt.Sprintf("reserve left eMode category %d", oldCategory)
+	default:
+		return fmt.Sprintf("reserve moved from eMode category %d to %d", oldCategory, newCategory)
+	}
+}
@@ -0,0 +1,19 @@
+package monitor
+
+import "testing"
+
+func TestEModeReason(t *testing.T) {
+	tests := []struct {
+		oldCategory, newCategory uint8
+		want                     string
+	}{
+		{0, 1, "reserve entered eMode category 1"},
+		{2, 0, "reserve left eMode category 2"},
+		{1, 3, "reserve moved from eMode category 1 to 3"},
+	}
+	for _, tt := range tests {
+		if got := eModeReason(tt.oldCategory, tt.newCategory); got != tt.want {
+			t.Errorf("eModeReason(%d, %d) = %q, want %q", tt.oldCategory, tt.newCategory, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+// projectCapETA extrapolates the buffered history linearly and returns how long, at the
+// average rate between the oldest and newest samples, the value needs to reach target,
+// falling to it when below is set. ok is false when there are too few samples, no time
+// has passed, the value is flat or moving away, or the target has already been reached.
+func projectCapETA(history *sampleRing, target *big.Int, below bool) (time.Duration, bool) {
+	oldest, newest, from, to, ok := history.span()
+	if !ok || target == nil {
+		return 0, false
+	}
+	elapsed := to.Sub(from)
+	if elapsed <= 0 {
+		return 0, false
+	}
+	growth := new(big.Int).Sub(newest, oldest)
+	remaining := new(big.Int).Sub(target, newest)
+	if below {
+		growth.Neg(growth)
+		remaining.Neg(remaining)
+	}
+	if growth.Sign() <= 0 {
+		return 0, false
+	}
+	if remaining.Sign() <= 0 {
+		return 0, false
+	}
+
+	// eta = remaining * elapsed / growth, computed in nanoseconds to keep precision.
+	eta := new(big.Int).Mul(remaining, big.NewInt(int64(elapsed)))
+	eta.Quo(eta, growth)
+	if !eta.IsInt64() {
+		return 0, false
+	}
+	return time.Duration(eta.Int64()), true
+}
+
+// checkCapETA fires a cap_eta event when the projected time to reach the effective
+// target drops below cap_eta_warn. It fires once and re-arms when the projection moves
+// back above the warning or can no longer be made.
+func (a *assetWatcher) checkCapETA(ctx context.Context, d *dispatcher, value *big.Int, source string, obs observation) {
+	if a.targetTotalSupply == nil {
+		return
+	}
+	eta, ok := projectCapETA(&a.history, a.effectiveTarget(), a.targetBelow)
+	if !ok || eta >= a.capETAWarn {
+		a.capETAWarned = false
+		return
+	}
+	if a.capETAWarned {
+		return
+	}
+	a.capETAWarned = true
+
+	reason := fmt.Sprintf("at current rate, %s reaches target %s in %s", a.metric(), a.effectiveTarget().String(), approxDuration(eta))
+	logger.Infof("asset %s %s", a.name, reason)
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventCapETA,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		OldTotalSupply:    cloneBigInt(a.lastTotalSupply),
+		NewTotalSupply:    new(big.Int).Set(value),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            source,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		CapETA:            eta,
+		TriggerReasons:    []string{reason},
+		History:           a.history.values(),
+		ObservedAt:        obs.observedAt,
+	})
+}
+
+// approxDuration renders a projection coarsely ("~2h", "~45m", "~3d"); finer precision
+// would suggest more confidence than a linear extrapolation deserves.
+func approxDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("~%dm", int(d.Round(time.Minute)/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("~%dh", int(d.Round(time.Hour)/time.Hour))
+	default:
+		return fmt.Sprintf("~%dd", int(d.Round(24*time.Hour)/(24*time.Hour)))
+	}
+}
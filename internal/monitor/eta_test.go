@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestProjectCapETA(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	ring := func(values ...int64) *sampleRing {
+		r := &sampleRing{}
+		for i, v := range values {
+			r.add(big.NewInt(v), start.Add(time.Duration(i)*time.Hour))
+		}
+		return r
+	}
+
+	// 100 per hour with 300 to go.
+	eta, ok := projectCapETA(ring(500, 600, 700), big.NewInt(1000), false)
+	if !ok || eta != 3*time.Hour {
+		t.Fatalf("eta = %v, %v; want 3h", eta, ok)
+	}
+
+	cases := []struct {
+		name   string
+		ring   *sampleRing
+		target int64
+	}{
+		{"single sample", ring(500), 1000},
+		{"flat", ring(500, 500, 500), 1000},
+		{"falling", ring(700, 600), 1000},
+		{"already reached", ring(900, 1000), 1000},
+	}
+	for _, tc := range cases {
+		if eta, ok := projectCapETA(tc.ring, big.NewInt(tc.target), false); ok {
+			t.Errorf("%s: got eta %v, want no projection", tc.name, eta)
+		}
+	}
+
+	// A downward target projects the fall toward it: 100 per hour with 300 to go.
+	if eta, ok := projectCapETA(ring(700, 600, 500), big.NewInt(200), true); !ok || eta != 3*time.Hour {
+		t.Errorf("below: eta = %v, %v; want 3h", eta, ok)
+	}
+	if eta, ok := projectCapETA(ring(500, 600, 700), big.NewInt(200), true); ok {
+		t.Errorf("below, rising: got eta %v, want no projection", eta)
+	}
+}
+
+func TestApproxDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		30 * time.Second:                "<1m",
+		44*time.Minute + 40*time.Second: "~45m",
+		2*time.Hour + 10*time.Minute:    "~2h",
+		80 * time.Hour:                  "~3d",
+	}
+	for d, want := range cases {
+		if got := approxDuration(d); got != want {
+			t.Errorf("approxDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
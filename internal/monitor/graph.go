@@ -0,0 +1,36 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/aave"
+)
+
+// graphFallback queries the subgraph at most once per check, and only after an RPC read
+// has failed, so decimals, the supply cap, and the supply can all fall back to it during
+// an RPC outage.
+type graphFallback struct {
+	url     string
+	fetched bool
+	reserve *aave.GraphReserve
+	err     error
+}
+
+// fallback returns the subgraph reserve to use in place of a failed RPC read. Without a
+// subgraph, or if the subgraph also fails, it returns an error wrapping rpcErr.
+func (g *graphFallback) fallback(ctx context.Context, client *aave.Client, aToken common.Address, what string, rpcErr error) (*aave.GraphReserve, error) {
+	if g.url == "" {
+		return nil, fmt.Errorf("fetch %s: %w", what, rpcErr)
+	}
+	if !g.fetched {
+		g.fetched = true
+		g.reserve, g.err = client.GraphReserve(ctx, g.url, aToken)
+	}
+	if g.err != nil {
+		return nil, fmt.Errorf("fetch %s: %w (graph fallback: %v)", what, rpcErr, g.err)
+	}
+	return g.reserve, nil
+}
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"math/big"
+	"time"
+)
+
+// historySize is how many recent samples each watcher keeps for sparklines and cap
+// projections.
+const historySize = 20
+
+// sampleRing is a fixed-size ring buffer of the most recent observed values and when
+// each was observed.
+type sampleRing struct {
+	samples [historySize]*big.Int
+	times   [historySize]time.Time
+	next    int
+	count   int
+}
+
+func (r *sampleRing) add(v *big.Int, at time.Time) {
+	r.samples[r.next] = new(big.Int).Set(v)
+	r.times[r.next] = at
+	r.next = (r.next + 1) % historySize
+	if r.count < historySize {
+		r.count++
+	}
+}
+
+// values returns copies of the buffered samples, oldest first.
+func (r *sampleRing) values() []*big.Int {
+	out := make([]*big.Int, 0, r.count)
+	start := (r.next - r.count + historySize) % historySize
+	for i := 0; i < r.count; i++ {
+		out = append(out, new(big.Int).Set(r.samples[(start+i)%historySize]))
+	}
+	return out
+}
+
+// span returns the oldest and newest buffered samples with their observation times; ok
+// is false with fewer than two samples.
+func (r *sampleRing) span() (oldest, newest *big.Int, from, to time.Time, ok bool) {
+	if r.count < 2 {
+		return nil, nil, time.Time{}, time.Time{}, false
+	}
+	first := (r.next - r.count + historySize) % historySize
+	last := (r.next - 1 + historySize) % historySize
+	return r.samples[first], r.samples[last], r.times[first], r.times[last], true
+}
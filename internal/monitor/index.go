@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// checkLiquidityIndex compares the reserve's liquidity index with the previous poll and
+// fires an index jump event when it moved by more than the configured percentage. The
+// index normally creeps up with accrued interest, so a large step in either direction is
+// a risk signal independent of supply movement.
+func (a *assetWatcher) checkLiquidityIndex(ctx context.Context, client *aave.Client, d *dispatcher, totalSupply *big.Int, obs observation) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	index, err := client.LiquidityIndex(ctx, *a.pool, underlying)
+	if err != nil {
+		return fmt.Errorf("fetch liquidity index: %w", err)
+	}
+
+	previous := a.lastIndex
+	a.lastIndex = index
+	if previous == nil || previous.Sign() <= 0 || index.Cmp(previous) == 0 {
+		return nil
+	}
+
+	change := relativeChange(previous, index)
+	magnitude := new(big.Rat).Abs(change)
+	threshold := new(big.Rat).Quo(a.indexJumpPct, big.NewRat(100, 1))
+	if magnitude.Cmp(threshold) <= 0 {
+		return nil
+	}
+
+	logger.Infof("asset %s liquidity index jump detected: %s -> %s", a.name, previous.String(), index.String())
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventIndexJump,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		NewTotalSupply:    new(big.Int).Set(totalSupply),
+		Change:            change,
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            notify.SourceRPC,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		OldLiquidityIndex: new(big.Int).Set(previous),
+		NewLiquidityIndex: new(big.Int).Set(index),
+		TriggerReasons: []string{
+			fmt.Sprintf("liquidity index moved more than %s%%: %s -> %s", a.indexJumpPct.FloatString(2), previous.String(), index.String()),
+		},
+		ObservedAt: obs.observedAt,
+	})
+	return nil
+}
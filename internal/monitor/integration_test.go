@@ -0,0 +1,225 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// mockATokenCode is the creation code of a minimal aToken. Its runtime answers
+// totalSupply(), scaledTotalSupply() and decimals() from storage slots 0, 1 and 2, and
+// any call with exactly 64 bytes of calldata stores the second word in the slot named by
+// the first, which is how the tests move the supply:
+//
+//	CALLDATASIZE PUSH1 0x40 EQ PUSH1 set JUMPI
+//	PUSH1 0 CALLDATALOAD PUSH1 0xe0 SHR
+//	DUP1 PUSH4 0x18160ddd EQ PUSH1 ts JUMPI
+//	DUP1 PUSH4 0xb1bf962d EQ PUSH1 sts JUMPI
+//	DUP1 PUSH4 0x313ce567 EQ PUSH1 dec JUMPI
+//	PUSH1 0 DUP1 REVERT
+//	ts:   JUMPDEST PUSH1 0 PUSH1 load JUMP
+//	sts:  JUMPDEST PUSH1 1 PUSH1 load JUMP
+//	dec:  JUMPDEST PUSH1 2 PUSH1 load JUMP
+//	load: JUMPDEST SLOAD PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+//	set:  JUMPDEST PUSH1 0x20 CALLDATALOAD PUSH1 0 CALLDATALOAD SSTORE STOP
+//
+// The first 11 bytes copy that runtime into memory and return it.
+const mockATokenCode = "605480600b6000396000f3" +
+	"36604014604b5760003560e01c806318160ddd14602f578063b1bf962d146035578063313ce56714603b57600080fd" +
+	"5b60006041565b60016041565b60026041565b5460005260206000f35b6020356000355500"
+
+// Storage slots of the mock aToken.
+const (
+	slotTotalSupply       = 0
+	slotScaledTotalSupply = 1
+	slotDecimals          = 2
+)
+
+// simnet is an in-process chain with one deployed mock aToken.
+type simnet struct {
+	t       *testing.T
+	backend *simulated.Backend
+	client  *aave.Client
+	key     *ecdsa.PrivateKey
+	signer  types.Signer
+	token   common.Address
+	nonce   uint64
+}
+
+func newSimnet(t *testing.T, decimals uint8) *simnet {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployer := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		deployer: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(100))},
+	})
+	t.Cleanup(func() { backend.Close() })
+
+	client, err := aave.NewClient(backend.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &simnet{t: t, backend: backend, client: client, key: key, signer: types.LatestSignerForChainID(chainID)}
+
+	receipt := s.send(nil, common.FromHex(mockATokenCode))
+	s.token = receipt.ContractAddress
+	s.set(slotDecimals, big.NewInt(int64(decimals)))
+	return s
+}
+
+// send mines a transaction from the funded account and returns its receipt; a nil to
+// deploys data as a contract.
+func (s *simnet) send(to *common.Address, data []byte) *types.Receipt {
+	s.t.Helper()
+	tx, err := types.SignNewTx(s.key, s.signer, &types.DynamicFeeTx{
+		Nonce:     s.nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(100 * params.GWei),
+		Gas:       200_000,
+		To:        to,
+		Data:      data,
+	})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.backend.Client().SendTransaction(ctx, tx); err != nil {
+		s.t.Fatal(err)
+	}
+	s.nonce++
+	s.backend.Commit()
+	receipt, err := s.backend.Client().TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		s.t.Fatalf("transaction %s reverted", tx.Hash())
+	}
+	return receipt
+}
+
+// set writes value to one of the mock aToken's storage slots.
+func (s *simnet) set(slot int64, value *big.Int) {
+	s.t.Helper()
+	data := append(common.BigToHash(big.NewInt(slot)).Bytes(), common.BigToHash(value).Bytes()...)
+	s.send(&s.token, data)
+}
+
+// setSupply moves both the total and the scaled supply to whole tokens.
+func (s *simnet) setSupply(tokens int64, decimals uint8) {
+	s.t.Helper()
+	value := new(big.Int).Mul(big.NewInt(tokens), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	s.set(slotTotalSupply, value)
+	s.set(slotScaledTotalSupply, value)
+}
+
+// received drains the types of the events the recorder has been sent so far.
+func received(r *recordingNotifier) []notify.EventType {
+	var types []notify.EventType
+	for {
+		select {
+		case event := <-r.events:
+			types = append(types, event.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func sameEventTypes(got, want []notify.EventType) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSimnetSupplyTriggers(t *testing.T) {
+	const decimals = 6
+	for _, metric := range []string{config.SupplyMetricTotal, config.SupplyMetricScaled} {
+		t.Run(metric, func(t *testing.T) {
+			chain := newSimnet(t, decimals)
+			chain.setSupply(800, decimals)
+
+			recorder := &recordingNotifier{events: make(chan notify.SupplyChangeEvent, 16)}
+			cfg := &config.Config{
+				Assets: []config.AssetConfig{{
+					Name:            "USDC",
+					Address:         chain.token.Hex(),
+					SupplyMetric:    metric,
+					TargetCapTokens: "1000e6", // base units
+					Triggers: []string{
+						config.TriggerIncreasePct,
+						config.TriggerDecreasePct,
+						config.TriggerCapReached,
+					},
+				}},
+			}
+			service, err := NewService(chain.client, cfg, []notify.Notifier{recorder}, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := service.assets[0]
+			check := func() []notify.EventType {
+				t.Helper()
+				if err := a.check(context.Background(), chain.client, service.dispatcher); err != nil {
+					t.Fatal(err)
+				}
+				return received(recorder)
+			}
+
+			if got := check(); len(got) != 0 {
+				t.Fatalf("first observation sent %v", got)
+			}
+			if got := check(); len(got) != 0 {
+				t.Fatalf("unchanged supply sent %v", got)
+			}
+
+			chain.setSupply(950, decimals)
+			if got := check(); !sameEventTypes(got, []notify.EventType{notify.EventSupplyIncrease}) {
+				t.Fatalf("increase below the cap sent %v", got)
+			}
+
+			// The crossing is also a >10% increase; the target event takes precedence.
+			chain.setSupply(1100, decimals)
+			if got := check(); !sameEventTypes(got, []notify.EventType{notify.EventTargetReached}) {
+				t.Fatalf("crossing the cap sent %v", got)
+			}
+			chain.setSupply(1300, decimals)
+			if got := check(); !sameEventTypes(got, []notify.EventType{notify.EventSupplyIncrease}) {
+				t.Fatalf("increase past the cap sent %v, want no second crossing", got)
+			}
+
+			chain.setSupply(990, decimals)
+			if got := check(); !sameEventTypes(got, []notify.EventType{notify.EventSupplyDecrease}) {
+				t.Fatalf("decrease sent %v", got)
+			}
+			if a.lastTotalSupply.Cmp(new(big.Int).Mul(big.NewInt(990), big.NewInt(1_000_000))) != 0 {
+				t.Fatalf("baseline = %s after the decrease", a.lastTotalSupply)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// checkDebtCeiling reads the reserve's isolation-mode debt and debt ceiling and fires a
+// debt ceiling event when utilization rises to or above the configured percentage. Assets
+// without a ceiling are not in isolation mode and are skipped.
+func (a *assetWatcher) checkDebtCeiling(ctx context.Context, client *aave.Client, d *dispatcher, totalSupply *big.Int, obs observation) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	debt, ceiling, err := client.IsolationDebt(ctx, *a.pool, underlying)
+	if err != nil {
+		return fmt.Errorf("fetch isolation debt: %w", err)
+	}
+	if ceiling.Sign() == 0 {
+		a.debtCeilingAbove = nil
+		return nil
+	}
+
+	utilization := new(big.Rat).SetFrac(debt, ceiling)
+	threshold := new(big.Rat).Quo(a.debtCeilingPct, big.NewRat(100, 1))
+	above := utilization.Cmp(threshold) >= 0
+	previous := a.debtCeilingAbove
+	a.debtCeilingAbove = &above
+	if previous == nil || *previous || !above {
+		return nil
+	}
+
+	pct := new(big.Rat).Mul(utilization, big.NewRat(100, 1)).FloatString(2)
+	logger.Infof("asset %s isolation debt ceiling utilization %s%% (debt %s / ceiling %s)", a.name, pct, debt.String(), ceiling.String())
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventDebtCeiling,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		NewTotalSupply:    new(big.Int).Set(totalSupply),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            notify.SourceRPC,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		IsolationDebt:     debt,
+		DebtCeiling:       ceiling,
+		TriggerReasons: []string{
+			fmt.Sprintf("isolation debt ceiling utilization reached %s%% (threshold %s%%)", pct, a.debtCeilingPct.FloatString(2)),
+		},
+		ObservedAt: obs.observedAt,
+	})
+	return nil
+}
@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/ha"
+	"aave-cap-alerts/internal/kv"
+)
+
+// Defaults for leader election.
+const (
+	defaultLeaseDuration = 15 * time.Second
+	defaultLeaderKey     = "aave-cap-alerts:leader"
+	defaultLeaseName     = "aave-cap-alerts"
+)
+
+// newElector builds the leader elector from cfg; it returns nil when HA is disabled.
+func newElector(cfg config.HAConfig, onChange func(leader bool)) (*ha.Elector, error) {
+	if cfg.Mode == "" {
+		if cfg.Identity != "" || cfg.LeaseDuration != "" || cfg.Redis != nil || cfg.Kubernetes != nil {
+			return nil, fmt.Errorf("ha settings require ha.mode")
+		}
+		return nil, nil
+	}
+	if cfg.Mode != config.HAModeRedis && cfg.Redis != nil {
+		return nil, fmt.Errorf("ha.redis requires ha.mode: %s", config.HAModeRedis)
+	}
+	if cfg.Mode != config.HAModeKubernetes && cfg.Kubernetes != nil {
+		return nil, fmt.Errorf("ha.kubernetes requires ha.mode: %s", config.HAModeKubernetes)
+	}
+
+	ttl := defaultLeaseDuration
+	if cfg.LeaseDuration != "" {
+		var err error
+		ttl, err = time.ParseDuration(cfg.LeaseDuration)
+		if err != nil {
+			return nil, fmt.Errorf("parse ha.lease_duration: %w", err)
+		}
+		if ttl < time.Second {
+			return nil, fmt.Errorf("ha.lease_duration must be at least 1s")
+		}
+	}
+	identity := cfg.Identity
+	if identity == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("ha.identity is unset and the hostname is unavailable: %w", err)
+		}
+		identity = host
+	}
+
+	var lock ha.Lock
+	switch cfg.Mode {
+	case config.HAModeRedis:
+		if cfg.Redis == nil || cfg.Redis.URL == "" {
+			return nil, fmt.Errorf("ha.mode %s requires ha.redis.url", cfg.Mode)
+		}
+		client, err := kv.NewRedis(cfg.Redis.URL)
+		if err != nil {
+			return nil, fmt.Errorf("ha.redis.url: %w", err)
+		}
+		key := cfg.Redis.Key
+		if key == "" {
+			key = defaultLeaderKey
+		}
+		lock = ha.NewRedisLock(client, key, identity, ttl)
+	case config.HAModeKubernetes:
+		var namespace, name string
+		if cfg.Kubernetes != nil {
+			namespace, name = cfg.Kubernetes.Namespace, cfg.Kubernetes.LeaseName
+		}
+		if name == "" {
+			name = defaultLeaseName
+		}
+		lease, err := ha.NewInClusterLeaseLock(namespace, name, identity, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("ha.mode %s: %w", cfg.Mode, err)
+		}
+		lock = lease
+	default:
+		return nil, fmt.Errorf("ha.mode must be %q or %q", config.HAModeRedis, config.HAModeKubernetes)
+	}
+	logger.Infof("leader election via %s as %q (lease %s)", cfg.Mode, identity, ttl)
+	return ha.NewElector(lock, ttl, onChange), nil
+}
+
+// leadershipChanged runs on the elector's goroutine. A replica that becomes leader
+// resumes every watcher from the shared state store, which the previous leader kept
+// current, so changes that happened while leadership moved are alerted on from the
+// last baseline that was reported. The snapshot sections, such as open incidents, are
+// reloaded too, so the new leader resolves what the previous one opened.
+func (s *Service) leadershipChanged(leader bool) {
+	if !leader || s.snapshot == nil {
+		return
+	}
+	resumed := 0
+	for _, a := range s.assets {
+		state, err := s.snapshot.store.Load(a.stateKey())
+		if errors.Is(err, ErrNoState) {
+			continue
+		}
+		if err != nil {
+			logger.Warnf("asset %s: load state for the new leader: %v; continuing from this replica's readings", a.name, err)
+			continue
+		}
+		a.snapshot.resumeFrom(state)
+		resumed++
+	}
+	logger.Infof("state snapshot: resuming %d of %d asset(s) from %v", resumed, len(s.assets), s.snapshot.store)
+
+	sections, ok := s.snapshot.store.(SectionStore)
+	if !ok {
+		return
+	}
+	s.snapshot.mu.Lock()
+	defer s.snapshot.mu.Unlock()
+	for name, section := range s.snapshot.sections {
+		if err := restoreSection(sections, name, section); err != nil {
+			logger.Warnf("state snapshot: %v; keeping this replica's %s", err, name)
+		}
+	}
+}
+
+// resumeFrom hands state to the watcher goroutine, which applies it at its next check.
+func (b *snapshotBox) resumeFrom(state State) {
+	b.mu.Lock()
+	b.resume = &state
+	b.mu.Unlock()
+}
+
+func (b *snapshotBox) takeResume() (State, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.resume == nil {
+		return State{}, false
+	}
+	state := *b.resume
+	b.resume = nil
+	return state, true
+}
+
+// applyResume replaces the watcher's state with the one handed over on a leadership
+// change, if any. It must be called from the watcher goroutine.
+func (a *assetWatcher) applyResume() {
+	if a.snapshot == nil {
+		return
+	}
+	state, ok := a.snapshot.takeResume()
+	if !ok || state.LastTotalSupply == nil {
+		return
+	}
+	a.history = sampleRing{}
+	a.restoreSnapshot(state)
+}
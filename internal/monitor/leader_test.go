@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestStandbyReplicaDropsEvents(t *testing.T) {
+	n := &fakeNotifier{name: "slack"}
+	d, err := newDispatcher([]notify.Notifier{n}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leading := false
+	d.leading = func() bool { return leading }
+	event := notify.SupplyChangeEvent{Type: notify.EventSupplyIncrease, AssetName: "USDC"}
+
+	d.dispatch(context.Background(), event)
+	if n.calls != 0 {
+		t.Fatalf("standby sent %d event(s)", n.calls)
+	}
+	leading = true
+	d.dispatch(context.Background(), event)
+	if n.calls != 1 {
+		t.Fatalf("leader sent %d event(s), want 1", n.calls)
+	}
+}
+
+func TestNewLeaderResumesFromStateStore(t *testing.T) {
+	store := &kvStateStore{store: memoryKV{}, prefix: "test:"}
+	a := &assetWatcher{name: "USDC", address: common.HexToAddress("0x1"), snapshot: &snapshotBox{}}
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	a.lastTotalSupply = big.NewInt(250)
+	a.history.add(big.NewInt(250), at)
+
+	// The previous leader last reported 200.
+	if err := store.Save(a.stateKey(), State{
+		LastTotalSupply: big.NewInt(200),
+		History:         []historySample{{Value: big.NewInt(200), At: at}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSection("incidents", json.RawMessage(`{"leader":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	incidents := &jsonSection{state: json.RawMessage(`{"standby":1}`)}
+	s := &Service{assets: []*assetWatcher{a}, snapshot: &snapshotter{store: store, sections: map[string]SnapshotSection{"incidents": incidents}}}
+	s.leadershipChanged(true)
+	if string(incidents.state) != `{"leader":1}` {
+		t.Fatalf("incidents = %s, want the previous leader's", incidents.state)
+	}
+	if a.lastTotalSupply.Int64() != 250 {
+		t.Fatal("state applied outside the watcher's check")
+	}
+
+	a.applyResume()
+	if a.lastTotalSupply.Int64() != 200 {
+		t.Fatalf("baseline = %s, want the leader's 200", a.lastTotalSupply)
+	}
+	if got := a.history.values(); len(got) != 1 || got[0].Int64() != 200 {
+		t.Fatalf("history = %v, want the leader's", got)
+	}
+}
+
+func TestNewElectorValidatesConfig(t *testing.T) {
+	for _, cfg := range []config.HAConfig{
+		{Identity: "pod-a"},
+		{Mode: "zookeeper"},
+		{Mode: config.HAModeRedis},
+		{Mode: config.HAModeRedis, Redis: &config.HARedisConfig{URL: "redis://localhost"}, LeaseDuration: "500ms"},
+		{Mode: config.HAModeRedis, Kubernetes: &config.HAKubernetesConfig{}},
+	} {
+		if _, err := newElector(cfg, nil); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+	if e, err := newElector(config.HAConfig{}, nil); e != nil || err != nil {
+		t.Errorf("unset = %v, %v; want disabled", e, err)
+	}
+	if _, err := newElector(config.HAConfig{Mode: config.HAModeRedis, Redis: &config.HARedisConfig{URL: "redis://localhost"}}, nil); err != nil {
+		t.Errorf("redis: %v", err)
+	}
+}
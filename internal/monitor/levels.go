@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+// alertLevel is an absolute level the tracked value is watched against.
+type alertLevel struct {
+	// tokens is the configured level in whole tokens, kept for messages.
+	tokens *big.Rat
+	// above records which side of the level the last reading was on; nil until the
+	// first reading.
+	above *bool
+}
+
+// baseUnits converts the level to base units with the token decimals, truncating.
+func (l *alertLevel) baseUnits(decimals uint8) *big.Int {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(l.tokens, new(big.Rat).SetInt(scale))
+	return new(big.Int).Quo(scaled.Num(), scaled.Denom())
+}
+
+// checkLevels compares the reading with every alert level and fires a level event for each
+// level whose side changed since the previous reading. The first reading only records
+// the sides.
+func (a *assetWatcher) checkLevels(ctx context.Context, d *dispatcher, value *big.Int, source string, obs observation) {
+	for _, level := range a.levels {
+		threshold := level.baseUnits(a.decimals)
+		above := value.Cmp(threshold) >= 0
+		previous := level.above
+		level.above = &above
+		if previous == nil || *previous == above {
+			continue
+		}
+
+		direction := "below"
+		if above {
+			direction = "above"
+		}
+		reason := fmt.Sprintf("%s crossed %s %s tokens", a.metric(), direction, level.tokens.RatString())
+		logger.Infof("asset %s %s", a.name, reason)
+		a.notify(ctx, d, notify.SupplyChangeEvent{
+			Type:              notify.EventLevelCrossed,
+			AssetName:         a.name,
+			AssetAddress:      a.address.Hex(),
+			ExplorerURL:       a.explorerLink(),
+			Holder:            a.holderHex(),
+			OldTotalSupply:    cloneBigInt(a.lastTotalSupply),
+			NewTotalSupply:    new(big.Int).Set(value),
+			TargetTotalSupply: threshold,
+			Decimals:          a.decimals,
+			Source:            source,
+			BlockNumber:       obs.blockNumber,
+			BlockTimestamp:    obs.blockTime,
+			TriggerReasons:    []string{reason},
+			ObservedAt:        obs.observedAt,
+		})
+	}
+}
@@ -2,24 +2,51 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"aave-cap-alerts/internal/aave"
 	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/ha"
+	"aave-cap-alerts/internal/logging"
 	"aave-cap-alerts/internal/notify"
 )
 
+var logger = logging.New("monitor")
+
 // Service coordinates polling the configured reserves and firing notifications when thresholds are crossed.
 type Service struct {
-	client      *aave.Client
-	assets      []*assetWatcher
-	notifiers   []notify.Notifier
-	defaultPoll time.Duration
+	client     *aave.Client
+	assets     []*assetWatcher
+	dispatcher *dispatcher
+	protocol   *protocolWatcher
+	pairs      []*pairWatcher
+	// aggregates sum the same asset across chains, each member on its own client.
+	aggregates []*aggregateWatcher
+	// concentrations are the opt-in top-holder checks, each on its own slow poll.
+	concentrations []*concentrationWatcher
+	defaultPoll    time.Duration
+	startup        *startupGate
+	// workers, when positive, selects the sharded scheduler with that many workers.
+	workers int
+	// strictStartup requires every asset's first check to succeed before Run proceeds.
+	strictStartup bool
+	confirm       *confirmer
+	// notifySummary sends the shutdown summary to the notifiers as well as the log.
+	notifySummary bool
+	// notifyStartup announces the service to the notifiers once it is running.
+	notifyStartup bool
+	started       time.Time
+	snapshot      *snapshotter
+	// elector, when set, makes this replica send notifications only while it leads.
+	elector *ha.Elector
 }
 
 // NewService builds a monitoring service from the loaded configuration.
@@ -28,7 +55,88 @@ func NewService(client *aave.Client, cfg *config.Config, notifiers []notify.Noti
 		return nil, fmt.Errorf("default poll interval must be positive")
 	}
 
+	d, err := newDispatcher(notifiers, cfg.Notifications.Routes, cfg.Notifications.FailureFallback, cfg.Notifications.Selectors())
+	if err != nil {
+		return nil, fmt.Errorf("notification routes: %w", err)
+	}
+	d.queue, err = newNotificationQueue(cfg.Notifications.Queue)
+	if err != nil {
+		return nil, err
+	}
+	d.quiet, err = newQuietHours(cfg.Notifications.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+
+	notifierNames := make(map[string]bool, len(notifiers))
+	for _, n := range notifiers {
+		notifierNames[n.Name()] = true
+	}
+
+	retry, err := newMetadataRetry(cfg.MetadataRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var decimalsRecheck time.Duration
+	if cfg.DecimalsRecheckInterval != "" {
+		decimalsRecheck, err = time.ParseDuration(cfg.DecimalsRecheckInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse decimals_recheck_interval: %w", err)
+		}
+		if decimalsRecheck < 0 {
+			return nil, fmt.Errorf("decimals_recheck_interval must not be negative")
+		}
+	}
+
+	var state *athStore
+	if cfg.StatePath != "" && cfg.Snapshot.Shared() {
+		return nil, fmt.Errorf("state_path keeps all-time highs in a local file; snapshot.backend %s already keeps them, so remove state_path", cfg.Snapshot.Backend)
+	}
+	if cfg.StatePath != "" {
+		state, err = loadATHStore(cfg.StatePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	contracts, err := resolveContracts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	confirm, err := newConfirmer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var capSource *aave.CapSource
+	if src := cfg.CapSource; src != nil || contracts.dataProvider != nil {
+		if src == nil {
+			src = &config.CapSourceConfig{}
+		}
+		address := contracts.dataProvider
+		if src.Address != "" {
+			if !common.IsHexAddress(src.Address) {
+				return nil, fmt.Errorf("cap_source address is not a valid hex string")
+			}
+			addr := common.HexToAddress(src.Address)
+			address = &addr
+		}
+		if address == nil {
+			return nil, fmt.Errorf("cap_source needs an address or contracts.pool_data_provider")
+		}
+		capSource, err = aave.NewCapSource(*address, src.ABI, src.Method, src.Output)
+		if err != nil {
+			return nil, fmt.Errorf("cap_source: %w", err)
+		}
+	}
+
+	pool := contracts.pool
+
 	watchers := make([]*assetWatcher, 0, len(cfg.Assets))
+	var concentrations []*concentrationWatcher
+	var problems []error
 	for _, assetCfg := range cfg.Assets {
 		name := assetCfg.Name
 		if name == "" {
@@ -41,19 +149,282 @@ func NewService(client *aave.Client, cfg *config.Config, notifiers []notify.Noti
 			return nil, fmt.Errorf("asset %s address is not a valid hex string", name)
 		}
 		addr := common.HexToAddress(assetCfg.Address)
-		target, err := parseBigInt(assetCfg.TargetCapTokens)
+		target, err := parseThreshold(assetCfg.TargetCapTokens)
 		if err != nil {
 			return nil, fmt.Errorf("asset %s target threshold: %w", name, err)
 		}
+		if assetCfg.UseSupplyCap {
+			if capSource == nil {
+				return nil, fmt.Errorf("asset %s use_supply_cap requires cap_source or contracts.pool_data_provider to be configured", name)
+			}
+			if target != nil {
+				return nil, fmt.Errorf("asset %s cannot set both target_cap_tokens and use_supply_cap", name)
+			}
+		}
 
 		watcher := &assetWatcher{
 			name:              name,
 			address:           addr,
 			targetTotalSupply: target,
-			notifyOnIncrease:  valueOrDefault(assetCfg.NotifyOnIncrease, true),
-			notifyOnDecrease:  valueOrDefault(assetCfg.NotifyOnDecrease, false),
+			notifyOnFirst:     assetCfg.NotifyOnFirst,
+			shadow:            assetCfg.Shadow,
+			trackATH:          assetCfg.TrackATH,
+			labels:            assetCfg.Labels,
+			retry:             retry,
+			confirm:           confirm,
 			pollInterval:      defaultPoll,
 		}
+		if assetCfg.UseSupplyCap {
+			watcher.capSource = capSource
+		}
+		if assetCfg.CapURL != "" {
+			if assetCfg.UseSupplyCap {
+				return nil, fmt.Errorf("asset %s cannot set both cap_url and use_supply_cap", name)
+			}
+			watcher.capURL = &capURL{url: assetCfg.CapURL, ttl: defaultCapURLTTL}
+			if assetCfg.CapURLTTL != "" {
+				ttl, err := time.ParseDuration(assetCfg.CapURLTTL)
+				if err != nil {
+					return nil, fmt.Errorf("parse asset %s cap_url_ttl: %w", name, err)
+				}
+				if ttl <= 0 {
+					return nil, fmt.Errorf("asset %s cap_url_ttl must be positive", name)
+				}
+				watcher.capURL.ttl = ttl
+			}
+		} else if assetCfg.CapURLTTL != "" {
+			return nil, fmt.Errorf("asset %s cap_url_ttl requires cap_url", name)
+		}
+		switch assetCfg.TargetDirection {
+		case "", config.TargetAbove:
+		case config.TargetBelow:
+			watcher.targetBelow = true
+		default:
+			return nil, fmt.Errorf("asset %s target_direction %q is not supported (use %s or %s)", name, assetCfg.TargetDirection, config.TargetAbove, config.TargetBelow)
+		}
+		if err := watcher.setTriggers(assetCfg); err != nil {
+			return nil, fmt.Errorf("asset %s %w", name, err)
+		}
+		for _, raw := range assetCfg.AlertLevels {
+			tokens, err := parseTokenAmount(raw)
+			if err != nil || tokens == nil || tokens.Sign() <= 0 {
+				return nil, fmt.Errorf("asset %s alert_levels: %q must be a positive token amount", name, raw)
+			}
+			watcher.levels = append(watcher.levels, &alertLevel{tokens: tokens})
+		}
+		watcher.capLevels, err = newCapLevels(name, assetCfg.CapLevels, notifierNames)
+		if err != nil {
+			return nil, err
+		}
+
+		tolerance, err := parseTokenAmount(assetCfg.CapToleranceTokens)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s cap_tolerance_tokens: %w", name, err)
+		}
+		if tolerance != nil && tolerance.Sign() != 0 {
+			watcher.capTolerance = tolerance
+		}
+
+		if assetCfg.CapETAWarn != "" {
+			warn, err := time.ParseDuration(assetCfg.CapETAWarn)
+			if err != nil {
+				return nil, fmt.Errorf("parse asset %s cap_eta_warn: %w", name, err)
+			}
+			if warn <= 0 {
+				return nil, fmt.Errorf("asset %s cap_eta_warn must be positive", name)
+			}
+			if target == nil && !assetCfg.UseSupplyCap && assetCfg.CapURL == "" {
+				return nil, fmt.Errorf("asset %s cap_eta_warn requires target_cap_tokens, use_supply_cap, or cap_url", name)
+			}
+			watcher.capETAWarn = warn
+		}
+
+		deadband, err := parseThreshold(assetCfg.BaselineDeadband)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s baseline_deadband: %w", name, err)
+		}
+		if deadband != nil && deadband.Sign() > 0 {
+			watcher.deadband = deadband
+		}
+
+		reference, err := newSupplyReference(assetCfg.Reference, assetCfg.ReferenceValue)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s %w", name, err)
+		}
+		watcher.reference = reference
+
+		if assetCfg.CoalesceWindow != "" {
+			window, err := time.ParseDuration(assetCfg.CoalesceWindow)
+			if err != nil {
+				return nil, fmt.Errorf("parse asset %s coalesce_window: %w", name, err)
+			}
+			if window < 0 {
+				return nil, fmt.Errorf("asset %s coalesce_window must not be negative", name)
+			}
+			watcher.coalesceWindow = window
+		}
+		if assetCfg.ResumeGrace != "" {
+			grace, err := time.ParseDuration(assetCfg.ResumeGrace)
+			if err != nil {
+				return nil, fmt.Errorf("parse asset %s resume_grace: %w", name, err)
+			}
+			if grace < 0 {
+				return nil, fmt.Errorf("asset %s resume_grace must not be negative", name)
+			}
+			watcher.resumeGrace = &grace
+		}
+
+		if assetCfg.MaxAlertsPerHour < 0 {
+			return nil, fmt.Errorf("asset %s max_alerts_per_hour must not be negative", name)
+		}
+		if assetCfg.MaxAlertsPerHour > 0 {
+			watcher.limiter = &alertLimiter{max: assetCfg.MaxAlertsPerHour}
+		}
+
+		indexJump, err := parsePercent(assetCfg.IndexJumpPct)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s index_jump_pct: %w", name, err)
+		}
+		if indexJump != nil {
+			if pool == nil {
+				return nil, fmt.Errorf("asset %s index_jump_pct requires contracts.pool (or pool_address) to be configured", name)
+			}
+			watcher.pool = pool
+			watcher.indexJumpPct = indexJump
+		}
+
+		debtCeiling, err := parsePercent(assetCfg.DebtCeilingPct)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s debt_ceiling_pct: %w", name, err)
+		}
+		if debtCeiling != nil {
+			if pool == nil {
+				return nil, fmt.Errorf("asset %s debt_ceiling_pct requires contracts.pool (or pool_address) to be configured", name)
+			}
+			watcher.pool = pool
+			watcher.debtCeilingPct = debtCeiling
+		}
+
+		for _, rate := range []struct {
+			key, name, value string
+			dest             **rateThreshold
+		}{
+			{"liquidity_rate_pct", "liquidity rate", assetCfg.LiquidityRatePct, &watcher.liquidityRate},
+			{"borrow_rate_pct", "variable borrow rate", assetCfg.BorrowRatePct, &watcher.borrowRate},
+		} {
+			pct, err := parsePercent(rate.value)
+			if err != nil {
+				return nil, fmt.Errorf("asset %s %s: %w", name, rate.key, err)
+			}
+			if pct == nil {
+				continue
+			}
+			if pool == nil {
+				return nil, fmt.Errorf("asset %s %s requires contracts.pool (or pool_address) to be configured", name, rate.key)
+			}
+			watcher.pool = pool
+			*rate.dest = &rateThreshold{name: rate.name, pct: pct}
+		}
+
+		treasury, err := parseThreshold(assetCfg.TreasuryThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s treasury_threshold: %w", name, err)
+		}
+		if treasury != nil {
+			if pool == nil {
+				return nil, fmt.Errorf("asset %s treasury_threshold requires contracts.pool (or pool_address) to be configured", name)
+			}
+			watcher.pool = pool
+			watcher.treasuryThreshold = treasury
+		}
+
+		if watcher.eModeChange {
+			if pool == nil {
+				return nil, fmt.Errorf("asset %s emode_change (or the %s trigger) requires contracts.pool (or pool_address) to be configured", name, config.TriggerStateChange)
+			}
+			watcher.pool = pool
+		}
+
+		watcher.explorerURL = strings.TrimRight(cfg.ExplorerURL, "/")
+		if assetCfg.ExplorerURL != "" {
+			watcher.explorerURL = strings.TrimRight(assetCfg.ExplorerURL, "/")
+		}
+
+		watcher.graphURL = cfg.GraphURL
+		if assetCfg.GraphURL != "" {
+			watcher.graphURL = assetCfg.GraphURL
+		}
+
+		if assetCfg.Concentration != nil {
+			concentration, err := newConcentrationWatcher(watcher, assetCfg.Concentration, watcher.graphURL)
+			if err != nil {
+				return nil, err
+			}
+			concentrations = append(concentrations, concentration)
+		}
+
+		switch assetCfg.Track {
+		case "", config.TrackTotalSupply:
+			if assetCfg.Holder != "" {
+				return nil, fmt.Errorf("asset %s holder is only valid with track: %s", name, config.TrackHolderBalance)
+			}
+		case config.TrackHolderBalance:
+			if !common.IsHexAddress(assetCfg.Holder) {
+				return nil, fmt.Errorf("asset %s holder must be a valid hex address for track: %s", name, config.TrackHolderBalance)
+			}
+			if assetCfg.GraphURL != "" {
+				return nil, fmt.Errorf("asset %s graph_url cannot be used with track: %s", name, config.TrackHolderBalance)
+			}
+			watcher.graphURL = ""
+			holder := common.HexToAddress(assetCfg.Holder)
+			watcher.holder = &holder
+		default:
+			return nil, fmt.Errorf("asset %s track %q is not supported", name, assetCfg.Track)
+		}
+
+		switch assetCfg.SupplyMetric {
+		case "", config.SupplyMetricTotal:
+			watcher.supplyMetric = config.SupplyMetricTotal
+		case config.SupplyMetricScaled, config.SupplyMetricActual:
+			if watcher.holder != nil {
+				return nil, fmt.Errorf("asset %s supply_metric cannot be used with track: %s", name, config.TrackHolderBalance)
+			}
+			if assetCfg.SupplyMetric == config.SupplyMetricActual {
+				if pool == nil {
+					return nil, fmt.Errorf("asset %s supply_metric: %s requires contracts.pool (or pool_address) to be configured", name, config.SupplyMetricActual)
+				}
+				watcher.pool = pool
+			}
+			// The subgraph reports totalSupply, which is not comparable to these metrics.
+			watcher.graphURL = ""
+			watcher.supplyMetric = assetCfg.SupplyMetric
+		default:
+			return nil, fmt.Errorf("asset %s supply_metric %q is not supported", name, assetCfg.SupplyMetric)
+		}
+
+		watcher.denomination, err = newDenomination(name, assetCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		watcher.adaptive, err = newAdaptiveMode(name, assetCfg.Adaptive)
+		if err != nil {
+			return nil, err
+		}
+		watcher.subthreshold, err = newSubthresholdReport(name, assetCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		watcher.decimalsRecheck = decimalsRecheck
+		if cfg.VerifyUnderlyingDecimals {
+			watcher.underlyingDecimals = &underlyingDecimalsCheck{strict: cfg.StrictStartup}
+		}
+
+		if watcher.trackATH && state != nil {
+			watcher.state = state
+			watcher.ath = state.ath(watcher.stateKey())
+		}
 
 		if assetCfg.PollInterval != "" {
 			customPoll, err := time.ParseDuration(assetCfg.PollInterval)
@@ -66,40 +437,240 @@ func NewService(client *aave.Client, cfg *config.Config, notifiers []notify.Noti
 			watcher.pollInterval = customPoll
 		}
 
+		problems = append(problems, validateAsset(watcher, assetCfg))
+
+		watcher.publishStatus(nil, nil)
 		watchers = append(watchers, watcher)
 	}
+	if err := errors.Join(problems...); err != nil {
+		return nil, err
+	}
 
-	return &Service{
-		client:      client,
-		assets:      watchers,
-		notifiers:   notifiers,
-		defaultPoll: defaultPoll,
-	}, nil
+	var protocol *protocolWatcher
+	if pp := cfg.ProtocolPause; pp != nil && pp.Enabled {
+		if pool == nil {
+			return nil, fmt.Errorf("protocol_pause requires contracts.pool (or pool_address) to be configured")
+		}
+		protocol = &protocolWatcher{pool: *pool, pollInterval: defaultPoll}
+		if pp.PollInterval != "" {
+			interval, err := time.ParseDuration(pp.PollInterval)
+			if err != nil {
+				return nil, fmt.Errorf("parse protocol_pause poll interval: %w", err)
+			}
+			if interval <= 0 {
+				return nil, fmt.Errorf("protocol_pause poll interval must be positive")
+			}
+			protocol.pollInterval = interval
+		}
+	}
+
+	pairs, err := newPairWatchers(cfg.Pairs, defaultPoll, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := newAggregateWatchers(cfg.Aggregates, defaultPoll, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.SchedulerWorkers < 0 {
+		return nil, fmt.Errorf("scheduler_workers must not be negative")
+	}
+	if cfg.StartupConcurrency < 0 {
+		return nil, fmt.Errorf("startup_concurrency must not be negative")
+	}
+	var stagger time.Duration
+	if cfg.StartupStagger != "" {
+		stagger, err = time.ParseDuration(cfg.StartupStagger)
+		if err != nil {
+			return nil, fmt.Errorf("parse startup_stagger: %w", err)
+		}
+		if stagger < 0 {
+			return nil, fmt.Errorf("startup_stagger must not be negative")
+		}
+	}
+
+	snapshot, err := newSnapshotter(cfg.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		if err := snapshot.restore(watchers); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Service{
+		client:         client,
+		protocol:       protocol,
+		pairs:          pairs,
+		aggregates:     aggregates,
+		concentrations: concentrations,
+		assets:         watchers,
+		dispatcher:     d,
+		defaultPoll:    defaultPoll,
+		startup:        newStartupGate(cfg.StartupConcurrency, stagger),
+		workers:        cfg.SchedulerWorkers,
+		strictStartup:  cfg.StrictStartup,
+		confirm:        confirm,
+		notifySummary:  cfg.Notifications.ShutdownSummary,
+		notifyStartup:  cfg.Notifications.NotifyOnStartup,
+		snapshot:       snapshot,
+	}
+	s.elector, err = newElector(cfg.HA, s.leadershipChanged)
+	if err != nil {
+		return nil, err
+	}
+	if s.elector != nil {
+		d.leading = s.elector.IsLeader
+		if snapshot == nil {
+			logger.Warnf("ha.mode without a snapshot backend: a new leader continues from its own readings")
+		} else {
+			snapshot.leading = s.elector.IsLeader
+			if _, ok := snapshot.store.(*FileStateStore); ok {
+				logger.Warnf("ha.mode with snapshot.backend %s: replicas share state only if they share the file", config.SnapshotBackendFile)
+			}
+		}
+	}
+	return s, nil
 }
 
-// Run launches the monitoring loops and blocks until the context is cancelled.
+// notifyTimeout bounds a single notifier delivery. It is applied to a child of the
+// service context so cancelling the root still aborts in-flight sends immediately.
+const notifyTimeout = 10 * time.Second
+
+// Run launches the monitoring loops and blocks until the context is cancelled and every
+// loop, including any notification it was sending, has returned. The notifiers are
+// closed once the shutdown summary has been delivered.
 func (s *Service) Run(ctx context.Context) error {
 	if len(s.assets) == 0 {
 		return fmt.Errorf("no assets configured")
 	}
 
-	for _, asset := range s.assets {
-		go asset.run(ctx, s.client, s.notifiers)
+	s.started = time.Now()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Queue workers start first so events raised by strict initial checks are drained
+	// instead of blocking a full queue.
+	var wg sync.WaitGroup
+	if q := s.dispatcher.queue; q != nil {
+		for i := 0; i < q.workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				q.run(ctx, s.dispatcher)
+			}()
+		}
 	}
 
-	<-ctx.Done()
-	return ctx.Err()
-}
+	// The elector outlives ctx so the leader still holds the lock while it sends the
+	// shutdown summary and saves the final snapshot.
+	var electors sync.WaitGroup
+	stopElector := func() {}
+	if s.elector != nil {
+		s.elector.Campaign(ctx)
+		electCtx, cancelElect := context.WithCancel(context.Background())
+		stopElector = func() {
+			cancelElect()
+			electors.Wait()
+		}
+		defer stopElector()
+		electors.Add(1)
+		go func() {
+			defer electors.Done()
+			s.elector.Run(electCtx)
+		}()
+	}
+
+	if s.strictStartup {
+		if err := s.strictInitialChecks(ctx); err != nil {
+			cancel()
+			wg.Wait()
+			return fmt.Errorf("strict startup: %w", err)
+		}
+	}
+	if s.notifyStartup {
+		s.announceStartup(ctx)
+	}
+
+	if s.workers > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runSharded(ctx, s.workers)
+		}()
+	} else {
+		for i, asset := range s.assets {
+			wg.Add(1)
+			go func(asset *assetWatcher, position int) {
+				defer wg.Done()
+				asset.run(ctx, s.client, s.dispatcher, s.startup, position, s.strictStartup)
+			}(asset, i)
+		}
+	}
+
+	if s.protocol != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.protocol.run(ctx, s.client, s.dispatcher)
+		}()
+	}
+
+	if q := s.dispatcher.quiet; q != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.run(ctx, s.dispatcher)
+		}()
+	}
+
+	for _, pair := range s.pairs {
+		wg.Add(1)
+		go func(pair *pairWatcher) {
+			defer wg.Done()
+			pair.run(ctx, s.client, s.dispatcher)
+		}(pair)
+	}
+
+	for _, aggregate := range s.aggregates {
+		wg.Add(1)
+		go func(aggregate *aggregateWatcher) {
+			defer wg.Done()
+			aggregate.run(ctx, s.client, s.dispatcher)
+		}(aggregate)
+	}
+
+	for _, concentration := range s.concentrations {
+		wg.Add(1)
+		go func(concentration *concentrationWatcher) {
+			defer wg.Done()
+			concentration.run(ctx, s.client, s.dispatcher)
+		}(concentration)
+	}
 
-func parseBigInt(v string) (*big.Int, error) {
-	if v == "" {
-		return nil, nil
+	if s.snapshot != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.snapshot.run(ctx, s.assets)
+		}()
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	if q := s.dispatcher.quiet; q != nil {
+		q.flush(s.dispatcher)
 	}
-	value, ok := new(big.Int).SetString(v, 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid integer %q", v)
+	if s.snapshot != nil {
+		s.snapshot.write(s.assets)
 	}
-	return value, nil
+	s.finish()
+	stopElector()
+	closeNotifiers(s.dispatcher.set.Load().notifiers)
+	return ctx.Err()
 }
 
 func valueOrDefault(v *bool, fallback bool) bool {
@@ -115,116 +686,553 @@ type assetWatcher struct {
 	targetTotalSupply *big.Int
 	notifyOnIncrease  bool
 	notifyOnDecrease  bool
+	notifyOnTarget    bool
+	// targetBelow makes the target trigger fire on a downward crossing instead.
+	targetBelow       bool
+	labels            map[string]string
+	capTolerance      *big.Rat
+	capETAWarn        time.Duration
+	capETAWarned      bool
+	levels            []*alertLevel
+	capLevels         []*capLevel
+	trackATH          bool
+	ath               *allTimeHigh
+	state             *athStore
+	confirm           *confirmer
+	notifyOnFirst     bool
+	shadow            bool
+	paused            atomic.Bool
 	pollInterval      time.Duration
+	capSource         *aave.CapSource
+	capURL            *capURL
+	capLoaded         bool
+	retry             metadataRetry
+	holder            *common.Address
+	supplyMetric      string
+	graphURL          string
+	explorerURL       string
+	pool              *common.Address
+	denomination      *denomination
+	adaptive          *adaptiveMode
+	subthreshold      *subthresholdReport
+	indexJumpPct      *big.Rat
+	lastIndex         *big.Int
+	treasuryThreshold *big.Int
+	lastAccrued       *big.Int
+	debtCeilingPct    *big.Rat
+	liquidityRate     *rateThreshold
+	borrowRate        *rateThreshold
+	debtCeilingAbove  *bool
+	eModeChange       bool
+	eModeCategory     *uint8
+	lastObservedAt    time.Time
+	history           sampleRing
+	underlying        *common.Address
 	decimalsLoaded    bool
 	decimals          uint8
-	lastTotalSupply   *big.Int
+	decimalsRecheck   time.Duration
+	// underlyingDecimals, when set, compares the token's decimals with its underlying's
+	// once they are first read.
+	underlyingDecimals *underlyingDecimalsCheck
+	lastDecimalsCheck  time.Time
+	lastTotalSupply    *big.Int
+	deadband           *big.Int
+	reference          *supplyReference
+	limiter            *alertLimiter
+	coalesceWindow     time.Duration
+	pending            *pendingChange
+	// resumeGrace is the oldest restored baseline trusted without confirmation; nil
+	// trusts any. unconfirmedResume is set while a stale restored baseline awaits it.
+	resumeGrace       *time.Duration
+	unconfirmedResume bool
+	status            statusBox
+	counters          watcherCounters
+	lastCheckDuration time.Duration
+	// snapshot holds the state published for the state snapshot; nil when disabled.
+	snapshot *snapshotBox
 }
 
-func (a *assetWatcher) run(ctx context.Context, client *aave.Client, notifiers []notify.Notifier) {
+func (a *assetWatcher) run(ctx context.Context, client *aave.Client, d *dispatcher, gate *startupGate, position int, initialDone bool) {
+	// Run the initial check as soon as the startup gate admits it, unless strict startup
+	// already did.
+	if !initialDone {
+		release, ok := gate.acquire(ctx, position)
+		if !ok {
+			return
+		}
+		started := time.Now()
+		err := a.check(ctx, client, d)
+		release()
+		if err != nil {
+			logger.Warnf("asset %s initial check failed: %v", a.name, err)
+		}
+		a.recordCheck(started, err)
+	}
+
 	ticker := time.NewTicker(a.pollInterval)
 	defer ticker.Stop()
 
-	// Trigger an immediate check on startup.
-	if err := a.check(ctx, client, notifiers); err != nil {
-		log.Printf("asset %s initial check failed: %v", a.name, err)
-	}
-
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if err := a.check(ctx, client, notifiers); err != nil {
-				log.Printf("asset %s check failed: %v", a.name, err)
-			}
+		case <-a.flushTimer():
+		}
+		started := time.Now()
+		err := a.check(ctx, client, d)
+		if err != nil {
+			logger.Warnf("asset %s check failed: %v", a.name, err)
 		}
+		a.recordCheck(started, err)
+	}
+}
+
+// recordCheck counts a check that began at started and publishes the watcher's state. A
+// check that took longer than the poll interval is logged and counted as an overrun:
+// the ticker drops the ticks it missed, so repeated overruns mean the asset is checked
+// less often than configured.
+func (a *assetWatcher) recordCheck(started time.Time, err error) {
+	a.counters.checks.Add(1)
+	if err != nil {
+		a.counters.failures.Add(1)
+	}
+	now := time.Now()
+	a.lastCheckDuration = now.Sub(started)
+	if a.lastCheckDuration > a.pollInterval {
+		a.counters.overruns.Add(1)
+		logger.Warnf("asset %s check overran its %s poll interval by %s", a.name, a.pollInterval,
+			(a.lastCheckDuration - a.pollInterval).Round(time.Millisecond))
 	}
+	a.publishStatus(&now, err)
+	a.publishSnapshot()
 }
 
-func (a *assetWatcher) check(ctx context.Context, client *aave.Client, notifiers []notify.Notifier) error {
+func (a *assetWatcher) check(ctx context.Context, client *aave.Client, d *dispatcher) error {
+	a.applyResume()
+	fallback := &graphFallback{url: a.graphURL}
+
 	if !a.decimalsLoaded {
-		decimals, err := client.Decimals(ctx, a.address)
-		if err != nil {
-			return fmt.Errorf("fetch decimals: %w", err)
+		var decimals uint8
+		err := a.retry.do(ctx, "asset "+a.name+" decimals read", func() (err error) {
+			decimals, err = client.Decimals(ctx, a.address)
+			return err
+		})
+		if err == nil {
+			a.setTokenDecimals(decimals)
+			a.decimalsLoaded = true
+			a.lastDecimalsCheck = time.Now()
+			if err := a.verifyUnderlyingDecimals(ctx, client, decimals); err != nil {
+				return err
+			}
+		} else {
+			reserve, graphErr := fallback.fallback(ctx, client, a.address, "decimals", err)
+			if graphErr != nil {
+				return graphErr
+			}
+			// Graph decimals are used for this check only; the RPC is asked again next time.
+			logger.Warnf("asset %s decimals RPC read failed; using subgraph value", a.name)
+			a.setTokenDecimals(reserve.Decimals)
+		}
+	} else if a.decimalsRecheck > 0 {
+		if err := a.recheckDecimals(ctx, client, d); err != nil {
+			logger.Warnf("asset %s %v", a.name, err)
 		}
-		a.decimals = decimals
-		a.decimalsLoaded = true
+	}
+
+	if a.capSource != nil {
+		if err := a.refreshSupplyCap(ctx, client); err != nil {
+			if err := a.fallbackSupplyCap(ctx, client, fallback, err); err != nil {
+				return err
+			}
+		}
+	}
+	if a.capURL != nil {
+		a.refreshCapURL(ctx, time.Now())
 	}
 
 	if a.lastTotalSupply == nil {
-		log.Printf("asset %s check: last total supply not yet recorded", a.name)
+		logger.Debugf("asset %s check: last %s not yet recorded", a.name, a.metric())
 	} else {
-		log.Printf("asset %s check: last total supply %s", a.name, a.lastTotalSupply.String())
+		logger.Debugf("asset %s check: last %s %s", a.name, a.metric(), a.lastTotalSupply.String())
 	}
 
-	totalSupply, err := client.TotalSupply(ctx, a.address)
+	// The block header is best effort: if the RPC is down the subgraph fallback may still work.
+	var obs observation
+	var err error
+	obs.blockNumber, obs.blockTime, err = client.LatestBlock(ctx)
 	if err != nil {
-		return fmt.Errorf("fetch totalSupply: %w", err)
+		logger.Debugf("asset %s: %v", a.name, err)
+	}
+
+	totalSupply, wrapperSupply, source, err := a.readTracked(ctx, client, fallback)
+	if err != nil {
+		return err
+	}
+	obs.observedAt = a.observedAt(time.Now())
+	if a.holdUnconfirmedResume(totalSupply) {
+		return nil
+	}
+	a.history.add(totalSupply, obs.observedAt)
+	a.flushAdaptive(ctx, d, obs.observedAt)
+	a.flushSubthreshold(ctx, d, obs)
+
+	if a.indexJumpPct != nil {
+		if err := a.checkLiquidityIndex(ctx, client, d, totalSupply, obs); err != nil {
+			logger.Warnf("asset %s liquidity index check failed: %v", a.name, err)
+		}
+	}
+
+	if a.debtCeilingPct != nil {
+		if err := a.checkDebtCeiling(ctx, client, d, totalSupply, obs); err != nil {
+			logger.Warnf("asset %s debt ceiling check failed: %v", a.name, err)
+		}
+	}
+
+	if a.liquidityRate != nil || a.borrowRate != nil {
+		if err := a.checkRates(ctx, client, d, totalSupply, obs); err != nil {
+			logger.Warnf("asset %s reserve rate check failed: %v", a.name, err)
+		}
+	}
+
+	if a.treasuryThreshold != nil {
+		if err := a.checkTreasury(ctx, client, d, totalSupply, obs); err != nil {
+			logger.Warnf("asset %s treasury check failed: %v", a.name, err)
+		}
+	}
+
+	if a.eModeChange {
+		if err := a.checkEMode(ctx, client, d, totalSupply, obs); err != nil {
+			logger.Warnf("asset %s eMode check failed: %v", a.name, err)
+		}
+	}
+
+	if len(a.levels) > 0 {
+		a.checkLevels(ctx, d, totalSupply, source, obs)
+	}
+
+	if len(a.capLevels) > 0 {
+		a.checkCapLevels(ctx, d, totalSupply, source, obs)
+	}
+
+	if a.trackATH {
+		a.checkATH(ctx, d, totalSupply, source, obs)
+	}
+
+	if a.capETAWarn > 0 {
+		a.checkCapETA(ctx, d, totalSupply, source, obs)
+	}
+
+	if a.reference != nil && a.reference.update(totalSupply, obs.observedAt) {
+		logger.Infof("asset %s %s reference set to %s", a.name, a.reference.mode, totalSupply.String())
 	}
 
 	if a.lastTotalSupply == nil {
 		a.lastTotalSupply = new(big.Int).Set(totalSupply)
-		log.Printf("asset %s initial total supply %s", a.name, totalSupply.String())
+		logger.Infof("asset %s initial %s %s", a.name, a.metric(), totalSupply.String())
+		if a.notifyOnFirst {
+			a.notify(ctx, d, notify.SupplyChangeEvent{
+				Type:              notify.EventFirstObservation,
+				AssetName:         a.name,
+				AssetAddress:      a.address.Hex(),
+				ExplorerURL:       a.explorerLink(),
+				Holder:            a.holderHex(),
+				NewTotalSupply:    new(big.Int).Set(totalSupply),
+				TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+				Decimals:          a.decimals,
+				WrapperSupply:     wrapperSupply,
+				WrapperDecimals:   a.tokenDecimals(),
+				Source:            source,
+				BlockNumber:       obs.blockNumber,
+				BlockTimestamp:    obs.blockTime,
+				TriggerReasons:    []string{fmt.Sprintf("first observation of %s", a.metric())},
+				ObservedAt:        obs.observedAt,
+			})
+		}
 		return nil
 	}
 
+	coalesced := 0
+	if a.coalesceWindow > 0 {
+		ready, count := a.coalesce(totalSupply, obs.observedAt)
+		if !ready {
+			return nil
+		}
+		coalesced = count
+	}
+
 	if totalSupply.Cmp(a.lastTotalSupply) == 0 {
+		if coalesced > 0 {
+			logger.Debugf("asset %s %d coalesced change(s) netted to zero", a.name, coalesced)
+		}
 		return nil
 	}
 
-	reasons := a.evaluateTriggers(totalSupply)
+	// Movements smaller than the deadband leave the baseline untouched so they can
+	// accumulate against it instead of being absorbed one poll at a time.
+	if a.deadband != nil {
+		delta := new(big.Int).Sub(totalSupply, a.lastTotalSupply)
+		if delta.CmpAbs(a.deadband) < 0 {
+			return nil
+		}
+	}
+
+	eventType, reasons := a.evaluateTriggers(totalSupply)
 	if len(reasons) == 0 {
-		log.Printf("asset %s total supply changed to %s (no triggers matched)", a.name, totalSupply.String())
+		a.reportSubthreshold(ctx, d, totalSupply, source, obs)
 		a.lastTotalSupply = new(big.Int).Set(totalSupply)
 		return nil
 	}
 
+	if eventType == notify.EventTargetReached {
+		extra, ok := a.confirmReading(ctx, totalSupply)
+		if !ok {
+			// Keep the old baseline so the next poll evaluates, and confirms, the crossing again.
+			logger.Warnf("asset %s %s alert suppressed: confirmation RPC disagrees", a.name, eventType)
+			return nil
+		}
+		reasons = append(reasons, extra...)
+	}
+
+	// With a pinned reference the reported change is measured against it, like the triggers.
+	changeBase := a.lastTotalSupply
+	if ref := a.referenceValue(); ref != nil {
+		changeBase = ref
+	}
 	event := notify.SupplyChangeEvent{
+		Type:              eventType,
 		AssetName:         a.name,
 		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
 		OldTotalSupply:    new(big.Int).Set(a.lastTotalSupply),
 		NewTotalSupply:    new(big.Int).Set(totalSupply),
+		Change:            relativeChange(changeBase, totalSupply),
 		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		ReferenceSupply:   cloneBigInt(a.referenceValue()),
+		ReferenceMode:     a.referenceMode(),
 		Decimals:          a.decimals,
+		WrapperSupply:     wrapperSupply,
+		WrapperDecimals:   a.tokenDecimals(),
+		Source:            source,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
 		TriggerReasons:    reasons,
-		ObservedAt:        time.Now(),
+		CoalescedChanges:  coalesced,
+		History:           a.history.values(),
+		ObservedAt:        obs.observedAt,
 	}
 
-	log.Printf("asset %s total supply change detected: %s -> %s", a.name, a.lastTotalSupply.String(), totalSupply.String())
-	for _, notifier := range notifiers {
-		if err := notifier.Notify(ctx, event); err != nil {
-			log.Printf("asset %s notifier error: %v", a.name, err)
-		}
+	logger.Infof("asset %s %s change detected: %s -> %s", a.name, a.metric(), a.lastTotalSupply.String(), totalSupply.String())
+	a.notifyAdaptive(ctx, d, event)
+	if a.subthreshold != nil {
+		// The triggered change reports the movement from the baseline, so the drift
+		// accumulated below the triggers starts over.
+		a.subthreshold.reset()
 	}
 
 	a.lastTotalSupply = new(big.Int).Set(totalSupply)
 	return nil
 }
 
-func (a *assetWatcher) evaluateTriggers(newSupply *big.Int) []string {
+// readSupply fetches the value this watcher tracks: the token's totalSupply, or the
+// configured holder's balance. When the RPC read fails and a subgraph is configured, the
+// subgraph's supply is used instead and the returned source says so.
+func (a *assetWatcher) readSupply(ctx context.Context, client *aave.Client, fallback *graphFallback) (*big.Int, string, error) {
+	if a.holder != nil {
+		balance, err := client.BalanceOf(ctx, a.address, *a.holder)
+		if err != nil {
+			return nil, "", fmt.Errorf("fetch balanceOf: %w", err)
+		}
+		return balance, notify.SourceRPC, nil
+	}
+
+	switch a.supplyMetric {
+	case config.SupplyMetricScaled:
+		scaled, err := client.ScaledTotalSupply(ctx, a.address)
+		if err != nil {
+			return nil, "", fmt.Errorf("fetch scaledTotalSupply: %w", err)
+		}
+		return scaled, notify.SourceRPC, nil
+	case config.SupplyMetricActual:
+		underlying, err := a.resolveUnderlying(ctx, client)
+		if err != nil {
+			return nil, "", err
+		}
+		actual, err := client.ActualSupply(ctx, *a.pool, a.address, underlying)
+		if err != nil {
+			return nil, "", fmt.Errorf("fetch actual supply: %w", err)
+		}
+		return actual, notify.SourceRPC, nil
+	}
+
+	totalSupply, err := client.TotalSupply(ctx, a.address)
+	if err == nil {
+		return totalSupply, notify.SourceRPC, nil
+	}
+	reserve, graphErr := fallback.fallback(ctx, client, a.address, "totalSupply", err)
+	if graphErr != nil {
+		return nil, "", graphErr
+	}
+	logger.Warnf("asset %s totalSupply RPC read failed (%v); using subgraph value", a.name, err)
+	return new(big.Int).Set(reserve.TotalSupply), notify.SourceGraph, nil
+}
+
+// metric names the tracked value in logs and trigger reasons.
+func (a *assetWatcher) metric() string {
+	metric := "total supply"
+	switch {
+	case a.holder != nil:
+		metric = "holder balance"
+	case a.supplyMetric == config.SupplyMetricScaled:
+		metric = "scaled total supply"
+	case a.supplyMetric == config.SupplyMetricActual:
+		metric = "actual supply"
+	}
+	if a.denomination != nil {
+		return "underlying " + metric
+	}
+	return metric
+}
+
+// explorerLink returns the block explorer page for the watched token, or "" when no
+// explorer is configured.
+func (a *assetWatcher) explorerLink() string {
+	if a.explorerURL == "" {
+		return ""
+	}
+	return a.explorerURL + "/address/" + a.address.Hex()
+}
+
+func (a *assetWatcher) holderHex() string {
+	if a.holder == nil {
+		return ""
+	}
+	return a.holder.Hex()
+}
+
+// resolveUnderlying looks up the aToken's reserve asset once and caches it.
+func (a *assetWatcher) resolveUnderlying(ctx context.Context, client *aave.Client) (common.Address, error) {
+	if a.underlying == nil {
+		var underlying common.Address
+		err := a.retry.do(ctx, "asset "+a.name+" underlying asset read", func() (err error) {
+			underlying, err = client.UnderlyingAsset(ctx, a.address)
+			return err
+		})
+		if err != nil {
+			return common.Address{}, fmt.Errorf("resolve underlying asset: %w", err)
+		}
+		a.underlying = &underlying
+	}
+	return *a.underlying, nil
+}
+
+// refreshSupplyCap re-reads the on-chain supply cap and converts it from whole tokens into
+// base units so it can be compared against totalSupply. A zero cap means uncapped.
+func (a *assetWatcher) refreshSupplyCap(ctx context.Context, client *aave.Client) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var supplyCap *big.Int
+	// Only the first cap read is retried; later failures fall back to the cached cap.
+	read := func() (err error) {
+		supplyCap, err = client.SupplyCap(ctx, a.capSource, underlying)
+		return err
+	}
+	if a.capLoaded {
+		err = read()
+	} else {
+		err = a.retry.do(ctx, "asset "+a.name+" supply cap read", read)
+	}
+	if err != nil {
+		return err
+	}
+
+	a.setSupplyCap(supplyCap)
+	a.capLoaded = true
+	return nil
+}
+
+// setSupplyCap stores a cap given in whole tokens as the target, in base units.
+func (a *assetWatcher) setSupplyCap(supplyCap *big.Int) {
+	if supplyCap.Sign() == 0 {
+		a.targetTotalSupply = nil
+		return
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.decimals)), nil)
+	a.targetTotalSupply = new(big.Int).Mul(supplyCap, scale)
+}
+
+// fallbackSupplyCap handles a failed cap read. With a subgraph configured the last cap
+// read from the RPC is kept, or, before any succeeded, the subgraph's cap is used.
+func (a *assetWatcher) fallbackSupplyCap(ctx context.Context, client *aave.Client, fallback *graphFallback, rpcErr error) error {
+	if fallback.url != "" && a.capLoaded {
+		logger.Warnf("asset %s supply cap RPC read failed (%v); keeping cached cap", a.name, rpcErr)
+		return nil
+	}
+	reserve, err := fallback.fallback(ctx, client, a.address, "supply cap", rpcErr)
+	if err != nil {
+		return err
+	}
+	logger.Warnf("asset %s supply cap RPC read failed (%v); using subgraph value", a.name, rpcErr)
+	a.setSupplyCap(reserve.SupplyCap)
+	return nil
+}
+
+// evaluateTriggers returns the reasons an alert should fire along with the event type that
+// best describes them; a target crossing takes precedence over a plain increase/decrease.
+func (a *assetWatcher) evaluateTriggers(newSupply *big.Int) (notify.EventType, []string) {
 	reasons := make([]string, 0, 2)
+	var eventType notify.EventType
 
-	if a.lastTotalSupply != nil {
+	if ref := a.referenceValue(); ref != nil && a.lastTotalSupply != nil {
+		// Against a pinned reference the triggers fire on crossing, not on every poll
+		// that stays past it.
+		if a.notifyOnIncrease && increasedByMoreThanOnePercent(ref, newSupply) && !increasedByMoreThanOnePercent(ref, a.lastTotalSupply) {
+			reasons = append(reasons, fmt.Sprintf("%s increased more than 1%% from %s reference %s: now %s",
+				a.metric(), a.reference.mode, ref.String(), newSupply.String()))
+			eventType = notify.EventSupplyIncrease
+		}
+		if a.notifyOnDecrease && newSupply.Cmp(ref) < 0 && a.lastTotalSupply.Cmp(ref) >= 0 {
+			reasons = append(reasons, fmt.Sprintf("%s fell below %s reference %s: now %s",
+				a.metric(), a.reference.mode, ref.String(), newSupply.String()))
+			eventType = notify.EventSupplyDecrease
+		}
+	} else if a.lastTotalSupply != nil {
 		switch newSupply.Cmp(a.lastTotalSupply) {
 		case 1:
 			if a.notifyOnIncrease && increasedByMoreThanOnePercent(a.lastTotalSupply, newSupply) {
-				reasons = append(reasons, fmt.Sprintf("total supply increased more than 1%%: %s -> %s", a.lastTotalSupply.String(), newSupply.String()))
+				reasons = append(reasons, fmt.Sprintf("%s increased more than 1%%: %s -> %s", a.metric(), a.lastTotalSupply.String(), newSupply.String()))
+				eventType = notify.EventSupplyIncrease
 			}
 		case -1:
 			if a.notifyOnDecrease {
-				reasons = append(reasons, fmt.Sprintf("total supply decreased from %s to %s", a.lastTotalSupply.String(), newSupply.String()))
+				reasons = append(reasons, fmt.Sprintf("%s decreased from %s to %s", a.metric(), a.lastTotalSupply.String(), newSupply.String()))
+				eventType = notify.EventSupplyDecrease
 			}
 		}
 	}
 
-	if a.targetTotalSupply != nil && a.lastTotalSupply != nil {
-		if a.lastTotalSupply.Cmp(a.targetTotalSupply) < 0 && newSupply.Cmp(a.targetTotalSupply) >= 0 {
-			reasons = append(reasons, fmt.Sprintf("total supply reached target %s", a.targetTotalSupply.String()))
+	if a.notifyOnTarget && a.targetTotalSupply != nil && a.lastTotalSupply != nil {
+		threshold := a.effectiveTarget()
+		if a.pastTarget(newSupply, threshold) && !a.pastTarget(a.lastTotalSupply, threshold) {
+			verb := "reached"
+			if a.targetBelow {
+				verb = "fell to"
+			}
+			reason := fmt.Sprintf("%s %s target %s", a.metric(), verb, a.targetTotalSupply.String())
+			if threshold.Cmp(a.targetTotalSupply) != 0 {
+				reason = fmt.Sprintf("%s %s effective threshold %s (target %s, cap tolerance %s tokens)",
+					a.metric(), verb, threshold.String(), a.targetTotalSupply.String(), a.capTolerance.RatString())
+			}
+			reasons = append(reasons, reason)
+			eventType = notify.EventTargetReached
 		}
 	}
 
-	return reasons
+	return eventType, reasons
 }
 
 func cloneBigInt(v *big.Int) *big.Int {
@@ -234,6 +1242,133 @@ func cloneBigInt(v *big.Int) *big.Int {
 	return new(big.Int).Set(v)
 }
 
+// relativeChange returns (new-old)/old exactly, or nil when old is not positive.
+func relativeChange(oldSupply, newSupply *big.Int) *big.Rat {
+	if oldSupply == nil || oldSupply.Sign() <= 0 {
+		return nil
+	}
+	delta := new(big.Int).Sub(newSupply, oldSupply)
+	return new(big.Rat).SetFrac(delta, oldSupply)
+}
+
+// setTriggers enables the configured trigger types. Without a triggers list the legacy
+// flags apply: notify_on_increase, notify_on_decrease and emode_change, cap_reached always,
+// and the velocity and utilization checks whenever cap_eta_warn and debt_ceiling_pct are
+// set. With a list, only the listed checks run, so their settings must be listed too.
+func (a *assetWatcher) setTriggers(assetCfg config.AssetConfig) error {
+	if len(assetCfg.Triggers) == 0 {
+		a.notifyOnIncrease = valueOrDefault(assetCfg.NotifyOnIncrease, true)
+		a.notifyOnDecrease = valueOrDefault(assetCfg.NotifyOnDecrease, false)
+		a.notifyOnTarget = true
+		a.eModeChange = assetCfg.EModeChange
+		return nil
+	}
+	if assetCfg.NotifyOnIncrease != nil || assetCfg.NotifyOnDecrease != nil || assetCfg.EModeChange {
+		return fmt.Errorf("cannot combine triggers with notify_on_increase/notify_on_decrease/emode_change")
+	}
+
+	var velocity, utilization bool
+	for _, trigger := range assetCfg.Triggers {
+		switch trigger {
+		case config.TriggerIncreasePct:
+			a.notifyOnIncrease = true
+		case config.TriggerDecreasePct:
+			a.notifyOnDecrease = true
+		case config.TriggerCapReached:
+			a.notifyOnTarget = true
+		case config.TriggerFloorBreached:
+			if !a.targetBelow {
+				return fmt.Errorf("trigger %s requires target_direction: %s", trigger, config.TargetBelow)
+			}
+			a.notifyOnTarget = true
+		case config.TriggerVelocity:
+			if assetCfg.CapETAWarn == "" {
+				return fmt.Errorf("trigger %s requires cap_eta_warn", trigger)
+			}
+			velocity = true
+		case config.TriggerStateChange:
+			a.eModeChange = true
+		case config.TriggerUtilization:
+			if assetCfg.DebtCeilingPct == "" {
+				return fmt.Errorf("trigger %s requires debt_ceiling_pct", trigger)
+			}
+			utilization = true
+		default:
+			return fmt.Errorf("trigger %q is not supported", trigger)
+		}
+	}
+	if assetCfg.CapETAWarn != "" && !velocity {
+		return fmt.Errorf("cap_eta_warn requires the %s trigger when triggers is set", config.TriggerVelocity)
+	}
+	if assetCfg.DebtCeilingPct != "" && !utilization {
+		return fmt.Errorf("debt_ceiling_pct requires the %s trigger when triggers is set", config.TriggerUtilization)
+	}
+	return nil
+}
+
+// triggerNames lists the enabled trigger types in canonical order. A target check on a
+// downward target is reported as floor_breached.
+func (a *assetWatcher) triggerNames() []string {
+	names := make([]string, 0, 6)
+	if a.notifyOnIncrease {
+		names = append(names, config.TriggerIncreasePct)
+	}
+	if a.notifyOnDecrease {
+		names = append(names, config.TriggerDecreasePct)
+	}
+	if a.notifyOnTarget && a.targetBelow {
+		names = append(names, config.TriggerFloorBreached)
+	} else if a.notifyOnTarget {
+		names = append(names, config.TriggerCapReached)
+	}
+	if a.capETAWarn > 0 {
+		names = append(names, config.TriggerVelocity)
+	}
+	if a.eModeChange {
+		names = append(names, config.TriggerStateChange)
+	}
+	if a.debtCeilingPct != nil {
+		names = append(names, config.TriggerUtilization)
+	}
+	return names
+}
+
+// effectiveTarget applies cap_tolerance_tokens to the target: a positive tolerance moves
+// the threshold so the alert fires that many tokens before the target is reached, a
+// negative one requires a margin past it. With target_direction: below "before" means
+// above the target. The tolerance is converted to base units with the token decimals and
+// truncated toward zero.
+func (a *assetWatcher) effectiveTarget() *big.Int {
+	if a.capTolerance == nil {
+		return a.targetTotalSupply
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(a.decimals)), nil)
+	scaled := new(big.Rat).Mul(a.capTolerance, new(big.Rat).SetInt(scale))
+	tolerance := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	if a.targetBelow {
+		return new(big.Int).Add(a.targetTotalSupply, tolerance)
+	}
+	return new(big.Int).Sub(a.targetTotalSupply, tolerance)
+}
+
+// pastTarget reports whether value is at or beyond threshold in the target's direction.
+// The target trigger fires when the value moves from not past to past, so it re-arms
+// only after the value has moved back.
+func (a *assetWatcher) pastTarget(value, threshold *big.Int) bool {
+	if a.targetBelow {
+		return value.Cmp(threshold) <= 0
+	}
+	return value.Cmp(threshold) >= 0
+}
+
+// targetDirection names the target's direction for events; empty is the default, above.
+func (a *assetWatcher) targetDirection() string {
+	if a.targetBelow {
+		return notify.TargetDirectionBelow
+	}
+	return ""
+}
+
 func increasedByMoreThanOnePercent(oldSupply, newSupply *big.Int) bool {
 	if oldSupply == nil || oldSupply.Sign() <= 0 {
 		return false
@@ -243,3 +1378,23 @@ func increasedByMoreThanOnePercent(oldSupply, newSupply *big.Int) bool {
 	threshold := new(big.Int).Mul(oldSupply, big.NewInt(110))
 	return scaledNew.Cmp(threshold) == 1
 }
+
+// observation captures when a check read the chain. Every event raised by that check
+// shares it, so retries and coalesced notifications carry the read time rather than the
+// send time.
+type observation struct {
+	blockNumber uint64
+	// blockTime is the latest block's header timestamp, zero if the header read failed.
+	blockTime  time.Time
+	observedAt time.Time
+}
+
+// observedAt returns now, clamped so observation times never go backwards for a watcher
+// even if the wall clock is stepped back.
+func (a *assetWatcher) observedAt(now time.Time) time.Time {
+	if now.Before(a.lastObservedAt) {
+		now = a.lastObservedAt
+	}
+	a.lastObservedAt = now
+	return now
+}
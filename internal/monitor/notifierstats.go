@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// NotifierDurationBuckets are the upper bounds, in seconds, of the notifier latency
+// histogram. Deliveries are capped by notifyTimeout, so the last bucket covers it.
+var NotifierDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// NotifierStats summarizes deliveries to one notifier since startup.
+type NotifierStats struct {
+	Name string
+	// BucketCounts[i] counts deliveries that took at most NotifierDurationBuckets[i]
+	// seconds; the counts are cumulative, as Prometheus expects.
+	BucketCounts []uint64
+	// DurationSum is the total time spent in Notify, in seconds; Count is the number of
+	// calls and equals Successes plus Failures.
+	DurationSum float64
+	Count       uint64
+	Successes   uint64
+	Failures    uint64
+}
+
+// notifierStats records the latency and outcome of every Notify call, keyed by notifier
+// name so the series survive a notifier reload.
+type notifierStats struct {
+	mu     sync.Mutex
+	byName map[string]*NotifierStats
+}
+
+func newNotifierStats() *notifierStats {
+	return &notifierStats{byName: make(map[string]*NotifierStats)}
+}
+
+func (m *notifierStats) record(name string, took time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.byName[name]
+	if s == nil {
+		s = &NotifierStats{Name: name, BucketCounts: make([]uint64, len(NotifierDurationBuckets))}
+		m.byName[name] = s
+	}
+	seconds := took.Seconds()
+	for i, bound := range NotifierDurationBuckets {
+		if seconds <= bound {
+			s.BucketCounts[i]++
+		}
+	}
+	s.DurationSum += seconds
+	s.Count++
+	if ok {
+		s.Successes++
+	} else {
+		s.Failures++
+	}
+}
+
+func (m *notifierStats) snapshot() []NotifierStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]NotifierStats, 0, len(m.byName))
+	for _, s := range m.byName {
+		c := *s
+		c.BucketCounts = append([]uint64(nil), s.BucketCounts...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Notifiers reports delivery latency and success counts per notifier, sorted by name.
+func (s *Service) Notifiers() []NotifierStats {
+	return s.dispatcher.stats.snapshot()
+}
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// pairWatcher compares the supplies of two correlated tokens, such as an asset and its
+// bridged version, and notifies when they diverge. Supplies are normalized to whole
+// tokens with each token's decimals, and the divergence is |a-b| / max(a, b).
+type pairWatcher struct {
+	name         string
+	first        common.Address
+	second       common.Address
+	thresholdPct *big.Rat
+	pollInterval time.Duration
+	retry        metadataRetry
+
+	decimalsLoaded bool
+	firstDecimals  uint8
+	secondDecimals uint8
+	// above records which side of the threshold the last reading was on; nil until the
+	// first reading.
+	above *bool
+}
+
+// newPairWatchers builds the pair watchers from the configuration.
+func newPairWatchers(pairs []config.PairConfig, defaultPoll time.Duration, retry metadataRetry) ([]*pairWatcher, error) {
+	watchers := make([]*pairWatcher, 0, len(pairs))
+	for i, pc := range pairs {
+		name := pc.Name
+		if name == "" {
+			name = fmt.Sprintf("pair %d", i)
+		}
+		if !common.IsHexAddress(pc.First) || !common.IsHexAddress(pc.Second) {
+			return nil, fmt.Errorf("%s: first and second must be valid hex addresses", name)
+		}
+		threshold, err := parsePercent(pc.DivergencePct)
+		if err != nil {
+			return nil, fmt.Errorf("%s divergence_pct: %w", name, err)
+		}
+		if threshold == nil || threshold.Sign() <= 0 {
+			return nil, fmt.Errorf("%s divergence_pct must be positive", name)
+		}
+		p := &pairWatcher{
+			name:         name,
+			first:        common.HexToAddress(pc.First),
+			second:       common.HexToAddress(pc.Second),
+			thresholdPct: threshold,
+			pollInterval: defaultPoll,
+			retry:        retry,
+		}
+		if pc.PollInterval != "" {
+			interval, err := time.ParseDuration(pc.PollInterval)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s poll interval: %w", name, err)
+			}
+			if interval <= 0 {
+				return nil, fmt.Errorf("%s poll interval must be positive", name)
+			}
+			p.pollInterval = interval
+		}
+		watchers = append(watchers, p)
+	}
+	return watchers, nil
+}
+
+func (p *pairWatcher) run(ctx context.Context, client *aave.Client, d *dispatcher) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.check(ctx, client, d); err != nil && ctx.Err() == nil {
+			logger.Warnf("%s check failed: %v", p.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *pairWatcher) check(ctx context.Context, client *aave.Client, d *dispatcher) error {
+	if !p.decimalsLoaded {
+		err := p.retry.do(ctx, p.name+" decimals read", func() (err error) {
+			if p.firstDecimals, err = client.Decimals(ctx, p.first); err != nil {
+				return err
+			}
+			p.secondDecimals, err = client.Decimals(ctx, p.second)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("fetch decimals: %w", err)
+		}
+		p.decimalsLoaded = true
+	}
+
+	// Both supplies are read in one round-trip.
+	results, err := client.BatchTotalSupply(ctx, []common.Address{p.first, p.second})
+	if err != nil {
+		return fmt.Errorf("fetch totalSupply: %w", err)
+	}
+	for i, token := range []common.Address{p.first, p.second} {
+		if results[i].Err != nil {
+			return fmt.Errorf("fetch %s totalSupply: %w", token.Hex(), results[i].Err)
+		}
+	}
+	firstSupply, secondSupply := results[0].Supply, results[1].Supply
+
+	divergence := pairDivergence(firstSupply, p.firstDecimals, secondSupply, p.secondDecimals)
+	above := divergence.Cmp(new(big.Rat).Quo(p.thresholdPct, big.NewRat(100, 1))) > 0
+	previous := p.above
+	p.above = &above
+	logger.Debugf("%s divergence %s%%", p.name, new(big.Rat).Mul(divergence, big.NewRat(100, 1)).FloatString(4))
+	if previous == nil || *previous || !above {
+		return nil
+	}
+
+	pct := new(big.Rat).Mul(divergence, big.NewRat(100, 1)).FloatString(2)
+	reason := fmt.Sprintf("supplies diverged by %s%% (threshold %s%%): %s vs %s tokens", pct, p.thresholdPct.FloatString(2),
+		tokensString(firstSupply, p.firstDecimals), tokensString(secondSupply, p.secondDecimals))
+	logger.Infof("%s %s", p.name, reason)
+	d.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventPairDivergence,
+		AssetName:      p.name,
+		AssetAddress:   p.first.Hex(),
+		NewTotalSupply: firstSupply,
+		Decimals:       p.firstDecimals,
+		PairAddress:    p.second.Hex(),
+		PairSupply:     secondSupply,
+		PairDecimals:   p.secondDecimals,
+		Divergence:     divergence,
+		Source:         notify.SourceRPC,
+		TriggerReasons: []string{reason},
+		ObservedAt:     time.Now(),
+	})
+	return nil
+}
+
+// pairDivergence returns |a-b| / max(a, b) with both supplies in whole tokens, or zero
+// when both are zero.
+func pairDivergence(first *big.Int, firstDecimals uint8, second *big.Int, secondDecimals uint8) *big.Rat {
+	a := tokensRat(first, firstDecimals)
+	b := tokensRat(second, secondDecimals)
+	larger := a
+	if b.Cmp(a) > 0 {
+		larger = b
+	}
+	if larger.Sign() == 0 {
+		return new(big.Rat)
+	}
+	diff := new(big.Rat).Abs(new(big.Rat).Sub(a, b))
+	return diff.Quo(diff, larger)
+}
+
+func tokensRat(v *big.Int, decimals uint8) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(v, scale)
+}
+
+// tokensString renders a base-unit amount in whole tokens with two decimals.
+func tokensString(v *big.Int, decimals uint8) string {
+	return tokensRat(v, decimals).FloatString(2)
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPairDivergenceNormalizesDecimals(t *testing.T) {
+	tests := []struct {
+		first         int64
+		firstDecimals uint8
+		second        int64
+		secondDec     uint8
+		want          *big.Rat
+	}{
+		// 100 tokens at 6 decimals vs 100 tokens at 18 decimals.
+		{100_000_000, 6, 100, 0, new(big.Rat)},
+		{95_000_000, 6, 100, 0, big.NewRat(1, 20)},
+		{100, 0, 95_000_000, 6, big.NewRat(1, 20)},
+		{0, 6, 0, 18, new(big.Rat)},
+		{0, 6, 5, 0, big.NewRat(1, 1)},
+	}
+	for _, tt := range tests {
+		got := pairDivergence(big.NewInt(tt.first), tt.firstDecimals, big.NewInt(tt.second), tt.secondDec)
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("pairDivergence(%d/%d, %d/%d) = %s, want %s", tt.first, tt.firstDecimals, tt.second, tt.secondDec, got.RatString(), tt.want.RatString())
+		}
+	}
+}
+
+func TestPairCheckReadsSuppliesInABatch(t *testing.T) {
+	for _, batches := range []bool{true, false} {
+		client := newFakeChainServer(t, fakeChainHandler(6, big.NewInt(5_000_000), batches))
+		d, err := newDispatcher(nil, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := &pairWatcher{
+			name:         "pair",
+			first:        common.HexToAddress("0x1"),
+			second:       common.HexToAddress("0x2"),
+			thresholdPct: big.NewRat(1, 1),
+		}
+		// Without batch support the client falls back to single calls.
+		for range 2 {
+			if err := p.check(context.Background(), client, d); err != nil {
+				t.Fatalf("batches %v: check: %v", batches, err)
+			}
+		}
+		if p.above == nil || *p.above {
+			t.Errorf("batches %v: above = %v, want false", batches, p.above)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxThresholdExponent caps scientific-notation exponents; uint256 tops out just above 1e77.
+const maxThresholdExponent = 77
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// parseThreshold converts a configured threshold into an integer amount. Besides plain
+// decimal integers it accepts 0x-prefixed hex ("0xde0b6b3a7640000") and scientific
+// notation ("1.5e24"). Inputs that are negative, fractional after scaling, or larger than
+// a uint256 are rejected rather than silently truncated. An empty string means unset.
+func parseThreshold(v string) (*big.Int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+") {
+		return nil, fmt.Errorf("invalid threshold %q: sign prefixes are not allowed", v)
+	}
+
+	var value *big.Int
+	switch {
+	case strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X"):
+		digits := v[2:]
+		if digits == "" || strings.ContainsAny(digits, "+-") {
+			return nil, fmt.Errorf("invalid hex threshold %q", v)
+		}
+		parsed, ok := new(big.Int).SetString(digits, 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid hex threshold %q", v)
+		}
+		value = parsed
+	case strings.ContainsAny(v, "eE."):
+		parsed, err := parseScientific(v)
+		if err != nil {
+			return nil, err
+		}
+		value = parsed
+	default:
+		parsed, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		value = parsed
+	}
+
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid threshold %q: must not be negative", v)
+	}
+	if value.Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("threshold %q exceeds the uint256 range", v)
+	}
+	return value, nil
+}
+
+func parseScientific(v string) (*big.Int, error) {
+	mantissa, exponent := v, ""
+	if i := strings.IndexAny(v, "eE"); i >= 0 {
+		mantissa, exponent = v[:i], v[i+1:]
+		if exponent == "" {
+			return nil, fmt.Errorf("invalid threshold %q: missing exponent", v)
+		}
+		exp, err := strconv.Atoi(exponent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: bad exponent", v)
+		}
+		if exp > maxThresholdExponent {
+			return nil, fmt.Errorf("threshold %q exceeds the uint256 range", v)
+		}
+		if exp < -maxThresholdExponent {
+			return nil, fmt.Errorf("threshold %q is not a whole number", v)
+		}
+	}
+	if mantissa == "" || strings.ContainsAny(mantissa, "eE") {
+		return nil, fmt.Errorf("invalid threshold %q", v)
+	}
+
+	rat, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid threshold %q", v)
+	}
+	if !rat.IsInt() {
+		return nil, fmt.Errorf("threshold %q is not a whole number; increase the exponent or drop the fraction", v)
+	}
+	return new(big.Int).Set(rat.Num()), nil
+}
+
+// parsePercent parses a non-negative percentage such as "0.5" (half a percent) exactly.
+func parsePercent(v string) (*big.Rat, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	pct, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid percentage %q", v)
+	}
+	if pct.Sign() < 0 {
+		return nil, fmt.Errorf("percentage %q must not be negative", v)
+	}
+	return pct, nil
+}
+
+// parseTokenAmount parses a signed amount of whole tokens, fractions allowed, exactly.
+// Amounts beyond the uint256 range in whole tokens can never be reached by a supply and
+// are rejected, which also keeps huge exponents from building enormous intermediates.
+func parseTokenAmount(v string) (*big.Rat, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if i := strings.IndexAny(v, "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(v[i+1:]); err == nil && (exp > maxThresholdExponent || exp < -maxThresholdExponent) {
+			return nil, fmt.Errorf("token amount %q is out of range", v)
+		}
+	}
+	amount, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount %q", v)
+	}
+	if new(big.Rat).Abs(amount).Cmp(new(big.Rat).SetInt(maxUint256)) > 0 {
+		return nil, fmt.Errorf("token amount %q exceeds the uint256 range", v)
+	}
+	return amount, nil
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: ""},
+		{in: "  ", want: ""},
+		{in: "1000", want: "1000"},
+		{in: "0", want: "0"},
+		{in: "0xde0b6b3a7640000", want: "1000000000000000000"},
+		{in: "0XFF", want: "255"},
+		{in: "1.5e24", want: "1500000000000000000000000"},
+		{in: "1E3", want: "1000"},
+		{in: "2.50e1", want: "25"},
+		{in: "1e+2", want: "100"},
+		{in: "1e77", want: "1" + strings.Repeat("0", 77)},
+		{in: "1e78", wantErr: true},
+		{in: "1.5", wantErr: true},
+		{in: "1.25e1", wantErr: true},
+		{in: "1e-1", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "e5", wantErr: true},
+		{in: "0x", wantErr: true},
+		{in: "0xZZ", wantErr: true},
+		{in: "-5", wantErr: true},
+		{in: "+5", wantErr: true},
+		{in: "0x-5", wantErr: true},
+		{in: "0x+5", wantErr: true},
+		{in: "-1e3", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "0x1" + strings.Repeat("0", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseThreshold(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseThreshold(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseThreshold(%q) error: %v", tt.in, err)
+			continue
+		}
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("parseThreshold(%q) = %v, want nil", tt.in, got)
+			}
+			continue
+		}
+		want, _ := new(big.Int).SetString(tt.want, 10)
+		if got == nil || got.Cmp(want) != 0 {
+			t.Errorf("parseThreshold(%q) = %v, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// Protocol pause states derived from the per-reserve paused flags.
+const (
+	protocolActive       = "active"
+	protocolPartialPause = "partially paused"
+	protocolFullyPaused  = "paused"
+	protocolEventName    = "protocol"
+)
+
+// protocolWatcher polls the Pool for a protocol-wide pause. Aave v3 has no single global
+// flag: PoolConfigurator.setPoolPause sets the paused bit on every reserve, so the watcher
+// reads each reserve's configuration and classifies the pool as active, partially paused,
+// or fully paused, notifying on every transition.
+type protocolWatcher struct {
+	pool         common.Address
+	pollInterval time.Duration
+	state        string
+}
+
+func (p *protocolWatcher) run(ctx context.Context, client *aave.Client, d *dispatcher) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.check(ctx, client, d); err != nil && ctx.Err() == nil {
+			logger.Warnf("protocol pause check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *protocolWatcher) check(ctx context.Context, client *aave.Client, d *dispatcher) error {
+	reserves, err := client.ReservesList(ctx, p.pool)
+	if err != nil {
+		return err
+	}
+
+	var paused []string
+	for _, reserve := range reserves {
+		configuration, err := client.ReserveConfiguration(ctx, p.pool, reserve)
+		if err != nil {
+			return fmt.Errorf("reserve %s: %w", reserve.Hex(), err)
+		}
+		if aave.ReservePaused(configuration) {
+			paused = append(paused, reserve.Hex())
+		}
+	}
+
+	state := protocolActive
+	switch {
+	case len(reserves) > 0 && len(paused) == len(reserves):
+		state = protocolFullyPaused
+	case len(paused) > 0:
+		state = protocolPartialPause
+	}
+
+	previous := p.state
+	p.state = state
+	if state == previous || (previous == "" && state == protocolActive) {
+		return nil
+	}
+
+	from := previous
+	if from == "" {
+		from = "unknown (startup)"
+	}
+	logger.Infof("protocol pause state changed: %s -> %s (%d/%d reserves paused)", from, state, len(paused), len(reserves))
+
+	reasons := []string{
+		fmt.Sprintf("PRIORITY: pool state changed from %s to %s", from, state),
+		fmt.Sprintf("%d of %d reserves paused", len(paused), len(reserves)),
+	}
+	for _, reserve := range paused {
+		reasons = append(reasons, "paused reserve "+reserve)
+	}
+
+	d.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventProtocolPause,
+		AssetName:      protocolEventName,
+		AssetAddress:   p.pool.Hex(),
+		TriggerReasons: reasons,
+		Source:         notify.SourceRPC,
+		ObservedAt:     time.Now(),
+	})
+	return nil
+}
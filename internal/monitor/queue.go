@@ -0,0 +1,256 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// QueueStats describes the notification queue for metrics.
+type QueueStats struct {
+	// Depth counts events waiting for a worker; InFlight counts events being delivered.
+	Depth    int
+	InFlight int
+	// Dropped counts events discarded by the drop_oldest policy since startup.
+	Dropped uint64
+}
+
+type queuedEvent struct {
+	seq   uint64
+	event notify.SupplyChangeEvent
+}
+
+// notificationQueue decouples checks from delivery: watchers push events and a pool of
+// workers drains them through the dispatcher. When full it either blocks the pushing
+// watcher or discards the oldest waiting event. With a persist path, every waiting and
+// in-flight event is written to disk on each change so a crash does not lose them;
+// events are removed from the file only once delivery has been attempted.
+type notificationQueue struct {
+	size       int
+	workers    int
+	dropOldest bool
+	path       string
+
+	mu       sync.Mutex
+	nextSeq  uint64
+	items    []queuedEvent
+	inFlight map[uint64]notify.SupplyChangeEvent
+	dropped  atomic.Uint64
+
+	// ready and space are signalled (without blocking) when events are added and removed.
+	ready chan struct{}
+	space chan struct{}
+}
+
+// newNotificationQueue builds the queue from its configuration; a zero size disables it.
+// Events persisted by a previous run are loaded and delivered first.
+func newNotificationQueue(cfg config.QueueConfig) (*notificationQueue, error) {
+	if cfg.Size < 0 || cfg.Workers < 0 {
+		return nil, fmt.Errorf("notifications.queue size and workers must not be negative")
+	}
+	if cfg.Size == 0 {
+		if cfg.PersistPath != "" || cfg.Workers != 0 || cfg.Overflow != "" {
+			return nil, fmt.Errorf("notifications.queue requires a positive size")
+		}
+		return nil, nil
+	}
+
+	q := &notificationQueue{
+		size:     cfg.Size,
+		workers:  cfg.Workers,
+		path:     cfg.PersistPath,
+		inFlight: make(map[uint64]notify.SupplyChangeEvent),
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+	}
+	if q.workers == 0 {
+		q.workers = 1
+	}
+	switch cfg.Overflow {
+	case "", config.QueueOverflowBlock:
+	case config.QueueOverflowDropOldest:
+		q.dropOldest = true
+	default:
+		return nil, fmt.Errorf("notifications.queue overflow %q is not supported (use %s or %s)",
+			cfg.Overflow, config.QueueOverflowBlock, config.QueueOverflowDropOldest)
+	}
+
+	if q.path != "" {
+		restored, err := loadQueue(q.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range restored {
+			q.items = append(q.items, queuedEvent{seq: q.nextSeq, event: event})
+			q.nextSeq++
+		}
+		if len(restored) > 0 {
+			logger.Infof("notification queue: restored %d pending event(s) from %s", len(restored), q.path)
+			q.signal(q.ready)
+		}
+	}
+	return q, nil
+}
+
+func loadQueue(path string) ([]notify.SupplyChangeEvent, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read notification queue: %w", err)
+	}
+	var events []notify.SupplyChangeEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, fmt.Errorf("parse notification queue %s: %w", path, err)
+	}
+	return events, nil
+}
+
+func (q *notificationQueue) signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// push enqueues an event. Under the block policy it waits for room and reports false if
+// the context ends first.
+func (q *notificationQueue) push(ctx context.Context, event notify.SupplyChangeEvent) bool {
+	q.mu.Lock()
+	for len(q.items) >= q.size {
+		if q.dropOldest {
+			old := q.items[0]
+			q.items = q.items[1:]
+			q.dropped.Add(1)
+			logger.Warnf("asset %s notification queue full: dropping oldest %s event", old.event.AssetName, old.event.Type)
+			continue
+		}
+		q.mu.Unlock()
+		select {
+		case <-q.space:
+		case <-ctx.Done():
+			logger.Warnf("asset %s notification queue full at shutdown: %s event not queued", event.AssetName, event.Type)
+			return false
+		}
+		q.mu.Lock()
+	}
+
+	q.items = append(q.items, queuedEvent{seq: q.nextSeq, event: event})
+	q.nextSeq++
+	q.persistLocked()
+	if len(q.items) < q.size {
+		q.signal(q.space)
+	}
+	q.mu.Unlock()
+	q.signal(q.ready)
+	return true
+}
+
+// pop waits for the next event and marks it in flight.
+func (q *notificationQueue) pop(ctx context.Context) (queuedEvent, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			item := q.items[0]
+			q.items = q.items[1:]
+			q.inFlight[item.seq] = item.event
+			more := len(q.items) > 0
+			q.mu.Unlock()
+			q.signal(q.space)
+			if more {
+				q.signal(q.ready)
+			}
+			return item, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return queuedEvent{}, false
+		}
+	}
+}
+
+// done removes a delivered event from the in-flight set.
+func (q *notificationQueue) done(seq uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.inFlight, seq)
+	q.persistLocked()
+}
+
+// persistLocked writes in-flight then waiting events, in order, to the persist path.
+// Write errors are logged and do not stop delivery.
+func (q *notificationQueue) persistLocked() {
+	if q.path == "" {
+		return
+	}
+
+	seqs := make([]uint64, 0, len(q.inFlight))
+	for seq := range q.inFlight {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	events := make([]notify.SupplyChangeEvent, 0, len(seqs)+len(q.items))
+	for _, seq := range seqs {
+		events = append(events, q.inFlight[seq])
+	}
+	for _, item := range q.items {
+		events = append(events, item.event)
+	}
+
+	data, err := json.Marshal(events)
+	if err != nil {
+		logger.Errorf("notification queue: encode: %v", err)
+		return
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		logger.Errorf("notification queue: write %s: %v", tmp, err)
+		return
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		logger.Errorf("notification queue: replace %s: %v", q.path, err)
+	}
+}
+
+// run drains the queue through the dispatcher until the context is cancelled. An event
+// interrupted by shutdown stays in the persisted queue and is retried on the next start.
+func (q *notificationQueue) run(ctx context.Context, d *dispatcher) {
+	for {
+		item, ok := q.pop(ctx)
+		if !ok {
+			return
+		}
+		d.deliverEvent(ctx, item.event)
+		if ctx.Err() != nil {
+			return
+		}
+		q.done(item.seq)
+	}
+}
+
+func (q *notificationQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return QueueStats{Depth: len(q.items), InFlight: len(q.inFlight), Dropped: q.dropped.Load()}
+}
+
+// NotificationQueue reports the queue's current state; ok is false when it is disabled.
+func (s *Service) NotificationQueue() (stats QueueStats, ok bool) {
+	if s.dispatcher.queue == nil {
+		return QueueStats{}, false
+	}
+	return s.dispatcher.queue.stats(), true
+}
@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// criticalEventTypes are always delivered immediately, even inside quiet hours.
+var criticalEventTypes = map[notify.EventType]struct{}{
+	notify.EventTargetReached: {},
+	notify.EventProtocolPause: {},
+}
+
+// quietHours holds back non-critical events during a daily window and releases them
+// when it ends. Start and end are minutes after midnight in loc; a window whose start is
+// after its end wraps past midnight.
+type quietHours struct {
+	start, end int
+	loc        *time.Location
+	eventTypes map[notify.EventType]struct{}
+
+	mu   sync.Mutex
+	held []notify.SupplyChangeEvent
+}
+
+// newQuietHours builds the window from its configuration; nil disables it.
+func newQuietHours(cfg *config.QuietHoursConfig) (*quietHours, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("quiet_hours.start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("quiet_hours.end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("quiet_hours start and end must differ")
+	}
+	loc := time.UTC
+	if cfg.Timezone != "" {
+		loc, err = time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours.timezone: %w", err)
+		}
+	}
+
+	q := &quietHours{start: start, end: end, loc: loc, eventTypes: make(map[notify.EventType]struct{})}
+	names := cfg.EventTypes
+	if len(names) == 0 {
+		for _, t := range notify.EventTypes {
+			if _, critical := criticalEventTypes[t]; !critical {
+				q.eventTypes[t] = struct{}{}
+			}
+		}
+		return q, nil
+	}
+	for _, name := range names {
+		eventType, err := notify.ParseEventType(name)
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours.event_types: %w", err)
+		}
+		if _, critical := criticalEventTypes[eventType]; critical {
+			return nil, fmt.Errorf("quiet_hours.event_types: %s is critical and always delivered", eventType)
+		}
+		q.eventTypes[eventType] = struct{}{}
+	}
+	return q, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// active reports whether now falls inside the window.
+func (q *quietHours) active(now time.Time) bool {
+	local := now.In(q.loc)
+	m := local.Hour()*60 + local.Minute()
+	if q.start < q.end {
+		return m >= q.start && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
+
+// nextBoundary returns the first window start or end after now.
+func (q *quietHours) nextBoundary(now time.Time) time.Time {
+	local := now.In(q.loc)
+	var next time.Time
+	for day := 0; day <= 1; day++ {
+		for _, m := range []int{q.start, q.end} {
+			at := time.Date(local.Year(), local.Month(), local.Day()+day, m/60, m%60, 0, 0, q.loc)
+			if at.After(now) && (next.IsZero() || at.Before(next)) {
+				next = at
+			}
+		}
+	}
+	return next
+}
+
+// hold keeps the event for later and reports true when the window is active and the
+// event's type respects it.
+func (q *quietHours) hold(event notify.SupplyChangeEvent, now time.Time) bool {
+	if _, ok := q.eventTypes[event.Type]; !ok || !q.active(now) {
+		return false
+	}
+	q.mu.Lock()
+	q.held = append(q.held, event)
+	q.mu.Unlock()
+	logger.Debugf("asset %s %s event held for quiet hours", event.AssetName, event.Type)
+	return true
+}
+
+func (q *quietHours) take() []notify.SupplyChangeEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	held := q.held
+	q.held = nil
+	return held
+}
+
+// run releases held events through the dispatcher each time the window ends, until the
+// context is cancelled.
+func (q *quietHours) run(ctx context.Context, d *dispatcher) {
+	for {
+		timer := time.NewTimer(time.Until(q.nextBoundary(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if q.active(time.Now()) {
+			continue
+		}
+		held := q.take()
+		if len(held) > 0 {
+			logger.Infof("quiet hours ended: releasing %d held event(s)", len(held))
+		}
+		for _, event := range held {
+			d.dispatch(ctx, event)
+		}
+	}
+}
+
+// flush delivers events still held at shutdown so they are not lost, each within
+// notifyTimeout.
+func (q *quietHours) flush(d *dispatcher) {
+	held := q.take()
+	if len(held) == 0 {
+		return
+	}
+	logger.Infof("shutting down during quiet hours: delivering %d held event(s)", len(held))
+	for _, event := range held {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		d.deliverEvent(ctx, event)
+		cancel()
+	}
+}
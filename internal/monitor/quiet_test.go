@@ -0,0 +1,53 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestQuietHoursWindow(t *testing.T) {
+	q, err := newQuietHours(&config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "America/New_York"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+
+	for _, tt := range []struct {
+		at     time.Time
+		active bool
+		next   time.Time
+	}{
+		{time.Date(2024, 3, 9, 21, 59, 0, 0, ny), false, time.Date(2024, 3, 9, 22, 0, 0, 0, ny)},
+		{time.Date(2024, 3, 9, 23, 0, 0, 0, ny), true, time.Date(2024, 3, 10, 7, 0, 0, 0, ny)},
+		{time.Date(2024, 3, 10, 6, 59, 0, 0, ny), true, time.Date(2024, 3, 10, 7, 0, 0, 0, ny)},
+		{time.Date(2024, 3, 10, 7, 0, 0, 0, ny), false, time.Date(2024, 3, 10, 22, 0, 0, 0, ny)},
+	} {
+		if got := q.active(tt.at.UTC()); got != tt.active {
+			t.Errorf("active(%s) = %v, want %v", tt.at, got, tt.active)
+		}
+		if got := q.nextBoundary(tt.at.UTC()); !got.Equal(tt.next) {
+			t.Errorf("nextBoundary(%s) = %s, want %s", tt.at, got, tt.next)
+		}
+	}
+
+	night := time.Date(2024, 3, 9, 23, 0, 0, 0, ny)
+	if q.hold(notify.SupplyChangeEvent{Type: notify.EventTargetReached}, night) {
+		t.Errorf("critical target_reached event was held")
+	}
+	if !q.hold(notify.SupplyChangeEvent{Type: notify.EventSupplyIncrease}, night) {
+		t.Errorf("supply_increase event was not held")
+	}
+	if len(q.take()) != 1 {
+		t.Errorf("held events not returned")
+	}
+}
+
+func TestQuietHoursRejectsCriticalTypes(t *testing.T) {
+	_, err := newQuietHours(&config.QuietHoursConfig{Start: "22:00", End: "07:00", EventTypes: []string{"target_reached"}})
+	if err == nil {
+		t.Fatalf("expected target_reached to be rejected")
+	}
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+// alertWindow is the sliding window max_alerts_per_hour is measured over.
+const alertWindow = time.Hour
+
+// alertLimiter caps how many alerts one watcher may send within a sliding hour. When the
+// cap is hit a single "rate limit reached" event is sent and everything else is
+// suppressed until older alerts age out of the window.
+type alertLimiter struct {
+	max         int
+	sent        []time.Time
+	suppressing bool
+}
+
+// allow reports whether an alert may be sent now, recording it if so. notice is true the
+// first time an alert is refused within a suppression period.
+func (l *alertLimiter) allow(now time.Time) (ok bool, notice bool) {
+	cutoff := now.Add(-alertWindow)
+	kept := l.sent[:0]
+	for _, t := range l.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.sent = kept
+
+	if len(l.sent) < l.max {
+		l.sent = append(l.sent, now)
+		l.suppressing = false
+		return true, false
+	}
+
+	if l.suppressing {
+		return false, false
+	}
+	l.suppressing = true
+	return false, true
+}
+
+// notify dispatches an event for this watcher, enforcing max_alerts_per_hour. Watchers in
+// shadow mode or paused through the API only log what they would have sent.
+func (a *assetWatcher) notify(ctx context.Context, d *dispatcher, event notify.SupplyChangeEvent) {
+	event.Labels = a.labels
+	if event.TargetTotalSupply != nil {
+		event.TargetDirection = a.targetDirection()
+	}
+	if a.paused.Load() {
+		logger.Infof("asset %s paused: suppressing %s: %s", a.name, event.Type, strings.Join(event.TriggerReasons, "; "))
+		return
+	}
+	if a.shadow {
+		logger.Infof("asset %s shadow: would notify %s: %s", a.name, event.Type, strings.Join(event.TriggerReasons, "; "))
+		return
+	}
+	if a.limiter == nil {
+		a.counters.notified.Add(1)
+		d.dispatch(ctx, event)
+		return
+	}
+
+	ok, notice := a.limiter.allow(event.ObservedAt)
+	if ok {
+		a.counters.notified.Add(1)
+		d.dispatch(ctx, event)
+		return
+	}
+
+	logger.Infof("asset %s alert suppressed by max_alerts_per_hour (%d): %s", a.name, a.limiter.max, event.Type)
+	if !notice {
+		return
+	}
+
+	a.counters.notified.Add(1)
+	d.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventAlertRateLimited,
+		AssetName:      event.AssetName,
+		AssetAddress:   event.AssetAddress,
+		Labels:         event.Labels,
+		ExplorerURL:    event.ExplorerURL,
+		Holder:         event.Holder,
+		NewTotalSupply: event.NewTotalSupply,
+		Decimals:       event.Decimals,
+		Source:         event.Source,
+		BlockNumber:    event.BlockNumber,
+		BlockTimestamp: event.BlockTimestamp,
+		TriggerReasons: []string{
+			fmt.Sprintf("rate limit reached (%d alerts per hour), suppressing alerts for asset %s", a.limiter.max, a.name),
+		},
+		ObservedAt: event.ObservedAt,
+	})
+}
@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// rateThreshold watches one reserve rate against a configured percentage.
+type rateThreshold struct {
+	name string
+	pct  *big.Rat
+	// above records which side of the threshold the last reading was on; nil until the
+	// first reading.
+	above *bool
+}
+
+// crossed records the reading and reports whether it rose to the threshold from below.
+func (r *rateThreshold) crossed(rate *big.Rat) bool {
+	if r == nil {
+		return false
+	}
+	above := rate.Cmp(r.pct) >= 0
+	previous := r.above
+	r.above = &above
+	return previous != nil && !*previous && above
+}
+
+// checkRates reads the reserve's liquidity and variable borrow rates and fires a rate
+// threshold event when either rises to its configured percentage. Borrow rate spikes in
+// particular can precede liquidations. The first reading only records the sides.
+func (a *assetWatcher) checkRates(ctx context.Context, client *aave.Client, d *dispatcher, totalSupply *big.Int, obs observation) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	rates, err := client.Rates(ctx, *a.pool, underlying)
+	if err != nil {
+		return fmt.Errorf("fetch reserve rates: %w", err)
+	}
+
+	var reasons []string
+	for _, check := range []struct {
+		threshold *rateThreshold
+		rate      *big.Rat
+	}{
+		{a.liquidityRate, rates.LiquidityRate},
+		{a.borrowRate, rates.VariableBorrowRate},
+	} {
+		if check.threshold.crossed(check.rate) {
+			reasons = append(reasons, fmt.Sprintf("%s reached %s%% (threshold %s%%)",
+				check.threshold.name, check.rate.FloatString(2), check.threshold.pct.FloatString(2)))
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	logger.Infof("asset %s reserve rates: liquidity %s%%, variable borrow %s%%", a.name,
+		rates.LiquidityRate.FloatString(2), rates.VariableBorrowRate.FloatString(2))
+	hundred := big.NewRat(100, 1)
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:               notify.EventRateThreshold,
+		AssetName:          a.name,
+		AssetAddress:       a.address.Hex(),
+		ExplorerURL:        a.explorerLink(),
+		Holder:             a.holderHex(),
+		NewTotalSupply:     new(big.Int).Set(totalSupply),
+		TargetTotalSupply:  cloneBigInt(a.targetTotalSupply),
+		Decimals:           a.decimals,
+		Source:             notify.SourceRPC,
+		BlockNumber:        obs.blockNumber,
+		BlockTimestamp:     obs.blockTime,
+		LiquidityRate:      new(big.Rat).Quo(rates.LiquidityRate, hundred),
+		VariableBorrowRate: new(big.Rat).Quo(rates.VariableBorrowRate, hundred),
+		TriggerReasons:     reasons,
+		ObservedAt:         obs.observedAt,
+	})
+	return nil
+}
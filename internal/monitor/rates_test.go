@@ -0,0 +1,30 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"aave-cap-alerts/internal/aave"
+)
+
+func TestRateThresholdCrossing(t *testing.T) {
+	r := &rateThreshold{name: "variable borrow rate", pct: big.NewRat(10, 1)}
+	// 1e27 * 0.12 is a 12% rate in RAY.
+	twelve := new(big.Int).Mul(big.NewInt(12), new(big.Int).Exp(big.NewInt(10), big.NewInt(25), nil))
+
+	for i, step := range []struct {
+		rate  *big.Rat
+		fires bool
+	}{
+		{big.NewRat(12, 1), false}, // first reading only records the side
+		{big.NewRat(5, 1), false},
+		{aave.RayToPercent(twelve), true},
+		{big.NewRat(15, 1), false},
+		{big.NewRat(999, 100), false},
+		{big.NewRat(10, 1), true},
+	} {
+		if got := r.crossed(step.rate); got != step.fires {
+			t.Fatalf("step %d (%s%%): fired = %v, want %v", i, step.rate.FloatString(2), got, step.fires)
+		}
+	}
+}
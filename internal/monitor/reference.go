@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+)
+
+// supplyReference pins the value the percentage triggers compare against, in place of
+// the previous poll, so a slow grind still adds up to a crossing.
+type supplyReference struct {
+	mode  string
+	value *big.Int
+	// day is the UTC midnight the daily reference was taken on.
+	day time.Time
+}
+
+// newSupplyReference builds the reference from an asset's config; nil keeps the default
+// previous-poll comparison.
+func newSupplyReference(mode, value string) (*supplyReference, error) {
+	fixed, err := parseThreshold(value)
+	if err != nil {
+		return nil, fmt.Errorf("reference_value: %w", err)
+	}
+	switch mode {
+	case "":
+		if fixed != nil {
+			return nil, fmt.Errorf("reference_value requires reference: %s", config.ReferenceFixed)
+		}
+		return nil, nil
+	case config.ReferenceSessionStart, config.ReferenceDaily:
+		if fixed != nil {
+			return nil, fmt.Errorf("reference_value is only valid with reference: %s", config.ReferenceFixed)
+		}
+		return &supplyReference{mode: mode}, nil
+	case config.ReferenceFixed:
+		if fixed == nil || fixed.Sign() <= 0 {
+			return nil, fmt.Errorf("reference: %s requires a positive reference_value", config.ReferenceFixed)
+		}
+		return &supplyReference{mode: mode, value: fixed}, nil
+	default:
+		return nil, fmt.Errorf("reference %q is not supported (use %s, %s or %s)",
+			mode, config.ReferenceSessionStart, config.ReferenceDaily, config.ReferenceFixed)
+	}
+}
+
+// update seeds the reference from the first reading and, in daily mode, moves it to the
+// first reading of each new UTC day. It reports whether the reference was (re)set.
+func (r *supplyReference) update(value *big.Int, at time.Time) bool {
+	day := at.UTC().Truncate(24 * time.Hour)
+	switch r.mode {
+	case config.ReferenceSessionStart:
+		if r.value != nil {
+			return false
+		}
+	case config.ReferenceDaily:
+		if r.value != nil && !day.After(r.day) {
+			return false
+		}
+	default:
+		return false
+	}
+	r.value = new(big.Int).Set(value)
+	r.day = day
+	return true
+}
+
+// referenceValue returns the pinned reference, or nil when percentage triggers compare
+// against the previous poll.
+func (a *assetWatcher) referenceValue() *big.Int {
+	if a.reference == nil {
+		return nil
+	}
+	return a.reference.value
+}
+
+// referenceMode returns the configured reference mode, empty for the previous poll.
+func (a *assetWatcher) referenceMode() string {
+	if a.reference == nil {
+		return ""
+	}
+	return a.reference.mode
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestDailyReferenceResetsAtMidnightUTC(t *testing.T) {
+	ref, err := newSupplyReference(config.ReferenceDaily, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	if !ref.update(big.NewInt(100), day) {
+		t.Fatal("first reading did not seed the reference")
+	}
+	if ref.update(big.NewInt(150), day.Add(59*time.Minute)) {
+		t.Fatal("reference reset before midnight")
+	}
+	if !ref.update(big.NewInt(200), day.Add(time.Hour)) || ref.value.Int64() != 200 {
+		t.Fatalf("reference = %s after midnight, want 200", ref.value)
+	}
+}
+
+func TestReferenceTriggersFireOnCrossing(t *testing.T) {
+	ref, err := newSupplyReference(config.ReferenceFixed, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &assetWatcher{
+		reference:        ref,
+		notifyOnIncrease: true,
+		notifyOnDecrease: true,
+		supplyMetric:     config.SupplyMetricTotal,
+	}
+
+	steps := []struct {
+		supply int64
+		want   notify.EventType
+	}{
+		{1050, ""},
+		{1099, ""},
+		{1101, notify.EventSupplyIncrease},
+		{1200, ""},
+		{1000, ""},
+		{999, notify.EventSupplyDecrease},
+		{990, ""},
+	}
+	a.lastTotalSupply = big.NewInt(1000)
+	for _, step := range steps {
+		got, reasons := a.evaluateTriggers(big.NewInt(step.supply))
+		if got != step.want {
+			t.Fatalf("supply %d: event %q, want %q (reasons %v)", step.supply, got, step.want, reasons)
+		}
+		a.lastTotalSupply = big.NewInt(step.supply)
+	}
+}
+
+func TestSupplyReferenceConfig(t *testing.T) {
+	for _, tt := range []struct{ mode, value string }{
+		{"", "100"},
+		{config.ReferenceFixed, ""},
+		{config.ReferenceDaily, "100"},
+		{"weekly", ""},
+	} {
+		if _, err := newSupplyReference(tt.mode, tt.value); err == nil {
+			t.Errorf("reference %q value %q: expected error", tt.mode, tt.value)
+		}
+	}
+}
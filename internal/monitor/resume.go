@@ -0,0 +1,39 @@
+package monitor
+
+import (
+	"math/big"
+	"time"
+)
+
+// staleResume reports whether a restored baseline is older than resume_grace and so
+// needs a confirming read. Its age is taken from the newest saved history sample; a
+// snapshot without history is treated as stale.
+func (a *assetWatcher) staleResume(state State, now time.Time) bool {
+	if a.resumeGrace == nil || state.LastTotalSupply == nil {
+		return false
+	}
+	var last time.Time
+	for _, sample := range state.History {
+		if sample.At.After(last) {
+			last = sample.At
+		}
+	}
+	return last.IsZero() || now.Sub(last) > *a.resumeGrace
+}
+
+// holdUnconfirmedResume checks the first read after a stale baseline was restored. A
+// read that differs from the baseline is not alerted on; the check ends there and the
+// next read, which confirms the live value, is compared with the restored baseline as
+// usual. A read equal to the baseline confirms it at once.
+func (a *assetWatcher) holdUnconfirmedResume(value *big.Int) bool {
+	if !a.unconfirmedResume {
+		return false
+	}
+	a.unconfirmedResume = false
+	if value.Cmp(a.lastTotalSupply) == 0 {
+		return false
+	}
+	logger.Infof("asset %s %s %s differs from the restored baseline %s; waiting for a confirming read before alerting",
+		a.name, a.metric(), value.String(), a.lastTotalSupply.String())
+	return true
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestStaleRestoredBaselineWaitsForConfirmingRead(t *testing.T) {
+	client := newFakeChain(t, 18, big.NewInt(1000))
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	asset := common.HexToAddress("0x1")
+
+	store, err := NewFileStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := time.Now().Add(-time.Hour)
+	if err := store.Save((&assetWatcher{address: asset}).stateKey(), State{
+		LastTotalSupply: big.NewInt(800),
+		History:         []historySample{{Value: big.NewInt(800), At: saved}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	n := &fakeNotifier{name: "slack"}
+	cfg := &config.Config{
+		Snapshot: config.SnapshotConfig{Path: path},
+		Assets: []config.AssetConfig{{
+			Name:        "TEST",
+			Address:     asset.Hex(),
+			ResumeGrace: "10m",
+		}},
+	}
+	service, err := NewService(client, cfg, []notify.Notifier{n}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := service.assets[0]
+	if !a.unconfirmedResume {
+		t.Fatal("an hour-old baseline should need confirmation")
+	}
+
+	if err := a.check(context.Background(), client, service.dispatcher); err != nil {
+		t.Fatal(err)
+	}
+	if n.calls != 0 || a.lastTotalSupply.Int64() != 800 {
+		t.Fatalf("first read: %d alert(s), baseline %s; want it held against 800", n.calls, a.lastTotalSupply)
+	}
+	if err := a.check(context.Background(), client, service.dispatcher); err != nil {
+		t.Fatal(err)
+	}
+	if n.calls != 1 || a.lastTotalSupply.Int64() != 1000 {
+		t.Fatalf("confirming read: %d alert(s), baseline %s; want the 800 -> 1000 increase", n.calls, a.lastTotalSupply)
+	}
+}
+
+func TestStaleResume(t *testing.T) {
+	grace := 10 * time.Minute
+	a := &assetWatcher{resumeGrace: &grace}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	state := func(at ...time.Time) State {
+		s := State{LastTotalSupply: big.NewInt(1)}
+		for _, t := range at {
+			s.History = append(s.History, historySample{Value: big.NewInt(1), At: t})
+		}
+		return s
+	}
+	if a.staleResume(state(now.Add(-time.Hour), now.Add(-5*time.Minute)), now) {
+		t.Error("a baseline read 5m ago is within the grace")
+	}
+	if !a.staleResume(state(now.Add(-time.Hour)), now) {
+		t.Error("a baseline read an hour ago is stale")
+	}
+	if !a.staleResume(state(), now) {
+		t.Error("a baseline of unknown age is stale")
+	}
+	if (&assetWatcher{}).staleResume(state(), now) {
+		t.Error("without resume_grace every baseline is trusted")
+	}
+}
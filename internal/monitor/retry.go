@@ -0,0 +1,84 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+)
+
+// metadataRetry re-attempts reads that only need to succeed once, such as decimals or the
+// underlying asset, with a short exponential backoff. A cold or briefly failing RPC at
+// startup then recovers within seconds instead of a full poll interval later.
+type metadataRetry struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// newMetadataRetry applies metadata_retry over the defaults of 4 attempts, 500ms
+// doubling up to 5s. max_attempts: 1 disables retries.
+func newMetadataRetry(cfg config.RetryConfig) (metadataRetry, error) {
+	retry := metadataRetry{
+		maxAttempts:    4,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     5 * time.Second,
+	}
+	if cfg.MaxAttempts < 0 {
+		return retry, fmt.Errorf("metadata_retry.max_attempts must not be negative")
+	}
+	if cfg.MaxAttempts > 0 {
+		retry.maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoff != "" {
+		d, err := time.ParseDuration(cfg.InitialBackoff)
+		if err != nil {
+			return retry, fmt.Errorf("parse metadata_retry.initial_backoff: %w", err)
+		}
+		if d <= 0 {
+			return retry, fmt.Errorf("metadata_retry.initial_backoff must be positive")
+		}
+		retry.initialBackoff = d
+	}
+	if cfg.MaxBackoff != "" {
+		d, err := time.ParseDuration(cfg.MaxBackoff)
+		if err != nil {
+			return retry, fmt.Errorf("parse metadata_retry.max_backoff: %w", err)
+		}
+		if d <= 0 {
+			return retry, fmt.Errorf("metadata_retry.max_backoff must be positive")
+		}
+		retry.maxBackoff = d
+	}
+	if retry.maxBackoff < retry.initialBackoff {
+		retry.maxBackoff = retry.initialBackoff
+	}
+	return retry, nil
+}
+
+// do calls fn until it succeeds, the attempts run out, or the context ends. Errors that
+// retrying cannot fix, such as an address without code, are returned immediately.
+func (r metadataRetry) do(ctx context.Context, what string, fn func() error) error {
+	backoff := r.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.maxAttempts || ctx.Err() != nil || permanent(err) {
+			return err
+		}
+
+		logger.Warnf("%s failed (attempt %d/%d): %v; retrying in %s", what, attempt, r.maxAttempts, err, backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, r.maxBackoff)
+	}
+}
+
+func permanent(err error) bool {
+	return errors.Is(err, aave.ErrNoContractData) || errors.Is(err, aave.ErrUnexpectedResult)
+}
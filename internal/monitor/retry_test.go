@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/aave"
+)
+
+func TestMetadataRetryRecoversFromTransientErrors(t *testing.T) {
+	retry := metadataRetry{maxAttempts: 4, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+
+	calls := 0
+	err := retry.do(context.Background(), "decimals read", func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err = %v after %d calls, want success on the third", err, calls)
+	}
+
+	calls = 0
+	err = retry.do(context.Background(), "decimals read", func() error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if err == nil || calls != 4 {
+		t.Fatalf("err = %v after %d calls, want failure after 4", err, calls)
+	}
+}
+
+func TestMetadataRetryStopsOnPermanentErrors(t *testing.T) {
+	retry := metadataRetry{maxAttempts: 4, initialBackoff: time.Millisecond, maxBackoff: time.Millisecond}
+
+	calls := 0
+	err := retry.do(context.Background(), "symbol read", func() error {
+		calls++
+		return fmt.Errorf("unpack symbol: %w", aave.ErrUnexpectedResult)
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("err = %v after %d calls, want one attempt", err, calls)
+	}
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// dueItem is a watcher waiting in the scheduler queue.
+type dueItem struct {
+	watcher  *assetWatcher
+	due      time.Time
+	position int
+	started  bool
+}
+
+// dueQueue is a min-heap of watchers ordered by their next check time.
+type dueQueue []*dueItem
+
+func (q dueQueue) Len() int           { return len(q) }
+func (q dueQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+func (q dueQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *dueQueue) Push(x any)        { *q = append(*q, x.(*dueItem)) }
+func (q *dueQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// runSharded checks every watcher from a fixed pool of workers fed by a queue ordered by
+// next check time, instead of one goroutine and ticker per asset. Each watcher is in at
+// most one place at a time (queued or being checked), so its state needs no locking, and
+// its poll interval is kept by rescheduling it relative to when its check finished.
+func (s *Service) runSharded(ctx context.Context, workers int) {
+	now := time.Now()
+	queue := make(dueQueue, 0, len(s.assets))
+	for i, a := range s.assets {
+		due := now
+		if s.startup != nil {
+			due = now.Add(time.Duration(i) * s.startup.stagger)
+		}
+		item := &dueItem{watcher: a, due: due, position: i}
+		if s.strictStartup {
+			item.due = now.Add(a.pollInterval)
+			item.started = true
+		}
+		queue = append(queue, item)
+	}
+	heap.Init(&queue)
+
+	jobs := make(chan *dueItem)
+	done := make(chan *dueItem, len(s.assets))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				s.checkScheduled(ctx, item)
+				done <- item
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(jobs)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var next *dueItem
+		var send chan *dueItem
+		if queue.Len() > 0 {
+			next = queue[0]
+			if wait := time.Until(next.due); wait > 0 {
+				timer.Reset(wait)
+			} else {
+				send = jobs
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case send <- next:
+			heap.Pop(&queue)
+		case item := <-done:
+			heap.Push(&queue, item)
+		case <-timer.C:
+		}
+	}
+}
+
+// checkScheduled runs one check for a queued watcher and sets its next due time.
+func (s *Service) checkScheduled(ctx context.Context, item *dueItem) {
+	a := item.watcher
+	label := "check"
+	if !item.started {
+		label = "initial check"
+		release, ok := s.startup.acquire(ctx, 0)
+		if !ok {
+			return
+		}
+		defer release()
+		item.started = true
+	}
+
+	started := time.Now()
+	err := a.check(ctx, s.client, s.dispatcher)
+	if err != nil {
+		logger.Warnf("asset %s %s failed: %v", a.name, label, err)
+	}
+	a.recordCheck(started, err)
+
+	item.due = time.Now().Add(a.pollInterval)
+	if a.pending != nil {
+		if flush := a.pending.since.Add(a.coalesceWindow); flush.Before(item.due) {
+			item.due = flush
+		}
+	}
+}
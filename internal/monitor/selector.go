@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// labelRequirement is one term of a selector: the label must equal value, or differ from
+// it when negated. A missing label differs from every value.
+type labelRequirement struct {
+	key     string
+	value   string
+	negated bool
+}
+
+// labelSelector matches an asset's labels when every requirement holds. The empty
+// selector matches everything.
+type labelSelector []labelRequirement
+
+// parseSelector parses a comma-separated list of key=value and key!=value terms, such as
+// "risk_tier=high,team=stablecoins".
+func parseSelector(expr string) (labelSelector, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	var selector labelSelector
+	for _, term := range strings.Split(expr, ",") {
+		term = strings.TrimSpace(term)
+		req := labelRequirement{}
+		key, value, ok := strings.Cut(term, "!=")
+		if ok {
+			req.negated = true
+		} else if key, value, ok = strings.Cut(term, "="); !ok {
+			return nil, fmt.Errorf("selector term %q must be key=value or key!=value", term)
+		}
+		req.key = strings.TrimSpace(key)
+		req.value = strings.TrimSpace(value)
+		if req.key == "" {
+			return nil, fmt.Errorf("selector term %q has an empty label name", term)
+		}
+		selector = append(selector, req)
+	}
+	return selector, nil
+}
+
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, ok := labels[req.key]
+		if (ok && value == req.value) == req.negated {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import "testing"
+
+func TestLabelSelector(t *testing.T) {
+	stablecoinHigh := map[string]string{"risk_tier": "high", "team": "stablecoins"}
+	stablecoinLow := map[string]string{"risk_tier": "low", "team": "stablecoins"}
+	unlabelled := map[string]string{}
+
+	cases := []struct {
+		expr   string
+		labels map[string]string
+		want   bool
+	}{
+		{"", unlabelled, true},
+		{"risk_tier=high,team=stablecoins", stablecoinHigh, true},
+		{"risk_tier=high, team=stablecoins", stablecoinLow, false},
+		{"team=stablecoins", stablecoinLow, true},
+		{"team=stablecoins", unlabelled, false},
+		{"risk_tier!=low", stablecoinHigh, true},
+		{"risk_tier!=low", stablecoinLow, false},
+		{"risk_tier!=low", unlabelled, true},
+		{"team=stablecoins,risk_tier!=high", stablecoinLow, true},
+	}
+	for _, tc := range cases {
+		selector, err := parseSelector(tc.expr)
+		if err != nil {
+			t.Fatalf("parseSelector(%q): %v", tc.expr, err)
+		}
+		if got := selector.matches(tc.labels); got != tc.want {
+			t.Errorf("%q matches %v = %v, want %v", tc.expr, tc.labels, got, tc.want)
+		}
+	}
+}
+
+func TestParseSelectorRejectsMalformedTerms(t *testing.T) {
+	for _, expr := range []string{"risk_tier", "=high", "team=stablecoins,"} {
+		if _, err := parseSelector(expr); err == nil {
+			t.Errorf("parseSelector(%q) succeeded, want an error", expr)
+		}
+	}
+}
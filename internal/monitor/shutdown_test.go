@@ -0,0 +1,223 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// newFakeChain serves the eth_call reads a total_supply watcher makes: decimals() and
+// totalSupply(), singly or in JSON-RPC batches. Every other method fails, which the
+// monitor treats as best effort.
+func newFakeChain(t *testing.T, decimals uint8, supply *big.Int) *aave.Client {
+	t.Helper()
+	return newFakeChainServer(t, fakeChainHandler(decimals, supply, true))
+}
+
+func newFakeChainServer(t *testing.T, handler http.HandlerFunc) *aave.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	backend, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("dial fake chain: %v", err)
+	}
+	t.Cleanup(backend.Close)
+
+	client, err := aave.NewClient(backend)
+	if err != nil {
+		t.Fatalf("build client: %v", err)
+	}
+	return client
+}
+
+type fakeRPCRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// fakeChainHandler answers decimals() and totalSupply() calls; with batches false it
+// rejects batch requests the way some providers do.
+func fakeChainHandler(decimals uint8, supply *big.Int, batches bool) http.HandlerFunc {
+	answer := func(req fakeRPCRequest) string {
+		var result string
+		if req.Method == "eth_call" && len(req.Params) > 0 {
+			var call struct {
+				Input string `json:"input"`
+			}
+			_ = json.Unmarshal(req.Params[0], &call)
+			switch {
+			case strings.HasPrefix(call.Input, "0x313ce567"):
+				result = fmt.Sprintf("0x%064x", decimals)
+			case strings.HasPrefix(call.Input, "0x18160ddd"):
+				result = fmt.Sprintf("0x%064x", supply)
+			}
+		}
+		if result == "" {
+			return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"unsupported"}}`, req.ID)
+		}
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%q}`, req.ID, result)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+			if !batches {
+				fmt.Fprint(w, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch requests are not supported"}}`)
+				return
+			}
+			var reqs []fakeRPCRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			answers := make([]string, len(reqs))
+			for i, req := range reqs {
+				answers[i] = answer(req)
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(answers, ","))
+			return
+		}
+
+		var req fakeRPCRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, answer(req))
+	}
+}
+
+func TestRunAbortsInFlightNotificationOnShutdown(t *testing.T) {
+	client := newFakeChain(t, 18, big.NewInt(1000))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Drain the body so the server notices the client going away.
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	notifier := notify.NewJSONRPCNotifier(slow.URL, notify.JSONRPCFormatFlat, "", "", nil)
+	cfg := &config.Config{
+		Assets: []config.AssetConfig{{
+			Name:    "TEST",
+			Address: common.HexToAddress("0x1").Hex(),
+		}},
+	}
+	service, err := NewService(client, cfg, []notify.Notifier{notifier}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	// Seed a baseline so the first check reports an increase and notifies.
+	service.assets[0].lastTotalSupply = big.NewInt(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- service.Run(ctx) }()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was never sent")
+	}
+
+	cancel()
+	cancelled := time.Now()
+	select {
+	case <-done:
+		if elapsed := time.Since(cancelled); elapsed > time.Second {
+			t.Errorf("Run took %s to return after cancellation", elapsed)
+		}
+	case <-time.After(notifyTimeout):
+		t.Fatal("Run did not return before the notifier timeout; the in-flight send was not aborted")
+	}
+}
+
+// recordingNotifier passes every event it receives to events.
+type recordingNotifier struct {
+	events chan notify.SupplyChangeEvent
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(_ context.Context, event notify.SupplyChangeEvent) error {
+	r.events <- event
+	return nil
+}
+
+func TestRunAnnouncesStartupOnce(t *testing.T) {
+	client := newFakeChain(t, 18, big.NewInt(1000))
+	recorder := &recordingNotifier{events: make(chan notify.SupplyChangeEvent, 8)}
+	cfg := &config.Config{
+		Assets: []config.AssetConfig{
+			{Name: "USDC", Address: common.HexToAddress("0x1").Hex()},
+			{Name: "DAI", Address: common.HexToAddress("0x2").Hex()},
+		},
+		StrictStartup: true,
+		Notifications: config.Notifications{NotifyOnStartup: true},
+	}
+	service, err := NewService(client, cfg, []notify.Notifier{recorder}, 30*time.Second)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- service.Run(ctx) }()
+
+	select {
+	case event := <-recorder.events:
+		if event.Type != notify.EventMonitoringStarted {
+			t.Fatalf("first event = %s, want %s", event.Type, notify.EventMonitoringStarted)
+		}
+		want := []string{"monitoring started: 2 asset(s) across 1 chain(s), poll interval 30s", "assets: USDC, DAI"}
+		if strings.Join(event.TriggerReasons, "\n") != strings.Join(want, "\n") {
+			t.Errorf("reasons = %q, want %q", event.TriggerReasons, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("startup was never announced")
+	}
+
+	cancel()
+	<-done
+	for len(recorder.events) > 0 {
+		if event := <-recorder.events; event.Type == notify.EventMonitoringStarted {
+			t.Error("startup announced more than once")
+		}
+	}
+}
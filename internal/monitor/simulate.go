@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// SimulationResult compares an asset's tracked value with and without state overrides
+// and reports which triggers the overridden value would fire.
+type SimulationResult struct {
+	Asset     string
+	Metric    string
+	Current   *big.Int
+	Simulated *big.Int
+	// EventType and Reasons are empty when no trigger would fire.
+	EventType notify.EventType
+	Reasons   []string
+}
+
+// Simulate reads every asset twice, once as-is and once through eth_call with the
+// given state overrides, and evaluates the triggers as if the overridden value were the
+// next reading. No notifications are sent and the subgraph is not consulted; the
+// service should not be run afterwards.
+func (s *Service) Simulate(ctx context.Context, overrides aave.StateOverrides) ([]SimulationResult, error) {
+	simulated := s.client.WithStateOverrides(overrides)
+	results := make([]SimulationResult, 0, len(s.assets))
+	for _, a := range s.assets {
+		decimals, err := s.client.Decimals(ctx, a.address)
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: fetch decimals: %w", a.name, err)
+		}
+		a.setTokenDecimals(decimals)
+		if a.capSource != nil {
+			if err := a.refreshSupplyCap(ctx, s.client); err != nil {
+				return nil, fmt.Errorf("asset %s: %w", a.name, err)
+			}
+		}
+		if a.capURL != nil {
+			a.refreshCapURL(ctx, time.Now())
+		}
+
+		current, _, _, err := a.readTracked(ctx, s.client, &graphFallback{})
+		if err != nil {
+			return nil, fmt.Errorf("asset %s: %w", a.name, err)
+		}
+		value, _, _, err := a.readTracked(ctx, simulated, &graphFallback{})
+		if err != nil {
+			return nil, fmt.Errorf("asset %s with overrides: %w", a.name, err)
+		}
+
+		a.lastTotalSupply = current
+		eventType, reasons := a.evaluateTriggers(value)
+		results = append(results, SimulationResult{
+			Asset:     a.name,
+			Metric:    a.metric(),
+			Current:   current,
+			Simulated: value,
+			EventType: eventType,
+			Reasons:   reasons,
+		})
+	}
+	return results, nil
+}
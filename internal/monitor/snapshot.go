@@ -0,0 +1,343 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+)
+
+// defaultSnapshotInterval is how often the full state snapshot is written when
+// snapshot.interval is unset.
+const defaultSnapshotInterval = 5 * time.Minute
+
+// SnapshotSection is state kept outside the watchers, such as open incidents, that is
+// saved in and restored from the state snapshot under its own name.
+type SnapshotSection interface {
+	SnapshotState() (json.RawMessage, error)
+	RestoreState(data json.RawMessage) error
+}
+
+// stateSnapshot is the snapshot file: per-watcher state keyed by stateKey, plus named
+// sections.
+type stateSnapshot struct {
+	WrittenAt time.Time                  `json:"written_at"`
+	Assets    map[string]State           `json:"assets"`
+	Sections  map[string]json.RawMessage `json:"sections,omitempty"`
+}
+
+// State holds the watcher state a restart would otherwise lose.
+type State struct {
+	LastTotalSupply *big.Int  `json:"last_total_supply,omitempty"`
+	ATH             *big.Int  `json:"ath,omitempty"`
+	ATHAt           time.Time `json:"ath_at,omitempty"`
+	CapETAWarned    bool      `json:"cap_eta_warned,omitempty"`
+	// Levels records which side of each alert level the last reading was on, keyed by
+	// the level in tokens.
+	Levels map[string]bool `json:"levels,omitempty"`
+	// CapLevels records which cap levels the last reading had reached, keyed by level_pct.
+	CapLevels          map[string]bool `json:"cap_levels,omitempty"`
+	DebtCeilingAbove   *bool           `json:"debt_ceiling_above,omitempty"`
+	LiquidityRateAbove *bool           `json:"liquidity_rate_above,omitempty"`
+	BorrowRateAbove    *bool           `json:"borrow_rate_above,omitempty"`
+	LastIndex          *big.Int        `json:"last_liquidity_index,omitempty"`
+	LastAccrued        *big.Int        `json:"last_accrued_to_treasury,omitempty"`
+	Reference          *big.Int        `json:"reference,omitempty"`
+	ReferenceDay       time.Time       `json:"reference_day,omitempty"`
+	History            []historySample `json:"history,omitempty"`
+}
+
+type historySample struct {
+	Value *big.Int  `json:"value"`
+	At    time.Time `json:"at"`
+}
+
+// snapshotter periodically saves every watcher's published state, and the registered
+// sections, to the state store.
+type snapshotter struct {
+	store    StateStore
+	interval time.Duration
+	// leading, when set, reports whether this replica is the HA leader; only the leader
+	// saves, so a standby does not overwrite the state the leader reported from.
+	leading func() bool
+
+	mu       sync.Mutex
+	sections map[string]SnapshotSection
+}
+
+// newSnapshotter builds the snapshotter from its configuration; it returns nil when no
+// backend is configured.
+func newSnapshotter(cfg config.SnapshotConfig) (*snapshotter, error) {
+	store, err := newStateStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		if cfg.Interval != "" {
+			return nil, fmt.Errorf("snapshot.interval requires snapshot.path or snapshot.backend")
+		}
+		return nil, nil
+	}
+	s := &snapshotter{store: store, interval: defaultSnapshotInterval, sections: make(map[string]SnapshotSection)}
+	if cfg.Interval != "" {
+		interval, err := time.ParseDuration(cfg.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("parse snapshot.interval: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("snapshot.interval must be positive")
+		}
+		s.interval = interval
+	}
+	return s, nil
+}
+
+// snapshotBox guards the state a watcher publishes after each check, like statusBox.
+type snapshotBox struct {
+	mu    sync.Mutex
+	state State
+	// resume is state loaded for a new HA leader, applied at the watcher's next check.
+	resume *State
+}
+
+func (b *snapshotBox) load() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *snapshotBox) store(state State) {
+	b.mu.Lock()
+	b.state = state
+	b.mu.Unlock()
+}
+
+// publishSnapshot copies the watcher's restorable state for the snapshotter. It must be
+// called from the watcher goroutine.
+func (a *assetWatcher) publishSnapshot() {
+	if a.snapshot == nil {
+		return
+	}
+	state := State{
+		LastTotalSupply:  cloneBigInt(a.lastTotalSupply),
+		CapETAWarned:     a.capETAWarned,
+		DebtCeilingAbove: cloneBool(a.debtCeilingAbove),
+		LastIndex:        cloneBigInt(a.lastIndex),
+		LastAccrued:      cloneBigInt(a.lastAccrued),
+	}
+	if a.ath != nil {
+		state.ATH = new(big.Int).Set(a.ath.value)
+		state.ATHAt = a.ath.at
+	}
+	state.Levels, state.CapLevels = a.armedLevels()
+	if a.liquidityRate != nil {
+		state.LiquidityRateAbove = cloneBool(a.liquidityRate.above)
+	}
+	if a.borrowRate != nil {
+		state.BorrowRateAbove = cloneBool(a.borrowRate.above)
+	}
+	if a.reference != nil && a.reference.mode != config.ReferenceFixed && a.reference.value != nil {
+		state.Reference = new(big.Int).Set(a.reference.value)
+		state.ReferenceDay = a.reference.day
+	}
+	start := (a.history.next - a.history.count + historySize) % historySize
+	for i := 0; i < a.history.count; i++ {
+		j := (start + i) % historySize
+		state.History = append(state.History, historySample{Value: new(big.Int).Set(a.history.samples[j]), At: a.history.times[j]})
+	}
+	a.snapshot.store(state)
+}
+
+// armedLevels returns which side of each alert level, keyed by the level in tokens, and
+// which cap levels, keyed by level_pct, the last reading was on. Levels not yet read are
+// left out; either map is nil when empty.
+func (a *assetWatcher) armedLevels() (levels, capLevels map[string]bool) {
+	for _, level := range a.levels {
+		if level.above == nil {
+			continue
+		}
+		if levels == nil {
+			levels = make(map[string]bool, len(a.levels))
+		}
+		levels[level.tokens.RatString()] = *level.above
+	}
+	for _, level := range a.capLevels {
+		if level.reached == nil {
+			continue
+		}
+		if capLevels == nil {
+			capLevels = make(map[string]bool, len(a.capLevels))
+		}
+		capLevels[level.pct.RatString()] = *level.reached
+	}
+	return levels, capLevels
+}
+
+// restoreSnapshot applies a saved state to a watcher that has not started yet. A
+// persisted all-time high from state_path is kept when it is higher.
+func (a *assetWatcher) restoreSnapshot(state State) {
+	a.lastTotalSupply = cloneBigInt(state.LastTotalSupply)
+	if state.ATH != nil && (a.ath == nil || state.ATH.Cmp(a.ath.value) > 0) {
+		a.ath = &allTimeHigh{value: new(big.Int).Set(state.ATH), at: state.ATHAt}
+	}
+	a.capETAWarned = state.CapETAWarned
+	for _, level := range a.levels {
+		if above, ok := state.Levels[level.tokens.RatString()]; ok {
+			level.above = &above
+		}
+	}
+	for _, level := range a.capLevels {
+		if reached, ok := state.CapLevels[level.pct.RatString()]; ok {
+			level.reached = &reached
+		}
+	}
+	a.debtCeilingAbove = cloneBool(state.DebtCeilingAbove)
+	if a.liquidityRate != nil {
+		a.liquidityRate.above = cloneBool(state.LiquidityRateAbove)
+	}
+	if a.borrowRate != nil {
+		a.borrowRate.above = cloneBool(state.BorrowRateAbove)
+	}
+	a.lastIndex = cloneBigInt(state.LastIndex)
+	a.lastAccrued = cloneBigInt(state.LastAccrued)
+	if a.reference != nil && a.reference.mode != config.ReferenceFixed && state.Reference != nil {
+		a.reference.value = new(big.Int).Set(state.Reference)
+		a.reference.day = state.ReferenceDay
+	}
+	for _, sample := range state.History {
+		if sample.Value != nil {
+			a.history.add(sample.Value, sample.At)
+		}
+	}
+	a.unconfirmedResume = a.staleResume(state, time.Now())
+	a.publishSnapshot()
+}
+
+func cloneBool(v *bool) *bool {
+	if v == nil {
+		return nil
+	}
+	b := *v
+	return &b
+}
+
+// restore loads each watcher's saved state from the store. Watchers with none start
+// fresh; any other load error fails startup.
+func (s *snapshotter) restore(watchers []*assetWatcher) error {
+	restored := 0
+	for _, a := range watchers {
+		a.snapshot = &snapshotBox{}
+		state, err := s.store.Load(a.stateKey())
+		if errors.Is(err, ErrNoState) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("restore asset %s from snapshot: %w", a.name, err)
+		}
+		a.restoreSnapshot(state)
+		restored++
+	}
+	logger.Infof("state snapshot: restored %d of %d asset(s) from %v", restored, len(watchers), s.store)
+	return nil
+}
+
+// SetStateStore replaces the configured snapshot backend with store and restores every
+// watcher from it, keeping the configured interval or the default. It must be called
+// before Run and before AddSnapshotSection.
+func (s *Service) SetStateStore(store StateStore) error {
+	if s.snapshot == nil {
+		s.snapshot = &snapshotter{interval: defaultSnapshotInterval, sections: make(map[string]SnapshotSection)}
+		if s.elector != nil {
+			s.snapshot.leading = s.elector.IsLeader
+		}
+	}
+	s.snapshot.store = store
+	return s.snapshot.restore(s.assets)
+}
+
+// AddSnapshotSection includes section in the state snapshot under name and restores it
+// from the state store. It must be called before Run and does nothing when snapshots
+// are disabled or the store cannot keep sections.
+func (s *Service) AddSnapshotSection(name string, section SnapshotSection) error {
+	if s.snapshot == nil {
+		return nil
+	}
+	sections, ok := s.snapshot.store.(SectionStore)
+	if !ok {
+		logger.Warnf("state snapshot: %v cannot keep sections; %s will not be saved", s.snapshot.store, name)
+		return nil
+	}
+	s.snapshot.mu.Lock()
+	s.snapshot.sections[name] = section
+	s.snapshot.mu.Unlock()
+	return restoreSection(sections, name, section)
+}
+
+// restoreSection loads the section saved under name into section. A section that was
+// never saved is left as it is.
+func restoreSection(sections SectionStore, name string, section SnapshotSection) error {
+	data, err := sections.LoadSection(name)
+	if errors.Is(err, ErrNoState) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load %s from snapshot: %w", name, err)
+	}
+	if err := section.RestoreState(data); err != nil {
+		return fmt.Errorf("restore %s from snapshot: %w", name, err)
+	}
+	return nil
+}
+
+// run writes the snapshot every interval until the context is cancelled. The final
+// snapshot is written by Run once the watchers have stopped.
+func (s *snapshotter) run(ctx context.Context, watchers []*assetWatcher) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.write(watchers)
+		}
+	}
+}
+
+// write saves every watcher's state and the sections to the store, then flushes a
+// buffering store once, unless this replica is on standby. Errors are logged so an
+// unavailable backend does not stop monitoring.
+func (s *snapshotter) write(watchers []*assetWatcher) {
+	if s.leading != nil && !s.leading() {
+		return
+	}
+	for _, a := range watchers {
+		if err := s.store.Save(a.stateKey(), a.snapshot.load()); err != nil {
+			logger.Errorf("state snapshot: save asset %s: %v", a.name, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sections, _ := s.store.(SectionStore)
+	for name, section := range s.sections {
+		data, err := section.SnapshotState()
+		if err != nil {
+			logger.Errorf("state snapshot: %s: %v", name, err)
+			continue
+		}
+		if err := sections.SaveSection(name, data); err != nil {
+			logger.Errorf("state snapshot: save %s: %v", name, err)
+		}
+	}
+	if flusher, ok := s.store.(StateFlusher); ok {
+		if err := flusher.Flush(); err != nil {
+			logger.Errorf("state snapshot: write %v: %v", s.store, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/kv"
+)
+
+type jsonSection struct{ state json.RawMessage }
+
+func (s *jsonSection) SnapshotState() (json.RawMessage, error) { return s.state, nil }
+
+func (s *jsonSection) RestoreState(data json.RawMessage) error {
+	s.state = data
+	return nil
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	newWatcher := func() *assetWatcher {
+		return &assetWatcher{
+			name:       "USDC",
+			address:    common.HexToAddress("0x1"),
+			levels:     []*alertLevel{{tokens: big.NewRat(5, 2)}},
+			borrowRate: &rateThreshold{name: "variable borrow rate", pct: big.NewRat(10, 1)},
+		}
+	}
+
+	snap, err := newSnapshotter(config.SnapshotConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := newWatcher()
+	if err := snap.restore([]*assetWatcher{before}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	before.history.add(big.NewInt(90), at)
+	before.history.add(big.NewInt(100), at.Add(time.Minute))
+	before.lastTotalSupply = big.NewInt(100)
+	before.ath = &allTimeHigh{value: big.NewInt(120), at: at}
+	before.capETAWarned = true
+	above := true
+	before.levels[0].above = &above
+	before.borrowRate.above = &above
+	before.publishSnapshot()
+	snap.sections["incidents"] = &jsonSection{state: json.RawMessage(`{"k":1}`)}
+	snap.write([]*assetWatcher{before})
+
+	restored, err := newSnapshotter(config.SnapshotConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := newWatcher()
+	if err := restored.restore([]*assetWatcher{after}); err != nil {
+		t.Fatal(err)
+	}
+	if after.lastTotalSupply.Int64() != 100 || after.ath.value.Int64() != 120 || !after.capETAWarned {
+		t.Fatalf("restored baseline %v, ATH %v, cap ETA warned %v", after.lastTotalSupply, after.ath.value, after.capETAWarned)
+	}
+	if after.levels[0].above == nil || !*after.levels[0].above || after.borrowRate.above == nil || !*after.borrowRate.above {
+		t.Fatalf("armed flags not restored")
+	}
+	if got := after.history.values(); len(got) != 2 || got[1].Int64() != 100 {
+		t.Fatalf("history = %v", got)
+	}
+
+	service := &Service{snapshot: restored}
+	section := &jsonSection{}
+	if err := service.AddSnapshotSection("incidents", section); err != nil {
+		t.Fatal(err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, section.state); err != nil || compact.String() != `{"k":1}` {
+		t.Fatalf("section restored as %s", section.state)
+	}
+}
+
+func TestFileStateStoreWritesOnFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	store, err := NewFileStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("0x1", State{LastTotalSupply: big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("0x2", State{LastTotalSupply: big.NewInt(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("file written before Flush: %v", err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewFileStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]int64{"0x1": 1, "0x2": 2} {
+		if state, err := reopened.Load(key); err != nil || state.LastTotalSupply.Int64() != want {
+			t.Errorf("%s = %+v, %v; want %d", key, state, err, want)
+		}
+	}
+}
+
+// memoryKV is an in-memory kv.Store.
+type memoryKV map[string][]byte
+
+func (m memoryKV) Get(_ context.Context, key string) ([]byte, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, kv.ErrNotFound
+	}
+	return v, nil
+}
+
+func (m memoryKV) String() string { return "memory" }
+
+func (m memoryKV) Put(_ context.Context, key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func TestKVStateStoreKeepsAssetsAndSections(t *testing.T) {
+	backend := memoryKV{}
+	store := &kvStateStore{store: backend, prefix: "test:"}
+	if _, err := store.Load("0x1"); !errors.Is(err, ErrNoState) {
+		t.Fatalf("empty store: %v, want ErrNoState", err)
+	}
+	if err := store.Save("0x1", State{LastTotalSupply: big.NewInt(42), CapETAWarned: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSection("incidents", json.RawMessage(`{"k":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := backend["test:asset/0x1"]; !ok {
+		t.Fatalf("keys = %v", backend)
+	}
+	state, err := store.Load("0x1")
+	if err != nil || state.LastTotalSupply.Int64() != 42 || !state.CapETAWarned {
+		t.Fatalf("loaded %+v, %v", state, err)
+	}
+	if data, err := store.LoadSection("incidents"); err != nil || string(data) != `{"k":1}` {
+		t.Fatalf("section = %s, %v", data, err)
+	}
+}
+
+func TestNewStateStoreValidatesBackend(t *testing.T) {
+	for _, cfg := range []config.SnapshotConfig{
+		{Backend: config.SnapshotBackendFile},
+		{Backend: config.SnapshotBackendRedis},
+		{Backend: config.SnapshotBackendRedis, Path: "snapshot.json", Redis: &config.SnapshotRedisConfig{URL: "redis://localhost"}},
+		{Path: "snapshot.json", Redis: &config.SnapshotRedisConfig{URL: "redis://localhost"}},
+		{Backend: config.SnapshotBackendS3},
+		{Backend: "etcd"},
+	} {
+		if _, err := newStateStore(cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+	if store, err := newStateStore(config.SnapshotConfig{}); store != nil || err != nil {
+		t.Errorf("unset = %v, %v; want disabled", store, err)
+	}
+	store, err := newStateStore(config.SnapshotConfig{Backend: config.SnapshotBackendRedis, Redis: &config.SnapshotRedisConfig{URL: "redis://localhost"}})
+	if err != nil || store.(*kvStateStore).prefix != defaultRedisKeyPrefix {
+		t.Errorf("redis = %v, %v", store, err)
+	}
+}
+
+func TestStatePathRejectedWithSharedBackend(t *testing.T) {
+	cfg := &config.Config{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Snapshot:  config.SnapshotConfig{Backend: config.SnapshotBackendRedis, Redis: &config.SnapshotRedisConfig{URL: "redis://localhost"}},
+		Assets:    []config.AssetConfig{{Name: "TEST", Address: common.HexToAddress("0x1").Hex()}},
+	}
+	if _, err := NewService(newFakeChain(t, 18, big.NewInt(1000)), cfg, nil, time.Minute); err == nil {
+		t.Fatal("state_path with a redis snapshot backend should fail")
+	}
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// startupGate bounds how many initial checks run at once and optionally staggers their
+// start, smoothing the boot burst when many assets are configured. A nil gate admits
+// everything immediately.
+type startupGate struct {
+	slots   chan struct{}
+	stagger time.Duration
+}
+
+func newStartupGate(concurrency int, stagger time.Duration) *startupGate {
+	if concurrency <= 0 && stagger <= 0 {
+		return nil
+	}
+	g := &startupGate{stagger: stagger}
+	if concurrency > 0 {
+		g.slots = make(chan struct{}, concurrency)
+	}
+	return g
+}
+
+// acquire waits for the watcher's stagger offset and a free slot. It returns a release
+// function, or false if the context ended first.
+func (g *startupGate) acquire(ctx context.Context, position int) (func(), bool) {
+	if g == nil {
+		return func() {}, true
+	}
+
+	if g.stagger > 0 && position > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(time.Duration(position) * g.stagger):
+		}
+	}
+
+	if g.slots == nil {
+		return func() {}, true
+	}
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case g.slots <- struct{}{}:
+		return func() { <-g.slots }, true
+	}
+}
+
+// strictInitialChecks runs every watcher's first check before monitoring starts and
+// returns the joined errors of any that failed, so a misconfigured deployment stops
+// instead of retrying forever. The startup gate still bounds concurrency and stagger.
+func (s *Service) strictInitialChecks(ctx context.Context) error {
+	errs := make([]error, len(s.assets))
+	var wg sync.WaitGroup
+	for i, a := range s.assets {
+		wg.Add(1)
+		go func(i int, a *assetWatcher) {
+			defer wg.Done()
+			release, ok := s.startup.acquire(ctx, i)
+			if !ok {
+				errs[i] = ctx.Err()
+				return
+			}
+			started := time.Now()
+			err := a.check(ctx, s.client, s.dispatcher)
+			release()
+			a.recordCheck(started, err)
+			if err != nil {
+				logger.Warnf("asset %s initial check failed: %v", a.name, err)
+				errs[i] = fmt.Errorf("asset %s: %w", a.name, err)
+			}
+		}(i, a)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
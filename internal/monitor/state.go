@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// athStore persists per-watcher state that should survive restarts, currently the
+// all-time high, as a JSON file keyed by asset (and holder) address.
+type athStore struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]watcherState
+}
+
+type watcherState struct {
+	ATH   *big.Int  `json:"ath,omitempty"`
+	ATHAt time.Time `json:"ath_at,omitempty"`
+}
+
+// loadATHStore reads the state file; a missing file starts empty.
+func loadATHStore(path string) (*athStore, error) {
+	s := &athStore{path: path, entries: make(map[string]watcherState)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state_path: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("parse state_path %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// stateKey identifies a watcher in the state file.
+func (a *assetWatcher) stateKey() string {
+	key := strings.ToLower(a.address.Hex())
+	if a.holder != nil {
+		key += "|" + strings.ToLower(a.holder.Hex())
+	}
+	return key
+}
+
+func (s *athStore) ath(key string) *allTimeHigh {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || entry.ATH == nil {
+		return nil
+	}
+	return &allTimeHigh{value: new(big.Int).Set(entry.ATH), at: entry.ATHAt}
+}
+
+// saveATH records a new all-time high and rewrites the file. Write errors are logged so
+// a full disk does not stop monitoring.
+func (s *athStore) saveATH(key string, ath *allTimeHigh) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entries[key]
+	entry.ATH = new(big.Int).Set(ath.value)
+	entry.ATHAt = ath.at
+	s.entries[key] = entry
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		logger.Errorf("state: encode: %v", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		logger.Errorf("state: write %s: %v", tmp, err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		logger.Errorf("state: replace %s: %v", s.path, err)
+	}
+}
@@ -0,0 +1,268 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/kv"
+)
+
+// ErrNoState is returned by StateStore.Load for an asset with no saved state.
+var ErrNoState = errors.New("no saved state")
+
+// StateStore keeps each watcher's State across restarts, keyed by the watcher's asset
+// (and holder) address. The snapshotter loads every asset at startup and saves them
+// every snapshot interval and on shutdown.
+type StateStore interface {
+	Load(asset string) (State, error)
+	Save(asset string, state State) error
+}
+
+// SectionStore is implemented by state stores that can also keep the named
+// SnapshotSections. Sections are not persisted with a store that does not.
+type SectionStore interface {
+	LoadSection(name string) (json.RawMessage, error)
+	SaveSection(name string, data json.RawMessage) error
+}
+
+// StateFlusher is implemented by state stores that hold Save and SaveSection in memory
+// and write them together. The snapshotter calls Flush once at the end of each pass, so
+// every pass lands as one snapshot.
+type StateFlusher interface {
+	Flush() error
+}
+
+// defaultRedisKeyPrefix and defaultS3Prefix start every key the snapshot is kept under.
+const (
+	defaultRedisKeyPrefix = "aave-cap-alerts:"
+	defaultS3Prefix       = "aave-cap-alerts/"
+)
+
+// stateStoreTimeout bounds each Redis or S3 request.
+const stateStoreTimeout = 10 * time.Second
+
+// newStateStore builds the configured backend; it returns nil when snapshots are disabled.
+func newStateStore(cfg config.SnapshotConfig) (StateStore, error) {
+	backend := cfg.Backend
+	if backend == "" && cfg.Path != "" {
+		backend = config.SnapshotBackendFile
+	}
+	if backend != config.SnapshotBackendFile && cfg.Path != "" {
+		return nil, fmt.Errorf("snapshot.path requires snapshot.backend: %s", config.SnapshotBackendFile)
+	}
+	if backend != config.SnapshotBackendRedis && cfg.Redis != nil {
+		return nil, fmt.Errorf("snapshot.redis requires snapshot.backend: %s", config.SnapshotBackendRedis)
+	}
+	if backend != config.SnapshotBackendS3 && cfg.S3 != nil {
+		return nil, fmt.Errorf("snapshot.s3 requires snapshot.backend: %s", config.SnapshotBackendS3)
+	}
+
+	switch backend {
+	case "":
+		return nil, nil
+	case config.SnapshotBackendFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("snapshot.backend %s requires snapshot.path", backend)
+		}
+		return NewFileStateStore(cfg.Path)
+	case config.SnapshotBackendRedis:
+		if cfg.Redis == nil || cfg.Redis.URL == "" {
+			return nil, fmt.Errorf("snapshot.backend %s requires snapshot.redis.url", backend)
+		}
+		client, err := kv.NewRedis(cfg.Redis.URL)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot.redis.url: %w", err)
+		}
+		prefix := cfg.Redis.KeyPrefix
+		if prefix == "" {
+			prefix = defaultRedisKeyPrefix
+		}
+		return &kvStateStore{store: client, prefix: prefix}, nil
+	case config.SnapshotBackendS3:
+		if cfg.S3 == nil {
+			return nil, fmt.Errorf("snapshot.backend %s requires snapshot.s3", backend)
+		}
+		client, err := kv.NewS3(kv.S3Config{
+			Bucket:          cfg.S3.Bucket,
+			Region:          cfg.S3.Region,
+			Endpoint:        cfg.S3.Endpoint,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			SessionToken:    cfg.S3.SessionToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("snapshot.s3: %w", err)
+		}
+		prefix := cfg.S3.Prefix
+		if prefix == "" {
+			prefix = defaultS3Prefix
+		}
+		return &kvStateStore{store: client, prefix: prefix, suffix: ".json"}, nil
+	}
+	return nil, fmt.Errorf("snapshot.backend must be %q, %q or %q", config.SnapshotBackendFile, config.SnapshotBackendRedis, config.SnapshotBackendS3)
+}
+
+// FileStateStore keeps every asset's state and the sections in one JSON file. Save and
+// SaveSection only update memory; Flush replaces the file with an atomic rename, so a
+// crash mid-write leaves the previous snapshot intact rather than a mix of the two.
+type FileStateStore struct {
+	path string
+
+	mu   sync.Mutex
+	snap stateSnapshot
+}
+
+// NewFileStateStore reads the file at path; a missing file starts empty.
+func NewFileStateStore(path string) (*FileStateStore, error) {
+	s := &FileStateStore{path: path, snap: stateSnapshot{Assets: make(map[string]State)}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.snap); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", path, err)
+	}
+	if s.snap.Assets == nil {
+		s.snap.Assets = make(map[string]State)
+	}
+	logger.Infof("state snapshot: read %s (written %s)", path, s.snap.WrittenAt.UTC().Format(time.RFC3339))
+	return s, nil
+}
+
+func (s *FileStateStore) String() string { return s.path }
+
+// Load implements StateStore.
+func (s *FileStateStore) Load(asset string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.snap.Assets[asset]
+	if !ok {
+		return State{}, ErrNoState
+	}
+	return state, nil
+}
+
+// Save implements StateStore.
+func (s *FileStateStore) Save(asset string, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.Assets[asset] = state
+	return nil
+}
+
+// LoadSection implements SectionStore.
+func (s *FileStateStore) LoadSection(name string) (json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.snap.Sections[name]
+	if !ok {
+		return nil, ErrNoState
+	}
+	return data, nil
+}
+
+// SaveSection implements SectionStore.
+func (s *FileStateStore) SaveSection(name string, data json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snap.Sections == nil {
+		s.snap.Sections = make(map[string]json.RawMessage)
+	}
+	s.snap.Sections[name] = data
+	return nil
+}
+
+// Flush implements StateFlusher.
+func (s *FileStateStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snap.WrittenAt = time.Now().UTC()
+	data, err := json.MarshalIndent(s.snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// kvStateStore keeps each asset's state as its own JSON value in a key/value store, so
+// several instances or short-lived ones can share it. Sections are kept under
+// "section/<name>".
+type kvStateStore struct {
+	store  kv.Store
+	prefix string
+	suffix string
+}
+
+func (s *kvStateStore) String() string {
+	return fmt.Sprintf("%v (prefix %q)", s.store, s.prefix)
+}
+
+func (s *kvStateStore) key(name string) string {
+	return s.prefix + name + s.suffix
+}
+
+// Load implements StateStore.
+func (s *kvStateStore) Load(asset string) (State, error) {
+	var state State
+	err := s.get("asset/"+asset, &state)
+	return state, err
+}
+
+// Save implements StateStore.
+func (s *kvStateStore) Save(asset string, state State) error {
+	return s.put("asset/"+asset, state)
+}
+
+// LoadSection implements SectionStore.
+func (s *kvStateStore) LoadSection(name string) (json.RawMessage, error) {
+	var data json.RawMessage
+	err := s.get("section/"+name, &data)
+	return data, err
+}
+
+// SaveSection implements SectionStore.
+func (s *kvStateStore) SaveSection(name string, data json.RawMessage) error {
+	return s.put("section/"+name, data)
+}
+
+func (s *kvStateStore) get(name string, v any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+	data, err := s.store.Get(ctx, s.key(name))
+	if errors.Is(err, kv.ErrNotFound) {
+		return ErrNoState
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", s.key(name), err)
+	}
+	return nil
+}
+
+func (s *kvStateStore) put(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+	return s.store.Put(ctx, s.key(name), data)
+}
@@ -0,0 +1,189 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+)
+
+// AssetStatus is a point-in-time view of a watcher's resolved configuration and state.
+// Big integers are encoded as decimal strings to keep full precision in JSON.
+type AssetStatus struct {
+	Name               string   `json:"name"`
+	Address            string   `json:"address"`
+	Holder             string   `json:"holder,omitempty"`
+	Metric             string   `json:"metric"`
+	TargetTotalSupply  *string  `json:"target_total_supply"`
+	TargetDirection    string   `json:"target_direction"`
+	UsesSupplyCap      bool     `json:"uses_supply_cap"`
+	CapURL             string   `json:"cap_url,omitempty"`
+	CapToleranceTokens *string  `json:"cap_tolerance_tokens,omitempty"`
+	Triggers           []string `json:"triggers"`
+	NotifyOnIncrease   bool     `json:"notify_on_increase"`
+	NotifyOnDecrease   bool     `json:"notify_on_decrease"`
+	NotifyOnFirst      bool     `json:"notify_on_first_observation"`
+	Shadow             bool     `json:"shadow,omitempty"`
+	Paused             bool     `json:"paused"`
+	IndexJumpPct       *string  `json:"index_jump_pct,omitempty"`
+	BaselineDeadband   *string  `json:"baseline_deadband,omitempty"`
+	MaxAlertsPerHour   int      `json:"max_alerts_per_hour,omitempty"`
+	GraphURL           string   `json:"graph_url,omitempty"`
+	PollInterval       string   `json:"poll_interval"`
+	Decimals           *uint8   `json:"decimals"`
+	LastTotalSupply    *string  `json:"last_total_supply"`
+	LastLiquidityIndex *string  `json:"last_liquidity_index,omitempty"`
+	// SubthresholdDrift is the net change of the SubthresholdChanges changes that fired
+	// no trigger since the last triggered change or digest.
+	SubthresholdDrift   *string `json:"subthreshold_drift,omitempty"`
+	SubthresholdChanges int     `json:"subthreshold_changes,omitempty"`
+	// The armed states record which side of each threshold the last reading was on, as
+	// kept in the state snapshot: Levels by level in tokens, CapLevels by level_pct.
+	Levels             map[string]bool `json:"levels,omitempty"`
+	CapLevels          map[string]bool `json:"cap_levels,omitempty"`
+	CapETAWarned       bool            `json:"cap_eta_warned"`
+	DebtCeilingAbove   *bool           `json:"debt_ceiling_above,omitempty"`
+	LiquidityRateAbove *bool           `json:"liquidity_rate_above,omitempty"`
+	BorrowRateAbove    *bool           `json:"borrow_rate_above,omitempty"`
+	LastCheck          *time.Time      `json:"last_check"`
+	// LastCheckSeconds is how long the last check took; CheckOverruns counts checks that
+	// took longer than the poll interval.
+	LastCheckSeconds *float64 `json:"last_check_duration_seconds,omitempty"`
+	CheckOverruns    uint64   `json:"check_overruns"`
+	LastError        string   `json:"last_error,omitempty"`
+}
+
+// statusBox guards the status snapshot a watcher publishes after each check so HTTP
+// handlers can read it while the watcher goroutine keeps running.
+type statusBox struct {
+	mu     sync.RWMutex
+	status AssetStatus
+}
+
+func (b *statusBox) load() AssetStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.status
+}
+
+func (b *statusBox) store(status AssetStatus) {
+	b.mu.Lock()
+	b.status = status
+	b.mu.Unlock()
+}
+
+// Assets returns the current status of every watcher in configuration order.
+func (s *Service) Assets() []AssetStatus {
+	statuses := make([]AssetStatus, 0, len(s.assets))
+	for _, a := range s.assets {
+		status := a.status.load()
+		status.Paused = a.paused.Load()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// ErrUnknownAsset is returned by SetPaused when no watcher tracks the given address.
+var ErrUnknownAsset = errors.New("unknown asset")
+
+// SetPaused mutes or unmutes every watcher of the aToken at address. Paused watchers keep
+// polling and updating their baseline but send no notifications. It is safe to call
+// while the service is running.
+func (s *Service) SetPaused(address string, paused bool) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%w: %q is not a hex address", ErrUnknownAsset, address)
+	}
+	target := common.HexToAddress(address)
+
+	found := false
+	for _, a := range s.assets {
+		if a.address == target {
+			a.paused.Store(paused)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUnknownAsset, target.Hex())
+	}
+	return nil
+}
+
+// publishStatus snapshots the watcher state. It must be called from the watcher goroutine.
+func (a *assetWatcher) publishStatus(lastCheck *time.Time, checkErr error) {
+	status := AssetStatus{
+		Name:               a.name,
+		Address:            a.address.Hex(),
+		Holder:             a.holderHex(),
+		Metric:             a.metric(),
+		TargetTotalSupply:  optionalString(a.targetTotalSupply),
+		UsesSupplyCap:      a.capSource != nil,
+		Triggers:           a.triggerNames(),
+		NotifyOnIncrease:   a.notifyOnIncrease,
+		NotifyOnDecrease:   a.notifyOnDecrease,
+		NotifyOnFirst:      a.notifyOnFirst,
+		Shadow:             a.shadow,
+		BaselineDeadband:   optionalString(a.deadband),
+		GraphURL:           a.graphURL,
+		PollInterval:       a.pollInterval.String(),
+		LastTotalSupply:    optionalString(a.lastTotalSupply),
+		LastLiquidityIndex: optionalString(a.lastIndex),
+		LastCheck:          lastCheck,
+		CapETAWarned:       a.capETAWarned,
+		DebtCeilingAbove:   cloneBool(a.debtCeilingAbove),
+	}
+	status.TargetDirection = config.TargetAbove
+	if a.targetBelow {
+		status.TargetDirection = config.TargetBelow
+	}
+	status.Levels, status.CapLevels = a.armedLevels()
+	if a.liquidityRate != nil {
+		status.LiquidityRateAbove = cloneBool(a.liquidityRate.above)
+	}
+	if a.borrowRate != nil {
+		status.BorrowRateAbove = cloneBool(a.borrowRate.above)
+	}
+	if a.indexJumpPct != nil {
+		pct := a.indexJumpPct.RatString()
+		status.IndexJumpPct = &pct
+	}
+	if a.capTolerance != nil {
+		tolerance := a.capTolerance.RatString()
+		status.CapToleranceTokens = &tolerance
+	}
+	if a.capURL != nil {
+		status.CapURL = a.capURL.url
+	}
+	if a.subthreshold != nil {
+		status.SubthresholdDrift = optionalString(a.subthreshold.drift)
+		status.SubthresholdChanges = a.subthreshold.count
+	}
+	if a.limiter != nil {
+		status.MaxAlertsPerHour = a.limiter.max
+	}
+	if a.decimalsLoaded {
+		decimals := a.decimals
+		status.Decimals = &decimals
+	}
+	if lastCheck != nil {
+		seconds := a.lastCheckDuration.Seconds()
+		status.LastCheckSeconds = &seconds
+	}
+	status.CheckOverruns = a.counters.overruns.Load()
+	if checkErr != nil {
+		status.LastError = checkErr.Error()
+	}
+	a.status.store(status)
+}
+
+func optionalString(v *big.Int) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.String()
+	return &s
+}
@@ -0,0 +1,42 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"aave-cap-alerts/internal/config"
+)
+
+func TestStatusReportsArmedStates(t *testing.T) {
+	above, below := true, false
+	a := &assetWatcher{
+		name:             "USDC",
+		address:          common.HexToAddress("0x1"),
+		targetBelow:      true,
+		capETAWarned:     true,
+		debtCeilingAbove: &above,
+		levels:           []*alertLevel{{tokens: big.NewRat(5, 2), above: &above}, {tokens: big.NewRat(10, 1)}},
+		capLevels:        []*capLevel{{pct: big.NewRat(90, 1), reached: &below}},
+		borrowRate:       &rateThreshold{name: "variable borrow rate", pct: big.NewRat(10, 1), above: &above},
+	}
+	a.publishStatus(nil, nil)
+	status := a.status.load()
+
+	if status.TargetDirection != config.TargetBelow {
+		t.Errorf("target_direction = %q, want %q", status.TargetDirection, config.TargetBelow)
+	}
+	if len(status.Levels) != 1 || !status.Levels["5/2"] {
+		t.Errorf("levels = %v, want only 5/2 above", status.Levels)
+	}
+	if reached, ok := status.CapLevels["90"]; !ok || reached {
+		t.Errorf("cap_levels = %v, want 90 not reached", status.CapLevels)
+	}
+	if !status.CapETAWarned || status.DebtCeilingAbove == nil || !*status.DebtCeilingAbove {
+		t.Errorf("cap ETA warned %v, debt ceiling above %v", status.CapETAWarned, status.DebtCeilingAbove)
+	}
+	if status.BorrowRateAbove == nil || !*status.BorrowRateAbove || status.LiquidityRateAbove != nil {
+		t.Errorf("borrow rate above %v, liquidity rate above %v", status.BorrowRateAbove, status.LiquidityRateAbove)
+	}
+}
@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// defaultSubthresholdDigestInterval is how often sub-threshold drift is reported in
+// digest mode when subthreshold_digest_interval is unset.
+const defaultSubthresholdDigestInterval = time.Hour
+
+// subthresholdReport accumulates the supply changes that fired no trigger. Its drift is
+// the net change they add up to since the last triggered change or digest, so slow
+// movements that never cross a percentage trigger still become visible.
+type subthresholdReport struct {
+	mode     string
+	interval time.Duration
+
+	// from is the value the accumulated changes started at; nil when none are pending.
+	from  *big.Int
+	drift *big.Int
+	count int
+	since time.Time
+}
+
+// newSubthresholdReport parses an asset's sub-threshold reporting; it returns nil when
+// the changes are only logged.
+func newSubthresholdReport(name string, cfg config.AssetConfig) (*subthresholdReport, error) {
+	switch cfg.ReportSubthresholdChanges {
+	case "":
+		if cfg.SubthresholdDigestInterval != "" {
+			return nil, fmt.Errorf("asset %s subthreshold_digest_interval requires report_subthreshold_changes: %s", name, config.SubthresholdDigest)
+		}
+		return nil, nil
+	case config.SubthresholdNotify:
+		if cfg.SubthresholdDigestInterval != "" {
+			return nil, fmt.Errorf("asset %s subthreshold_digest_interval requires report_subthreshold_changes: %s", name, config.SubthresholdDigest)
+		}
+	case config.SubthresholdDigest:
+	default:
+		return nil, fmt.Errorf("asset %s report_subthreshold_changes must be %q or %q", name, config.SubthresholdNotify, config.SubthresholdDigest)
+	}
+	r := &subthresholdReport{
+		mode:     cfg.ReportSubthresholdChanges,
+		interval: defaultSubthresholdDigestInterval,
+		drift:    new(big.Int),
+	}
+	if cfg.SubthresholdDigestInterval != "" {
+		interval, err := time.ParseDuration(cfg.SubthresholdDigestInterval)
+		if err != nil {
+			return nil, fmt.Errorf("parse asset %s subthreshold_digest_interval: %w", name, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("asset %s subthreshold_digest_interval must be positive", name)
+		}
+		r.interval = interval
+	}
+	return r, nil
+}
+
+// add accumulates a change from old to current observed at now.
+func (r *subthresholdReport) add(old, current *big.Int, now time.Time) {
+	if r.from == nil {
+		r.from = new(big.Int).Set(old)
+		r.since = now
+	}
+	r.drift.Add(r.drift, new(big.Int).Sub(current, old))
+	r.count++
+}
+
+// reset discards the accumulated changes, once a trigger has reported them or a digest
+// has been sent.
+func (r *subthresholdReport) reset() {
+	r.from = nil
+	r.drift.SetInt64(0)
+	r.count = 0
+}
+
+// reportSubthreshold handles a change from the baseline to current that matched no
+// trigger: it is added to the drift and, in notify mode, sent on its own.
+func (a *assetWatcher) reportSubthreshold(ctx context.Context, d *dispatcher, current *big.Int, source string, obs observation) {
+	logger.Debugf("asset %s %s changed to %s (no triggers matched)", a.name, a.metric(), current.String())
+	r := a.subthreshold
+	if r == nil {
+		return
+	}
+	r.add(a.lastTotalSupply, current, obs.observedAt)
+	if r.mode == config.SubthresholdNotify {
+		a.notify(ctx, d, a.subthresholdEvent(a.lastTotalSupply, current, source, obs))
+	}
+}
+
+// flushSubthreshold sends the digest of the accumulated changes once the interval since
+// the first of them has passed. It is called on every check.
+func (a *assetWatcher) flushSubthreshold(ctx context.Context, d *dispatcher, obs observation) {
+	r := a.subthreshold
+	if r == nil || r.mode != config.SubthresholdDigest || r.count == 0 || obs.observedAt.Sub(r.since) < r.interval {
+		return
+	}
+	// The accumulated changes end at the current baseline.
+	event := a.subthresholdEvent(r.from, new(big.Int).Add(r.from, r.drift), "", obs)
+	event.CoalescedChanges = r.count
+	a.notify(ctx, d, event)
+	r.reset()
+}
+
+func (a *assetWatcher) subthresholdEvent(from, to *big.Int, source string, obs observation) notify.SupplyChangeEvent {
+	return notify.SupplyChangeEvent{
+		Type:              notify.EventSubthresholdChange,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		OldTotalSupply:    new(big.Int).Set(from),
+		NewTotalSupply:    new(big.Int).Set(to),
+		Change:            relativeChange(from, to),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            source,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		TriggerReasons:    []string{a.subthreshold.reason(a.metric())},
+		History:           a.history.values(),
+		ObservedAt:        obs.observedAt,
+	}
+}
+
+func (r *subthresholdReport) reason(metric string) string {
+	return fmt.Sprintf("%d %s change(s) below the triggers since %s, net drift %s",
+		r.count, metric, r.since.UTC().Format(time.RFC3339), signedString(r.drift))
+}
+
+func signedString(v *big.Int) string {
+	if v.Sign() > 0 {
+		return "+" + v.String()
+	}
+	return v.String()
+}
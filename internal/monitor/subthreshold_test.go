@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+)
+
+func TestSubthresholdReportAccumulatesDrift(t *testing.T) {
+	r, err := newSubthresholdReport("USDC", config.AssetConfig{ReportSubthresholdChanges: config.SubthresholdDigest, SubthresholdDigestInterval: "30m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.add(big.NewInt(1000), big.NewInt(1010), start)
+	r.add(big.NewInt(1010), big.NewInt(1005), start.Add(time.Minute))
+	r.add(big.NewInt(1005), big.NewInt(1030), start.Add(2*time.Minute))
+
+	if r.count != 3 || r.drift.Int64() != 30 || r.from.Int64() != 1000 || !r.since.Equal(start) {
+		t.Fatalf("report = %d changes, drift %s from %s since %s", r.count, r.drift, r.from, r.since)
+	}
+	want := "3 total supply change(s) below the triggers since 2024-01-01T00:00:00Z, net drift +30"
+	if got := r.reason("total supply"); got != want {
+		t.Errorf("reason = %q, want %q", got, want)
+	}
+
+	r.reset()
+	r.add(big.NewInt(1030), big.NewInt(1020), start.Add(time.Hour))
+	if r.count != 1 || r.drift.Int64() != -10 || r.from.Int64() != 1030 {
+		t.Errorf("after reset = %d changes, drift %s from %s", r.count, r.drift, r.from)
+	}
+}
+
+func TestNewSubthresholdReportValidatesConfig(t *testing.T) {
+	if r, err := newSubthresholdReport("USDC", config.AssetConfig{}); r != nil || err != nil {
+		t.Errorf("unset = %v, %v; want off", r, err)
+	}
+	r, err := newSubthresholdReport("USDC", config.AssetConfig{ReportSubthresholdChanges: config.SubthresholdDigest})
+	if err != nil || r.interval != defaultSubthresholdDigestInterval {
+		t.Errorf("digest defaults = %+v, %v", r, err)
+	}
+	for _, cfg := range []config.AssetConfig{
+		{ReportSubthresholdChanges: "page"},
+		{SubthresholdDigestInterval: "1h"},
+		{ReportSubthresholdChanges: config.SubthresholdNotify, SubthresholdDigestInterval: "1h"},
+		{ReportSubthresholdChanges: config.SubthresholdDigest, SubthresholdDigestInterval: "0s"},
+	} {
+		if _, err := newSubthresholdReport("USDC", cfg); err == nil {
+			t.Errorf("%q/%q: expected an error", cfg.ReportSubthresholdChanges, cfg.SubthresholdDigestInterval)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+// summaryEventName is the asset name carried by startup and shutdown summary events.
+const summaryEventName = "aave-cap-alerts"
+
+// watcherCounters tallies a watcher's activity since startup for the shutdown summary.
+type watcherCounters struct {
+	checks   atomic.Uint64
+	failures atomic.Uint64
+	// notified counts events handed to the dispatcher, including rate limit notices.
+	notified atomic.Uint64
+	// overruns counts checks that took longer than the poll interval.
+	overruns atomic.Uint64
+}
+
+// summaryLines describes the run: one line for the service, then one per asset.
+func (s *Service) summaryLines(now time.Time) []string {
+	var checks, failures, notified uint64
+	assetLines := make([]string, 0, len(s.assets))
+	for _, a := range s.assets {
+		c, f, n := a.counters.checks.Load(), a.counters.failures.Load(), a.counters.notified.Load()
+		checks, failures, notified = checks+c, failures+f, notified+n
+		last := "none"
+		if status := a.status.load(); status.LastTotalSupply != nil {
+			last = *status.LastTotalSupply
+		}
+		assetLines = append(assetLines, fmt.Sprintf("asset %s: checks=%d failed=%d notifications=%d last_%s=%s",
+			a.name, c, f, n, metricKey(a.metric()), last))
+	}
+	header := fmt.Sprintf("ran %s: assets=%d checks=%d failed=%d notifications=%d",
+		now.Sub(s.started).Round(time.Second), len(s.assets), checks, failures, notified)
+	return append([]string{header}, assetLines...)
+}
+
+// metricKey turns a metric name such as "total supply" into a key like total_supply.
+func metricKey(metric string) string {
+	key := []byte(metric)
+	for i, c := range key {
+		if c == ' ' {
+			key[i] = '_'
+		}
+	}
+	return string(key)
+}
+
+// startupLines describes the running configuration: one line for the service, then the
+// other watchers if any.
+func (s *Service) startupLines() []string {
+	// The main RPC plus each distinct aggregate member RPC.
+	chains := 1 + len(aggregateRPCURLs(s.aggregates))
+	names := make([]string, len(s.assets))
+	for i, a := range s.assets {
+		names[i] = a.name
+	}
+	lines := []string{
+		fmt.Sprintf("monitoring started: %d asset(s) across %d chain(s), poll interval %s", len(s.assets), chains, s.defaultPoll),
+		"assets: " + strings.Join(names, ", "),
+	}
+	if others := len(s.pairs) + len(s.aggregates) + len(s.concentrations); others > 0 {
+		lines = append(lines, fmt.Sprintf("pairs=%d aggregates=%d concentrations=%d", len(s.pairs), len(s.aggregates), len(s.concentrations)))
+	}
+	return lines
+}
+
+// announceStartup sends the monitoring_started event.
+func (s *Service) announceStartup(ctx context.Context) {
+	lines := s.startupLines()
+	logger.Infof("%s", lines[0])
+	s.dispatcher.dispatch(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventMonitoringStarted,
+		AssetName:      summaryEventName,
+		TriggerReasons: lines,
+		ObservedAt:     time.Now(),
+	})
+}
+
+// finish logs the shutdown summary and, when enabled, delivers it to the notifiers. The
+// run's context is already cancelled, so delivery gets its own notifyTimeout and skips
+// the queue, whose workers have stopped.
+func (s *Service) finish() {
+	now := time.Now()
+	lines := s.summaryLines(now)
+	for _, line := range lines {
+		logger.Infof("shutdown summary: %s", line)
+	}
+	if !s.notifySummary {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	s.dispatcher.deliverEvent(ctx, notify.SupplyChangeEvent{
+		Type:           notify.EventShutdownSummary,
+		AssetName:      summaryEventName,
+		TriggerReasons: lines,
+		ObservedAt:     now,
+	})
+}
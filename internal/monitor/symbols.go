@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ResolveSymbols fills in the address of every asset configured by symbol, matching the
+// symbol case-insensitively against the underlying tokens of the Pool's reserves and
+// taking the reserve's aToken. The reserve list is read once and shared by all assets.
+// It must be called before NewService and fails if a symbol is unknown or ambiguous.
+func ResolveSymbols(ctx context.Context, client *aave.Client, cfg *config.Config) error {
+	needed := false
+	for i := range cfg.Assets {
+		asset := &cfg.Assets[i]
+		if asset.Symbol == "" {
+			continue
+		}
+		if asset.Address != "" {
+			return fmt.Errorf("asset %s: set either symbol or address, not both", asset.Symbol)
+		}
+		needed = true
+	}
+	if !needed {
+		return nil
+	}
+
+	contracts, err := resolveContracts(cfg)
+	if err != nil {
+		return err
+	}
+	if contracts.pool == nil {
+		return fmt.Errorf("assets configured by symbol require contracts.pool (or pool_address) to be configured")
+	}
+
+	retry, err := newMetadataRetry(cfg.MetadataRetry)
+	if err != nil {
+		return err
+	}
+	registry, err := reserveRegistry(ctx, client, *contracts.pool, retry)
+	if err != nil {
+		return err
+	}
+
+	for i := range cfg.Assets {
+		asset := &cfg.Assets[i]
+		if asset.Symbol == "" {
+			continue
+		}
+		matches := registry[strings.ToUpper(asset.Symbol)]
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("asset symbol %s: no reserve with that underlying symbol", asset.Symbol)
+		case 1:
+		default:
+			return fmt.Errorf("asset symbol %s: ambiguous, %d reserves match; configure address instead", asset.Symbol, len(matches))
+		}
+
+		asset.Address = matches[0].Hex()
+		if asset.Name == "" {
+			asset.Name = asset.Symbol
+		}
+		logger.Infof("asset symbol %s resolved to aToken %s", asset.Symbol, asset.Address)
+	}
+	return nil
+}
+
+// reserveRegistry maps upper-cased underlying symbols to the aTokens of matching reserves.
+// Reserves whose symbol cannot be read, even after retrying, are skipped.
+func reserveRegistry(ctx context.Context, client *aave.Client, pool common.Address, retry metadataRetry) (map[string][]common.Address, error) {
+	var reserves []common.Address
+	err := retry.do(ctx, "reserves list read", func() (err error) {
+		reserves, err = client.ReservesList(ctx, pool)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch reserves list: %w", err)
+	}
+
+	registry := make(map[string][]common.Address, len(reserves))
+	for _, underlying := range reserves {
+		var symbol string
+		err := retry.do(ctx, "reserve "+underlying.Hex()+" symbol read", func() (err error) {
+			symbol, err = client.Symbol(ctx, underlying)
+			return err
+		})
+		if err != nil {
+			logger.Debugf("reserve %s: skipping symbol lookup: %v", underlying.Hex(), err)
+			continue
+		}
+		var aToken common.Address
+		err = retry.do(ctx, "reserve "+underlying.Hex()+" aToken read", func() (err error) {
+			aToken, err = client.ATokenAddress(ctx, pool, underlying)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("reserve %s aToken: %w", underlying.Hex(), err)
+		}
+		key := strings.ToUpper(symbol)
+		registry[key] = append(registry[key], aToken)
+	}
+	return registry, nil
+}
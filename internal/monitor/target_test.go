@@ -0,0 +1,81 @@
+package monitor
+
+import (
+	"math/big"
+	"testing"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestTargetDirectionHysteresis(t *testing.T) {
+	tests := []struct {
+		name   string
+		below  bool
+		supply []int64
+		fires  []bool
+	}{
+		{
+			name:   "above",
+			supply: []int64{900, 1000, 1100, 950, 990, 1000},
+			fires:  []bool{false, true, false, false, false, true},
+		},
+		{
+			name:   "below",
+			below:  true,
+			supply: []int64{1100, 1000, 900, 1050, 1010, 1000},
+			fires:  []bool{false, true, false, false, false, true},
+		},
+		{
+			// An upward target never fires on the way down, and vice versa.
+			name:   "above ignores falling",
+			supply: []int64{1100, 1000, 900},
+			fires:  []bool{false, false, false},
+		},
+		{
+			name:   "below ignores rising",
+			below:  true,
+			supply: []int64{900, 1000, 1100},
+			fires:  []bool{false, false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &assetWatcher{
+				targetTotalSupply: big.NewInt(1000),
+				targetBelow:       tt.below,
+				notifyOnTarget:    true,
+				supplyMetric:      "total_supply",
+			}
+			for i, v := range tt.supply {
+				supply := big.NewInt(v)
+				eventType, reasons := a.evaluateTriggers(supply)
+				if fired := eventType == notify.EventTargetReached; fired != tt.fires[i] {
+					t.Errorf("step %d (%d): fired = %v, want %v (reasons %v)", i, v, fired, tt.fires[i], reasons)
+				}
+				a.lastTotalSupply = supply
+			}
+		})
+	}
+}
+
+func TestTargetBelowTolerance(t *testing.T) {
+	// A positive tolerance fires 5 tokens before a downward target, i.e. above it.
+	a := &assetWatcher{
+		targetTotalSupply: big.NewInt(100000),
+		targetBelow:       true,
+		capTolerance:      big.NewRat(5, 1),
+		decimals:          2,
+		notifyOnTarget:    true,
+		lastTotalSupply:   big.NewInt(110000),
+		supplyMetric:      "total_supply",
+	}
+	if got := a.effectiveTarget(); got.Int64() != 100500 {
+		t.Fatalf("effective target = %s, want 100500", got)
+	}
+	if eventType, _ := a.evaluateTriggers(big.NewInt(100501)); eventType == notify.EventTargetReached {
+		t.Error("fired one unit above the effective threshold")
+	}
+	if eventType, _ := a.evaluateTriggers(big.NewInt(100500)); eventType != notify.EventTargetReached {
+		t.Error("did not fire at the effective threshold")
+	}
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"aave-cap-alerts/internal/notify"
+)
+
+func TestCapToleranceBoundary(t *testing.T) {
+	// Target 1000 tokens with 2 decimals: 100000 base units.
+	target := big.NewInt(100000)
+
+	tests := []struct {
+		name      string
+		tolerance *big.Rat
+		supply    int64
+		fires     bool
+	}{
+		{"no tolerance, exact target", nil, 100000, true},
+		{"no tolerance, one unit below", nil, 99999, false},
+		{"no tolerance, one unit above", nil, 100001, true},
+		{"positive, exact effective threshold", big.NewRat(5, 1), 99500, true},
+		{"positive, one unit below", big.NewRat(5, 1), 99499, false},
+		{"positive, one unit above", big.NewRat(5, 1), 99501, true},
+		{"negative, exact effective threshold", big.NewRat(-5, 1), 100500, true},
+		{"negative, one unit below", big.NewRat(-5, 1), 100499, false},
+		{"negative, one unit above", big.NewRat(-5, 1), 100501, true},
+		{"negative, exact target", big.NewRat(-5, 1), 100000, false},
+		{"fractional truncates", big.NewRat(1, 1000), 100000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &assetWatcher{
+				targetTotalSupply: target,
+				capTolerance:      tt.tolerance,
+				decimals:          2,
+				notifyOnTarget:    true,
+				lastTotalSupply:   big.NewInt(90000),
+				supplyMetric:      "total_supply",
+			}
+			eventType, reasons := a.evaluateTriggers(big.NewInt(tt.supply))
+			fired := eventType == notify.EventTargetReached
+			if fired != tt.fires {
+				t.Fatalf("supply %d: fired = %v, want %v (reasons %v)", tt.supply, fired, tt.fires, reasons)
+			}
+			if fired && tt.tolerance != nil && tt.tolerance.Sign() != 0 {
+				threshold := a.effectiveTarget()
+				if threshold.Cmp(target) != 0 && !strings.Contains(reasons[0], "effective threshold "+threshold.String()) {
+					t.Errorf("reason %q does not name effective threshold %s", reasons[0], threshold)
+				}
+			}
+		})
+	}
+}
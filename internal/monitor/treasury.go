@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/aave"
+	"aave-cap-alerts/internal/notify"
+)
+
+// checkTreasury reads the reserve's accruedToTreasury and fires a treasury event when it
+// crosses the configured threshold from below. Fee accrual spikes are an ops signal that
+// is independent of user supply.
+func (a *assetWatcher) checkTreasury(ctx context.Context, client *aave.Client, d *dispatcher, totalSupply *big.Int, obs observation) error {
+	underlying, err := a.resolveUnderlying(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	accrued, err := client.AccruedToTreasury(ctx, *a.pool, underlying)
+	if err != nil {
+		return fmt.Errorf("fetch accruedToTreasury: %w", err)
+	}
+
+	previous := a.lastAccrued
+	a.lastAccrued = accrued
+	if previous == nil || previous.Cmp(a.treasuryThreshold) >= 0 || accrued.Cmp(a.treasuryThreshold) < 0 {
+		return nil
+	}
+
+	logger.Infof("asset %s accruedToTreasury crossed %s: %s -> %s", a.name, a.treasuryThreshold.String(), previous.String(), accrued.String())
+	a.notify(ctx, d, notify.SupplyChangeEvent{
+		Type:              notify.EventTreasuryThreshold,
+		AssetName:         a.name,
+		AssetAddress:      a.address.Hex(),
+		ExplorerURL:       a.explorerLink(),
+		Holder:            a.holderHex(),
+		NewTotalSupply:    new(big.Int).Set(totalSupply),
+		TargetTotalSupply: cloneBigInt(a.targetTotalSupply),
+		Decimals:          a.decimals,
+		Source:            notify.SourceRPC,
+		BlockNumber:       obs.blockNumber,
+		BlockTimestamp:    obs.blockTime,
+		AccruedToTreasury: new(big.Int).Set(accrued),
+		TriggerReasons: []string{
+			fmt.Sprintf("accruedToTreasury reached %s: %s -> %s", a.treasuryThreshold.String(), previous.String(), accrued.String()),
+		},
+		ObservedAt: obs.observedAt,
+	})
+	return nil
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+	"aave-cap-alerts/internal/notify"
+)
+
+// Supplies are uint256 on chain; every comparison and ratio must stay exact up to the
+// maximum, where a float64 or int64 shortcut would silently lose precision or wrap.
+
+func TestTriggersAtUint256Max(t *testing.T) {
+	almost := new(big.Int).Sub(maxUint256, big.NewInt(1))
+	a := &assetWatcher{
+		lastTotalSupply:   almost,
+		targetTotalSupply: maxUint256,
+		notifyOnIncrease:  true,
+		notifyOnDecrease:  true,
+		notifyOnTarget:    true,
+		supplyMetric:      config.SupplyMetricTotal,
+	}
+	// A one-unit rise is far below 10% but still reaches the target at the very top.
+	eventType, reasons := a.evaluateTriggers(maxUint256)
+	if eventType != notify.EventTargetReached {
+		t.Fatalf("event type = %q (reasons %v), want %q", eventType, reasons, notify.EventTargetReached)
+	}
+	if !strings.Contains(strings.Join(reasons, "; "), maxUint256.String()) {
+		t.Errorf("reasons %v do not name the exact target", reasons)
+	}
+
+	if increasedByMoreThanOnePercent(almost, maxUint256) {
+		t.Error("a one-unit rise near the maximum counted as a large increase")
+	}
+	tenth := new(big.Int).Quo(maxUint256, big.NewInt(11))
+	if !increasedByMoreThanOnePercent(tenth, maxUint256) {
+		t.Error("growth to the maximum from a tenth of it was not detected")
+	}
+
+	change := relativeChange(almost, maxUint256)
+	if want := new(big.Rat).SetFrac(big.NewInt(1), almost); change.Cmp(want) != 0 {
+		t.Errorf("relativeChange = %s, want %s", change.RatString(), want.RatString())
+	}
+}
+
+func TestThresholdsBeyondInt64(t *testing.T) {
+	a := &assetWatcher{decimals: 18, supplyMetric: config.SupplyMetricTotal}
+	// 1e59 tokens at 18 decimals is 1e77 base units, the largest power of ten in uint256.
+	level := &alertLevel{tokens: new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(59), nil))}
+	if got, want := level.baseUnits(a.decimals).String(), "1"+strings.Repeat("0", 77); got != want {
+		t.Errorf("baseUnits = %s, want %s", got, want)
+	}
+
+	capLevel := &capLevel{pct: big.NewRat(95, 1)}
+	want := new(big.Int).Quo(new(big.Int).Mul(maxUint256, big.NewInt(95)), big.NewInt(100))
+	if got := capLevel.threshold(maxUint256); got.Cmp(want) != 0 {
+		t.Errorf("cap level threshold = %s, want %s", got, want)
+	}
+
+	if got := tokensString(maxUint256, 0); got != maxUint256.String()+".00" {
+		t.Errorf("tokensString = %s", got)
+	}
+}
+
+func TestCapETAAtUint256Scale(t *testing.T) {
+	var history sampleRing
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history.add(big.NewInt(0), start)
+	history.add(big.NewInt(1), start.Add(time.Second))
+	// A target this far away is centuries out: the projection overflows time.Duration and
+	// must report no ETA rather than a wrapped one.
+	if eta, ok := projectCapETA(&history, maxUint256, false); ok {
+		t.Errorf("projectCapETA = %s, want no projection", eta)
+	}
+}
+
+func TestParseTokenAmountRange(t *testing.T) {
+	for _, in := range []string{"1e59", "-1e59", "0.5", maxUint256.String()} {
+		if _, err := parseTokenAmount(in); err != nil {
+			t.Errorf("parseTokenAmount(%q): %v", in, err)
+		}
+	}
+	for _, in := range []string{"1e78", "1e100000", "-1e100000", "1e-100000", new(big.Int).Add(maxUint256, big.NewInt(1)).String()} {
+		if _, err := parseTokenAmount(in); err == nil {
+			t.Errorf("parseTokenAmount(%q): expected an error", in)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"aave-cap-alerts/internal/config"
+)
+
+// validateAsset cross-checks an asset's parsed settings for combinations that are valid
+// one by one but can never take effect together. It returns every problem found, each
+// naming the asset, so a config can be fixed in one pass.
+func validateAsset(a *assetWatcher, cfg config.AssetConfig) error {
+	var problems []error
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf("asset %s "+format, append([]any{a.name}, args...)...))
+	}
+
+	hasTarget := a.targetTotalSupply != nil || a.capSource != nil || a.capURL != nil
+	if hasTarget && !a.notifyOnTarget && a.capETAWarn == 0 {
+		problem("has a target but neither the %s trigger nor cap_eta_warn uses it", config.TriggerCapReached)
+	}
+	if a.capTolerance != nil && !hasTarget {
+		problem("cap_tolerance_tokens requires target_cap_tokens, use_supply_cap, or cap_url")
+	}
+	if a.capTolerance != nil && hasTarget && !a.notifyOnTarget {
+		problem("cap_tolerance_tokens only applies to the %s trigger, which is disabled", config.TriggerCapReached)
+	}
+
+	percentTriggers := a.notifyOnIncrease || a.notifyOnDecrease
+	if a.reference != nil && !percentTriggers {
+		problem("reference %s has no effect without the %s or %s trigger", cfg.Reference, config.TriggerIncreasePct, config.TriggerDecreasePct)
+	}
+	if a.adaptive != nil && !percentTriggers {
+		problem("adaptive has nothing to digest without the %s or %s trigger", config.TriggerIncreasePct, config.TriggerDecreasePct)
+	}
+	if a.adaptive != nil && a.adaptive.quietPeriod < a.adaptive.digestInterval {
+		problem("adaptive.quiet_period %s is shorter than digest_interval %s", a.adaptive.quietPeriod, a.adaptive.digestInterval)
+	}
+
+	// Isolation-mode debt cannot exceed the ceiling, so utilization never passes 100%.
+	if a.debtCeilingPct != nil && a.debtCeilingPct.Cmp(big.NewRat(100, 1)) > 0 {
+		problem("debt_ceiling_pct %s can never be reached (utilization is at most 100%%)", cfg.DebtCeilingPct)
+	}
+
+	for i, level := range a.levels {
+		for _, earlier := range a.levels[:i] {
+			if level.tokens.Cmp(earlier.tokens) == 0 {
+				problem("alert_levels lists %s more than once", cfg.AlertLevels[i])
+				break
+			}
+		}
+	}
+
+	if len(a.capLevels) > 0 && !hasTarget {
+		problem("cap_levels requires target_cap_tokens, use_supply_cap, or cap_url")
+	}
+	if len(a.capLevels) > 0 && a.targetBelow {
+		problem("cap_levels escalate towards an upward target and cannot be used with target_direction %s", config.TargetBelow)
+	}
+	for i, level := range a.capLevels {
+		for _, earlier := range a.capLevels[:i] {
+			if level.pct.Cmp(earlier.pct) == 0 {
+				problem("cap_levels lists level_pct %s more than once", cfg.CapLevels[i].LevelPct)
+				break
+			}
+		}
+	}
+
+	return errors.Join(problems...)
+}
@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"aave-cap-alerts/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNewServiceReportsEveryInconsistency(t *testing.T) {
+	off := false
+	cfg := &config.Config{
+		Assets: []config.AssetConfig{
+			{
+				Name:               "USDC",
+				Address:            common.HexToAddress("0x1").Hex(),
+				Triggers:           []string{config.TriggerIncreasePct},
+				TargetCapTokens:    "1000",
+				CapToleranceTokens: "5",
+				AlertLevels:        []string{"100", "200", "100"},
+			},
+			{
+				Name:             "DAI",
+				Address:          common.HexToAddress("0x2").Hex(),
+				NotifyOnIncrease: &off,
+				Reference:        config.ReferenceSessionStart,
+				Adaptive:         &config.AdaptiveConfig{},
+			},
+			{
+				Name:    "WETH",
+				Address: common.HexToAddress("0x3").Hex(),
+			},
+		},
+	}
+	_, err := NewService(nil, cfg, nil, time.Minute)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		"asset USDC has a target but neither",
+		"asset USDC cap_tolerance_tokens only applies",
+		"asset USDC alert_levels lists 100 more than once",
+		"asset DAI reference session_start has no effect",
+		"asset DAI adaptive has nothing to digest",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "WETH") {
+		t.Errorf("consistent asset reported: %v", err)
+	}
+}
+
+func TestSetTriggers(t *testing.T) {
+	tests := []struct {
+		name    string
+		below   bool
+		cfg     config.AssetConfig
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "legacy flags",
+			cfg:  config.AssetConfig{EModeChange: true},
+			want: []string{config.TriggerIncreasePct, config.TriggerCapReached, config.TriggerStateChange},
+		},
+		{
+			name: "every trigger",
+			cfg: config.AssetConfig{
+				Triggers: []string{
+					config.TriggerDecreasePct, config.TriggerCapReached, config.TriggerVelocity,
+					config.TriggerStateChange, config.TriggerUtilization,
+				},
+				CapETAWarn:     "24h",
+				DebtCeilingPct: "90",
+			},
+			want: []string{config.TriggerDecreasePct, config.TriggerCapReached, config.TriggerStateChange},
+		},
+		{
+			name:  "floor",
+			below: true,
+			cfg:   config.AssetConfig{Triggers: []string{config.TriggerFloorBreached}},
+			want:  []string{config.TriggerFloorBreached},
+		},
+		{
+			name:    "floor needs a downward target",
+			cfg:     config.AssetConfig{Triggers: []string{config.TriggerFloorBreached}},
+			wantErr: "requires target_direction: below",
+		},
+		{
+			name:    "velocity needs cap_eta_warn",
+			cfg:     config.AssetConfig{Triggers: []string{config.TriggerVelocity}},
+			wantErr: "trigger velocity requires cap_eta_warn",
+		},
+		{
+			name:    "unlisted utilization",
+			cfg:     config.AssetConfig{Triggers: []string{config.TriggerIncreasePct}, DebtCeilingPct: "90"},
+			wantErr: "debt_ceiling_pct requires the utilization trigger",
+		},
+		{
+			name:    "list and flag",
+			cfg:     config.AssetConfig{Triggers: []string{config.TriggerStateChange}, EModeChange: true},
+			wantErr: "cannot combine triggers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &assetWatcher{targetBelow: tt.below}
+			err := a.setTriggers(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// velocity and utilization are reported once their settings are parsed.
+			if got := a.triggerNames(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("triggers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// AmountStyle selects how token amounts are written in messages.
+type AmountStyle string
+
+const (
+	// AmountGrouped groups the whole tokens in thousands and keeps up to four truncated
+	// fractional digits (e.g. "1,234,567.8912").
+	AmountGrouped AmountStyle = "grouped"
+	// AmountFull writes every fractional digit without grouping (e.g.
+	// "1234567.891234567890123456").
+	AmountFull AmountStyle = "full"
+	// AmountSigRound rounds to a number of significant figures with a K/M/B/T suffix
+	// (e.g. "≈1.23B").
+	AmountSigRound AmountStyle = "siground"
+)
+
+// AmountFormat configures how token amounts are displayed. Only the display changes;
+// structured payloads always carry the exact base-unit value.
+type AmountFormat struct {
+	Style AmountStyle
+	// Figures is the number of significant figures kept by AmountSigRound.
+	Figures int
+}
+
+// DefaultAmountFormat groups thousands, with three significant figures should siground
+// be selected.
+var DefaultAmountFormat = AmountFormat{Style: AmountGrouped, Figures: 3}
+
+// ParseAmountFormat validates configured amount settings. An empty style and a nil
+// figures pointer keep the defaults.
+func ParseAmountFormat(style string, figures *int) (AmountFormat, error) {
+	f := DefaultAmountFormat
+	switch AmountStyle(style) {
+	case "":
+	case AmountGrouped, AmountFull, AmountSigRound:
+		f.Style = AmountStyle(style)
+	default:
+		return f, fmt.Errorf("unknown amount format %q (want full, grouped or siground)", style)
+	}
+	if figures != nil {
+		if *figures < 1 || *figures > 18 {
+			return f, fmt.Errorf("significant figures must be between 1 and 18")
+		}
+		f.Figures = *figures
+	}
+	return f, nil
+}
+
+// format renders a base-unit amount in whole tokens in the configured style.
+func (f AmountFormat) format(amount *big.Int, decimals uint8) string {
+	switch f.Style {
+	case AmountFull:
+		return formatAmountFull(amount, decimals)
+	case AmountSigRound:
+		figures := f.Figures
+		if figures < 1 {
+			figures = DefaultAmountFormat.Figures
+		}
+		return formatSigRound(amount, decimals, figures)
+	default:
+		return formatAmount(amount, decimals)
+	}
+}
+
+// formatAmountFull writes an amount in whole tokens with every significant fractional
+// digit and no grouping, so it can be copied back into other tools exactly.
+func formatAmountFull(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "n/a"
+	}
+	value := new(big.Rat).SetFrac(amount, pow10(int(decimals)))
+	s := value.FloatString(int(decimals))
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// amountSuffixes name successive powers of a thousand; values beyond the last are
+// written as multiples of it ("≈1,230T").
+var amountSuffixes = []string{"", "K", "M", "B", "T"}
+
+// formatSigRound rounds an amount in whole tokens half-up to figures significant figures
+// and scales it by the largest suffix that keeps a whole part, e.g. 1234567890 tokens with
+// three figures -> "≈1.23B". The "≈" marks a value that rounding changed; trailing zeros
+// are kept, as they are significant ("1.20B").
+func formatSigRound(amount *big.Int, decimals uint8, figures int) string {
+	if amount == nil {
+		return "n/a"
+	}
+	if amount.Sign() == 0 {
+		return "0"
+	}
+	value := new(big.Rat).SetFrac(new(big.Int).Abs(amount), pow10(int(decimals)))
+
+	// exp is the decimal exponent of the leading digit: 10^exp <= value < 10^(exp+1).
+	// The digit counts of numerator and denominator put it within one of the answer.
+	exp := len(value.Num().String()) - len(value.Denom().String())
+	for value.Cmp(ratPow10(exp)) < 0 {
+		exp--
+	}
+	for value.Cmp(ratPow10(exp+1)) >= 0 {
+		exp++
+	}
+
+	// Round to an integer count of units of 10^shift, which keeps exactly figures digits.
+	shift := exp - figures + 1
+	scaled := new(big.Rat).Quo(value, ratPow10(shift))
+	units, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Lsh(rem, 1).Cmp(scaled.Denom()) >= 0 {
+		units.Add(units, big.NewInt(1))
+	}
+	if units.Cmp(pow10(figures)) == 0 {
+		// Rounding carried into a new digit, e.g. 999.6 -> 1000.
+		units.Quo(units, big.NewInt(10))
+		shift++
+		exp++
+	}
+	rounded := new(big.Rat).Mul(new(big.Rat).SetInt(units), ratPow10(shift))
+
+	tier := 0
+	if exp >= 3 {
+		tier = min(exp/3, len(amountSuffixes)-1)
+	}
+	mantissa := new(big.Rat).Quo(rounded, ratPow10(3*tier))
+	digits := mantissa.FloatString(max(0, 3*tier-shift))
+	whole, frac, _ := strings.Cut(digits, ".")
+	wholeInt, _ := new(big.Int).SetString(whole, 10)
+	out := formatTokens(wholeInt)
+	if frac != "" {
+		out += "." + frac
+	}
+
+	if amount.Sign() < 0 {
+		out = "-" + out
+	}
+	if rounded.Cmp(value) != 0 {
+		out = "≈" + out
+	}
+	return out + amountSuffixes[tier]
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ratPow10 returns 10^n for any integer n.
+func ratPow10(n int) *big.Rat {
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow10(-n))
+	}
+	return new(big.Rat).SetInt(pow10(n))
+}
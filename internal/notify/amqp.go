@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultAMQPRoutingKey is the routing key template used when none is configured.
+const DefaultAMQPRoutingKey = "{{.Type}}"
+
+// amqpDialTimeout bounds connecting to the broker; publishing is bounded by the caller's
+// context.
+const amqpDialTimeout = 10 * time.Second
+
+// AMQPNotifier publishes each event as JSON to an AMQP 0-9-1 exchange, such as one on
+// RabbitMQ. The channel is in confirm mode and Notify returns only once the broker has
+// acknowledged the message, so a nack or a connection lost mid-publish is reported as a
+// failed delivery. The connection is opened on first use and reopened on the next
+// delivery after it drops.
+type AMQPNotifier struct {
+	uri        string
+	exchange   string
+	routingKey *template.Template
+	pct        PctFormat
+
+	// mu serializes publishes so each waits for its own confirmation, and guards the
+	// connection.
+	mu     sync.Mutex
+	conn   *amqp.Connection
+	ch     *amqp.Channel
+	closed bool
+	// connected records that a connection was made before, to log reconnects.
+	connected bool
+}
+
+// NewAMQPNotifier builds a notifier publishing to exchange on the broker at uri. The
+// routing key is a Go template over the event; empty uses DefaultAMQPRoutingKey. The
+// exchange must already exist; an empty name is the default exchange. change_pct in the
+// payload is formatted by pct.
+func NewAMQPNotifier(uri, exchange, routingKey string, pct PctFormat) (*AMQPNotifier, error) {
+	if _, err := amqp.ParseURI(uri); err != nil {
+		return nil, fmt.Errorf("amqp.uri: %w", err)
+	}
+	if routingKey == "" {
+		routingKey = DefaultAMQPRoutingKey
+	}
+	tmpl, err := template.New("routing_key").Option("missingkey=error").Parse(routingKey)
+	if err != nil {
+		return nil, fmt.Errorf("amqp.routing_key: %w", err)
+	}
+	return &AMQPNotifier{uri: uri, exchange: exchange, routingKey: tmpl, pct: pct}, nil
+}
+
+// Name implements Notifier.
+func (n *AMQPNotifier) Name() string {
+	return "amqp"
+}
+
+// Notify publishes the event and waits for the broker's confirmation.
+func (n *AMQPNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	key, err := n.renderRoutingKey(event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(newEventPayload(event, n.pct))
+	if err != nil {
+		return fmt.Errorf("marshal amqp payload: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ch, err := n.channelLocked()
+	if err != nil {
+		return err
+	}
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, n.exchange, key, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    IdempotencyKey(event),
+		Timestamp:    event.ObservedAt.UTC(),
+		Type:         string(event.Type),
+		Body:         body,
+	})
+	if err != nil {
+		n.resetLocked()
+		return fmt.Errorf("publish to amqp exchange %q: %w", n.exchange, err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("wait for amqp confirmation: %w", err)
+	}
+	if !acked {
+		// A closed channel also resolves pending confirmations as nacks.
+		if ch.IsClosed() {
+			n.resetLocked()
+			return fmt.Errorf("amqp channel closed before the broker confirmed the message")
+		}
+		return fmt.Errorf("amqp broker rejected the message")
+	}
+	return nil
+}
+
+// renderRoutingKey executes the routing key template for event.
+func (n *AMQPNotifier) renderRoutingKey(event SupplyChangeEvent) (string, error) {
+	var buf bytes.Buffer
+	if err := n.routingKey.Execute(&buf, event); err != nil {
+		return "", fmt.Errorf("render amqp routing key: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// channelLocked returns the open confirm-mode channel, connecting first if there is none
+// or the previous connection was lost.
+func (n *AMQPNotifier) channelLocked() (*amqp.Channel, error) {
+	if n.closed {
+		return nil, fmt.Errorf("amqp notifier is closed")
+	}
+	if n.ch != nil && !n.ch.IsClosed() && !n.conn.IsClosed() {
+		return n.ch, nil
+	}
+	n.resetLocked()
+
+	conn, err := amqp.DialConfig(n.uri, amqp.Config{Dial: amqp.DefaultDial(amqpDialTimeout)})
+	if err != nil {
+		return nil, fmt.Errorf("connect to amqp broker: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open amqp channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("enable amqp confirm mode: %w", err)
+	}
+	if n.connected {
+		logger.Infof("amqp: reconnected to broker")
+	}
+	n.conn, n.ch, n.connected = conn, ch, true
+	return ch, nil
+}
+
+// resetLocked drops the current connection so the next delivery reconnects.
+func (n *AMQPNotifier) resetLocked() {
+	if n.conn != nil && !n.conn.IsClosed() {
+		n.conn.Close()
+	}
+	n.conn, n.ch = nil, nil
+}
+
+// Close closes the broker connection; later deliveries fail.
+func (n *AMQPNotifier) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closed = true
+	if n.conn == nil || n.conn.IsClosed() {
+		n.conn, n.ch = nil, nil
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.ch = nil, nil
+	if err != nil {
+		return fmt.Errorf("close amqp connection: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailTimeout bounds one delivery, from connecting to the server to QUIT, within the
+// caller's context.
+const emailTimeout = 30 * time.Second
+
+// EmailOptions configures an EmailNotifier. Port 465 connects with implicit TLS and port
+// 587 requires STARTTLS; on any other port STARTTLS is used when the server offers it.
+// Username and Password, when set, authenticate with PLAIN, which net/smtp only sends
+// over TLS or to localhost.
+type EmailOptions struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	Recipients []string
+}
+
+// EmailNotifier sends each event as a plain-text email through an SMTP server. A
+// connection is opened for each message.
+type EmailNotifier struct {
+	opts       EmailOptions
+	from       string
+	recipients []string
+	// fromHeader and toHeader are the parsed addresses as written in the headers.
+	fromHeader string
+	toHeader   string
+	renderer   *Renderer
+	// tlsConfig verifies the server as Host for implicit TLS and STARTTLS.
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
+
+// NewEmailNotifier builds an email notifier. The rendered message is the body. The
+// subject is rendered with the renderer's subject template, or is "Aave supply change:
+// <asset>" without one.
+func NewEmailNotifier(opts EmailOptions, renderer *Renderer) (*EmailNotifier, error) {
+	if opts.Host == "" {
+		return nil, fmt.Errorf("email.host is required")
+	}
+	if opts.Port <= 0 || opts.Port > 65535 {
+		return nil, fmt.Errorf("email.port must be between 1 and 65535")
+	}
+	if opts.Password != "" && opts.Username == "" {
+		return nil, fmt.Errorf("email.password is set without email.username")
+	}
+	from, err := mail.ParseAddress(opts.From)
+	if err != nil {
+		return nil, fmt.Errorf("email.from: %w", err)
+	}
+	if len(opts.Recipients) == 0 {
+		return nil, fmt.Errorf("email.recipients must list at least one address")
+	}
+	recipients := make([]string, len(opts.Recipients))
+	to := make([]string, len(opts.Recipients))
+	for i, recipient := range opts.Recipients {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("email.recipients[%d]: %w", i, err)
+		}
+		recipients[i] = addr.Address
+		to[i] = addr.String()
+	}
+	return &EmailNotifier{
+		opts:       opts,
+		from:       from.Address,
+		recipients: recipients,
+		fromHeader: from.String(),
+		toHeader:   strings.Join(to, ", "),
+		renderer:   renderer,
+		tlsConfig:  &tls.Config{ServerName: opts.Host},
+		now:        time.Now,
+	}, nil
+}
+
+// Name implements Notifier.
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify renders the event and sends it to every recipient in one message.
+func (e *EmailNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	body, err := e.renderer.Render(event)
+	if err != nil {
+		return err
+	}
+	subject, err := e.subject(event)
+	if err != nil {
+		return err
+	}
+	msg, err := e.message(subject, body)
+	if err != nil {
+		return err
+	}
+	if err := e.send(ctx, msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// subject renders the subject line for an event.
+func (e *EmailNotifier) subject(event SupplyChangeEvent) (string, error) {
+	if e.renderer != nil && e.renderer.subject != nil {
+		return e.renderer.Subject(event)
+	}
+	asset := event.AssetName
+	if asset == "" {
+		asset = event.AssetAddress
+	}
+	return "Aave supply change: " + asset, nil
+}
+
+// message builds the RFC 5322 message. The subject is encoded as needed, which also
+// keeps line breaks in an asset name out of the headers, and the body is sent
+// quoted-printable.
+func (e *EmailNotifier) message(subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.fromHeader)
+	fmt.Fprintf(&buf, "To: %s\r\n", e.toHeader)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", e.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("encode email body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("encode email body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// send delivers msg over a new SMTP connection.
+func (e *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(e.opts.Host, strconv.Itoa(e.opts.Port))
+	var conn net.Conn
+	var err error
+	if e.opts.Port == 465 {
+		dialer := &tls.Dialer{Config: e.tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	// Closing on cancellation unblocks whichever SMTP exchange is in progress.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, e.opts.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.opts.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(e.tlsConfig); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		} else if e.opts.Port == 587 {
+			return fmt.Errorf("server does not offer STARTTLS on port 587")
+		}
+	}
+	if e.opts.Username != "" {
+		auth := smtp.PlainAuth("", e.opts.Username, e.opts.Password, e.opts.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+	for _, recipient := range e.recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
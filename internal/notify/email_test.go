@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one message without TLS or authentication and sends the envelope
+// recipients and the DATA it received on the returned channels.
+func fakeSMTP(t *testing.T) (port int, rcpts <-chan []string, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	rcptCh := make(chan []string, 1)
+	dataCh := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		var to []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				to = append(to, strings.Trim(strings.TrimPrefix(cmd, "RCPT TO:"), "<>"))
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var sb strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					sb.WriteString(line)
+				}
+				rcptCh <- to
+				dataCh <- sb.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, rcptCh, dataCh
+}
+
+func TestEmailNotifierSendsMessage(t *testing.T) {
+	port, rcpts, data := fakeSMTP(t)
+	n, err := NewEmailNotifier(EmailOptions{
+		Host:       "127.0.0.1",
+		Port:       port,
+		From:       "Aave alerts <alerts@example.com>",
+		Recipients: []string{"risk@example.com", "Ops <ops@example.com>"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC\r\nBcc: attacker@example.com",
+		TriggerReasons: []string{"increase"},
+		ObservedAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := <-rcpts; strings.Join(got, ",") != "risk@example.com,ops@example.com" {
+		t.Errorf("envelope recipients = %v", got)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(<-data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := msg.Header.Get("Bcc"); got != "" {
+		t.Errorf("asset name injected a header: Bcc %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(subject, "Aave supply change: USDC") {
+		t.Errorf("subject = %q", subject)
+	}
+	if to := msg.Header.Get("To"); !strings.Contains(to, "<ops@example.com>") {
+		t.Errorf("To = %q", to)
+	}
+}
+
+func TestNewEmailNotifierValidates(t *testing.T) {
+	valid := EmailOptions{Host: "smtp.example.com", Port: 587, From: "a@example.com", Recipients: []string{"b@example.com"}}
+	for name, mutate := range map[string]func(*EmailOptions){
+		"host":       func(o *EmailOptions) { o.Host = "" },
+		"port":       func(o *EmailOptions) { o.Port = 0 },
+		"from":       func(o *EmailOptions) { o.From = "not an address" },
+		"recipients": func(o *EmailOptions) { o.Recipients = nil },
+		"recipient":  func(o *EmailOptions) { o.Recipients = []string{"b@example.com", "@"} },
+		"password":   func(o *EmailOptions) { o.Password = "secret" },
+	} {
+		opts := valid
+		mutate(&opts)
+		if _, err := NewEmailNotifier(opts, nil); err == nil || !strings.Contains(err.Error(), "email."+name) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+	if _, err := NewEmailNotifier(valid, nil); err != nil {
+		t.Errorf("valid options: %v", err)
+	}
+}
+
+func TestEmailNotifierSubjectTemplate(t *testing.T) {
+	event := SupplyChangeEvent{Type: EventTargetReached, AssetName: "USDC"}
+	n, err := NewEmailNotifier(EmailOptions{Host: "smtp.example.com", Port: 587, From: "a@example.com", Recipients: []string{"b@example.com"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := n.subject(event); got != "Aave supply change: USDC" {
+		t.Errorf("default subject = %q", got)
+	}
+
+	renderer, err := (*Renderer)(nil).WithSubjectTemplate("[{{.Type}}] {{.AssetName}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.renderer = renderer
+	if got, _ := n.subject(event); got != "[target_reached] USDC" {
+		t.Errorf("templated subject = %q", got)
+	}
+}
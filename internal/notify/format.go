@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"math/big"
+	"strings"
+)
+
+// amountFractionDigits is how many fractional token digits human-readable amounts keep.
+const amountFractionDigits = 4
+
+// displayOptions controls how the built-in renderers present numbers.
+type displayOptions struct {
+	pct       PctFormat
+	amount    AmountFormat
+	showRaw   bool
+	verbosity Verbosity
+	sparkline bool
+}
+
+var defaultDisplay = displayOptions{pct: DefaultPctFormat, amount: DefaultAmountFormat, showRaw: true, verbosity: VerbosityNormal}
+
+// formatAmount converts a base-unit amount into whole tokens using the token decimals,
+// grouping the integer part and keeping up to four truncated fractional digits
+// (e.g. 1234567890000000000000 with 18 decimals -> "1,234.5678").
+func formatAmount(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "n/a"
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(new(big.Int).Abs(amount), scale, new(big.Int))
+
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	out := sign + formatTokens(whole)
+
+	if decimals == 0 || frac.Sign() == 0 {
+		return out
+	}
+
+	fracDigits := frac.String()
+	fracDigits = strings.Repeat("0", int(decimals)-len(fracDigits)) + fracDigits
+	if len(fracDigits) > amountFractionDigits {
+		fracDigits = fracDigits[:amountFractionDigits]
+	}
+	fracDigits = strings.TrimRight(fracDigits, "0")
+	if fracDigits == "" {
+		return out
+	}
+	return out + "." + fracDigits
+}
+
+// displayAmount renders an amount for people, optionally followed by the exact raw value.
+func displayAmount(amount *big.Int, decimals uint8, opts displayOptions) string {
+	if amount == nil {
+		return "n/a"
+	}
+	formatted := opts.amount.format(amount, decimals)
+	if !opts.showRaw {
+		return formatted
+	}
+	return formatted + " (raw " + amount.String() + ")"
+}
@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+func TestFormatTokensGroupsAnyLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"999", "999"},
+		{"1000", "1,000"},
+		{"123456", "123,456"},
+		{"-123456", "-123,456"},
+		{"-1234567", "-1,234,567"},
+		{maxUint256.String(), "115,792,089,237,316,195,423,570,985,008,687,907,853,269,984,665,640,564,039,457,584,007,913,129,639,935"},
+	}
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.in, 10)
+		if got := formatTokens(amount); got != tt.want {
+			t.Errorf("formatTokens(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Far beyond uint256, the grouping stays exact.
+	huge := new(big.Int).Exp(big.NewInt(10), big.NewInt(300), nil)
+	if got := strings.ReplaceAll(formatTokens(huge), ",", ""); got != huge.String() {
+		t.Errorf("formatTokens(1e300) lost digits: %s", got)
+	}
+}
+
+func TestFormatAmountAtUint256Max(t *testing.T) {
+	if got, want := formatAmount(maxUint256, 18), "115,792,089,237,316,195,423,570,985,008,687,907,853,269,984,665,640,564,039,457.584"; got != want {
+		t.Errorf("formatAmount(max, 18) = %q, want %q", got, want)
+	}
+	if got := formatAmount(new(big.Int).Neg(maxUint256), 77); got != "-1.1579" {
+		t.Errorf("formatAmount(-max, 77) = %q", got)
+	}
+	// More decimals than digits: the whole part is zero and the fraction is padded.
+	if got := formatAmount(big.NewInt(5), 255); got != "0" {
+		t.Errorf("formatAmount(5, 255) = %q", got)
+	}
+}
+
+func TestPayloadKeepsUint256Exact(t *testing.T) {
+	almost := new(big.Int).Sub(maxUint256, big.NewInt(1))
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		OldTotalSupply: almost,
+		NewTotalSupply: maxUint256,
+		Change:         new(big.Rat).SetFrac(big.NewInt(1), almost),
+		Decimals:       18,
+		History:        []*big.Int{big.NewInt(0), almost, maxUint256},
+	}
+	body, err := json.Marshal(newEventPayload(event, DefaultPctFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		NewTotalSupply string `json:"new_total_supply"`
+		ChangePct      string `json:"change_pct"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.NewTotalSupply != maxUint256.String() {
+		t.Errorf("new_total_supply = %s, want %s", decoded.NewTotalSupply, maxUint256)
+	}
+	// A one-unit change rounds to zero but keeps its sign.
+	if decoded.ChangePct != "+0.00%" {
+		t.Errorf("change_pct = %q, want +0.00%%", decoded.ChangePct)
+	}
+	if got := sparkline(event.History); got != "▁▇█" {
+		t.Errorf("sparkline = %q", got)
+	}
+}
+
+func TestFormatSigRound(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		figures  int
+		want     string
+	}{
+		{"1234567890500000000000000000", 18, 3, "≈1.23B"},
+		{"1200000000", 0, 3, "1.20B"},
+		{"999600", 0, 3, "≈1.00M"},
+		{"-1500", 0, 2, "-1.5K"},
+		{"999", 0, 2, "≈1.0K"},
+		{"12345", 6, 3, "≈0.0123"},
+		{"42", 0, 3, "42.0"},
+		{"1234000000000000", 0, 3, "≈1,230T"},
+		{"0", 18, 3, "0"},
+	}
+	for _, tt := range tests {
+		amount, _ := new(big.Int).SetString(tt.amount, 10)
+		if got := formatSigRound(amount, tt.decimals, tt.figures); got != tt.want {
+			t.Errorf("formatSigRound(%s, %d, %d) = %q, want %q", tt.amount, tt.decimals, tt.figures, got, tt.want)
+		}
+	}
+	if got := formatAmountFull(big.NewInt(-1234500), 6); got != "-1.2345" {
+		t.Errorf("formatAmountFull = %q", got)
+	}
+	if _, err := ParseAmountFormat("short", nil); err == nil {
+		t.Error("expected an unknown format to be rejected")
+	}
+}
+
+func TestSummaryMessageFollowsDisplay(t *testing.T) {
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC",
+		Decimals:       0,
+		OldTotalSupply: big.NewInt(1_200_000),
+		NewTotalSupply: big.NewInt(1_500_000),
+	}
+	opts := displayOptions{pct: DefaultPctFormat, amount: AmountFormat{Style: AmountSigRound, Figures: 2}}
+	if got, want := summaryMessage(event, opts), "asset USDC total supply changed: 1.2M -> 1.5M"; got != want {
+		t.Errorf("without raw: %q, want %q", got, want)
+	}
+	opts.showRaw = true
+	if got, want := summaryMessage(event, opts), "asset USDC total supply changed: 1.2M (raw 1200000) -> 1.5M (raw 1500000)"; got != want {
+		t.Errorf("with raw: %q, want %q", got, want)
+	}
+}
+
+func TestDetailedMessageShowsTargetShareAndFlags(t *testing.T) {
+	event := SupplyChangeEvent{
+		Type:              EventTargetReached,
+		AssetName:         "USDC",
+		NewTotalSupply:    big.NewInt(950),
+		TargetTotalSupply: big.NewInt(1000),
+		ReferenceMode:     "daily",
+		Source:            SourceRPC,
+	}
+	opts := defaultDisplay
+	opts.verbosity = VerbosityDetailed
+	got := renderMessage(event, opts)
+	for _, want := range []string{"Of target: 95.00%\n", "Target direction: above\n", "Reference mode: daily\n", "Source: rpc\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("detailed message lacks %q:\n%s", want, got)
+		}
+	}
+
+	opts.verbosity = VerbosityNormal
+	if got := renderMessage(event, opts); strings.Contains(got, "Of target") {
+		t.Errorf("normal message includes detailed lines:\n%s", got)
+	}
+}
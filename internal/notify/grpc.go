@@ -0,0 +1,235 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"aave-cap-alerts/internal/notify/notifypb"
+)
+
+// Defaults for the gRPC notifier.
+const (
+	DefaultGRPCTimeout     = 10 * time.Second
+	DefaultGRPCMaxAttempts = 3
+	// maxGRPCAttempts is gRPC's own limit on retry policy attempts.
+	maxGRPCAttempts = 5
+)
+
+// GRPCOptions configure a GRPCNotifier. Without TLS the connection is plaintext.
+type GRPCOptions struct {
+	Address string
+	// Timeout is the deadline of each call, including its retries.
+	Timeout time.Duration
+	// MaxAttempts is how many times a call is tried while the server is unavailable,
+	// at most 5.
+	MaxAttempts int
+	TLS         *GRPCTLSOptions
+	// Pct formats change_pct in the JSON payload; the zero value uses DefaultPctFormat.
+	Pct PctFormat
+}
+
+// GRPCTLSOptions enable TLS. CAFile replaces the system roots; CertFile and KeyFile
+// present a client certificate; ServerName overrides the name checked on the server's
+// certificate.
+type GRPCTLSOptions struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// GRPCNotifier delivers each event by calling the Notifier.Notify RPC defined in
+// notifypb. One connection is shared by all deliveries; gRPC connects on first use and
+// reconnects by itself. Calls that fail because the server is unavailable are retried by
+// gRPC within the call's deadline.
+type GRPCNotifier struct {
+	address string
+	timeout time.Duration
+	pct     PctFormat
+	conn    *grpc.ClientConn
+	client  notifypb.NotifierClient
+
+	closeOnce sync.Once
+}
+
+// NewGRPCNotifier builds a notifier calling the service at opts.Address. It does not
+// connect until the first delivery.
+func NewGRPCNotifier(opts GRPCOptions) (*GRPCNotifier, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("grpc.address is required")
+	}
+	if opts.Timeout < 0 {
+		return nil, fmt.Errorf("grpc.timeout must not be negative")
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultGRPCTimeout
+	}
+	if opts.MaxAttempts < 0 || opts.MaxAttempts > maxGRPCAttempts {
+		return nil, fmt.Errorf("grpc.max_attempts must be between 1 and %d", maxGRPCAttempts)
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = DefaultGRPCMaxAttempts
+	}
+	if opts.Pct == (PctFormat{}) {
+		opts.Pct = DefaultPctFormat
+	}
+
+	creds := insecure.NewCredentials()
+	if opts.TLS != nil {
+		config, err := grpcTLSConfig(opts.TLS)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(config)
+	}
+	conn, err := grpc.NewClient(opts.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(grpcServiceConfig(opts.MaxAttempts)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("grpc.address: %w", err)
+	}
+	return &GRPCNotifier{
+		address: opts.Address,
+		timeout: opts.Timeout,
+		pct:     opts.Pct,
+		conn:    conn,
+		client:  notifypb.NewNotifierClient(conn),
+	}, nil
+}
+
+// grpcServiceConfig retries Notify on UNAVAILABLE, which covers a server that is
+// restarting or not yet reachable. A single attempt disables retries.
+func grpcServiceConfig(maxAttempts int) string {
+	if maxAttempts < 2 {
+		return `{}`
+	}
+	return fmt.Sprintf(`{"methodConfig": [{
+		"name": [{"service": "aavecapalerts.notify.v1.Notifier"}],
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "0.5s",
+			"maxBackoff": "5s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]}`, maxAttempts)
+}
+
+func grpcTLSConfig(opts *GRPCTLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("grpc.tls.ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("grpc.tls.ca_file %s contains no PEM certificates", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, fmt.Errorf("grpc.tls.cert_file and key_file must be set together")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("grpc.tls client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Name implements Notifier.
+func (n *GRPCNotifier) Name() string {
+	return "grpc"
+}
+
+// Notify calls the Notify RPC and treats a rejected Ack as a failed delivery.
+func (n *GRPCNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	message, err := newGRPCEvent(event, n.pct)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+	ack, err := n.client.Notify(ctx, message)
+	if err != nil {
+		return fmt.Errorf("grpc notify %s: %w", n.address, err)
+	}
+	if !ack.GetAccepted() {
+		return fmt.Errorf("grpc server %s rejected the event: %s", n.address, ack.GetMessage())
+	}
+	return nil
+}
+
+// newGRPCEvent converts an event to its protobuf message, carrying the full JSON
+// payload alongside the common fields.
+func newGRPCEvent(event SupplyChangeEvent, pct PctFormat) (*notifypb.Event, error) {
+	payload := newEventPayload(event, pct)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal grpc payload: %w", err)
+	}
+	message := &notifypb.Event{
+		Type:              string(event.Type),
+		AssetName:         event.AssetName,
+		AssetAddress:      event.AssetAddress,
+		Labels:            event.Labels,
+		Holder:            event.Holder,
+		OldTotalSupply:    stringOrEmpty(payload.OldTotalSupply),
+		NewTotalSupply:    stringOrEmpty(payload.NewTotalSupply),
+		TargetTotalSupply: stringOrEmpty(payload.TargetTotalSupply),
+		Decimals:          uint32(event.Decimals),
+		ChangePct:         stringOrEmpty(payload.ChangePct),
+		Source:            event.Source,
+		BlockNumber:       event.BlockNumber,
+		TriggerReasons:    event.TriggerReasons,
+		IncidentId:        event.IncidentID,
+		IncidentStatus:    event.IncidentStatus,
+		IdempotencyKey:    IdempotencyKey(event),
+		ObservedAt:        timestamppb.New(event.ObservedAt),
+		PayloadJson:       string(body),
+	}
+	if !event.BlockTimestamp.IsZero() {
+		message.BlockTimestamp = timestamppb.New(event.BlockTimestamp)
+	}
+	return message, nil
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Close closes the connection; later deliveries fail.
+func (n *GRPCNotifier) Close() error {
+	var err error
+	n.closeOnce.Do(func() {
+		if closeErr := n.conn.Close(); closeErr != nil {
+			err = fmt.Errorf("close grpc connection: %w", closeErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"aave-cap-alerts/internal/notify/notifypb"
+)
+
+type fakeGRPCServer struct {
+	notifypb.UnimplementedNotifierServer
+	received chan *notifypb.Event
+	accept   bool
+}
+
+func (s *fakeGRPCServer) Notify(_ context.Context, event *notifypb.Event) (*notifypb.Ack, error) {
+	s.received <- event
+	if !s.accept {
+		return &notifypb.Ack{Message: "unknown asset"}, nil
+	}
+	return &notifypb.Ack{Accepted: true}, nil
+}
+
+func startGRPCServer(t *testing.T, accept bool) (string, *fakeGRPCServer) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGRPCServer{received: make(chan *notifypb.Event, 1), accept: accept}
+	server := grpc.NewServer()
+	notifypb.RegisterNotifierServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String(), fake
+}
+
+func TestGRPCNotifierDeliversEvent(t *testing.T) {
+	address, fake := startGRPCServer(t, true)
+	n, err := NewGRPCNotifier(GRPCOptions{Address: address, Timeout: 5 * time.Second, Pct: PctFormat{Decimals: 1, Rounding: RoundDown}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC",
+		Labels:         map[string]string{"team": "stablecoins"},
+		OldTotalSupply: big.NewInt(1000),
+		NewTotalSupply: big.NewInt(1100),
+		Change:         big.NewRat(1, 10),
+		Decimals:       6,
+		TriggerReasons: []string{"increased"},
+		ObservedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	got := <-fake.received
+	if got.Type != "supply_increase" || got.NewTotalSupply != "1100" || got.Labels["team"] != "stablecoins" ||
+		got.Decimals != 6 || !got.ObservedAt.AsTime().Equal(event.ObservedAt) || got.BlockTimestamp != nil {
+		t.Errorf("received %v", got)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(got.PayloadJson), &payload); err != nil || payload["new_total_supply"] != "1100" ||
+		payload["change_pct"] != "+10.0%" {
+		t.Errorf("payload_json = %s (%v)", got.PayloadJson, err)
+	}
+
+	// The connection is reused for later deliveries.
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatalf("second Notify: %v", err)
+	}
+	<-fake.received
+}
+
+func TestGRPCNotifierRejectedAck(t *testing.T) {
+	address, fake := startGRPCServer(t, false)
+	n, err := NewGRPCNotifier(GRPCOptions{Address: address})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+	if err := n.Notify(context.Background(), SupplyChangeEvent{Type: EventTargetReached}); err == nil {
+		t.Error("expected a rejected ack to fail the delivery")
+	}
+	<-fake.received
+}
+
+func TestNewGRPCNotifierValidatesOptions(t *testing.T) {
+	for _, opts := range []GRPCOptions{
+		{},
+		{Address: "localhost:1", MaxAttempts: 6},
+		{Address: "localhost:1", Timeout: -time.Second},
+		{Address: "localhost:1", TLS: &GRPCTLSOptions{CertFile: "client.pem"}},
+		{Address: "localhost:1", TLS: &GRPCTLSOptions{CAFile: "/nonexistent/ca.pem"}},
+	} {
+		if _, err := NewGRPCNotifier(opts); err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+	}
+}
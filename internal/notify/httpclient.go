@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent identifies notifier traffic when no User-Agent is configured.
+const DefaultUserAgent = "aave-cap-alerts"
+
+// headerTransport stamps every outbound request with the User-Agent and a fresh
+// X-Request-ID so receivers can correlate alerts in their logs.
+type headerTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	req.Header.Set("X-Request-ID", newRequestID())
+	return t.next.RoundTrip(req)
+}
+
+// newHTTPClient returns the HTTP client used by notifier constructors. An empty
+// userAgent sends DefaultUserAgent.
+func newHTTPClient(userAgent string) *http.Client {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: headerTransport{next: http.DefaultTransport, userAgent: userAgent},
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Incident statuses carried by events that belong to an incident.
+const (
+	IncidentOpen     = "open"
+	IncidentResolved = "resolved"
+)
+
+// IncidentTracker assigns a stable incident ID to each breach so the notifications that
+// open and resolve it can be tied together across channels. Incidents are keyed by asset
+// (and holder) plus trigger:
+//   - cap: opened by target_reached, resolved by any later event for the asset that
+//     reports a value back below its target (supply_decrease, for example).
+//   - level:<threshold>: opened when level_crossed reports the value above the level,
+//     resolved when it reports it back below.
+//
+// Every channel sees the same event, so the annotation chosen for an event is recorded
+// against its IdempotencyKey and reused for the other channels and for retries. With a
+// path, incident state is written to disk on every change and survives restarts.
+type IncidentTracker struct {
+	path string
+
+	mu        sync.Mutex
+	incidents map[string]*incident
+}
+
+type incident struct {
+	ID       string    `json:"id"`
+	Status   string    `json:"status"`
+	OpenedAt time.Time `json:"opened_at"`
+	// EventKey is the IdempotencyKey of the event that last changed the incident.
+	EventKey string `json:"event_key"`
+}
+
+// NewIncidentTracker builds a tracker, loading state from path when it is set and exists.
+func NewIncidentTracker(path string) (*IncidentTracker, error) {
+	t := &IncidentTracker{path: path, incidents: make(map[string]*incident)}
+	if path == "" {
+		return t, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read incident state: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.incidents); err != nil {
+		return nil, fmt.Errorf("parse incident state %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Wrap returns n with incident IDs attached to the events it delivers.
+func (t *IncidentTracker) Wrap(n Notifier) Notifier {
+	return &incidentNotifier{next: n, tracker: t}
+}
+
+// annotate sets IncidentID and IncidentStatus on events that open, resolve, or repeat an
+// incident and leaves other events unchanged.
+func (t *IncidentTracker) annotate(event SupplyChangeEvent) SupplyChangeEvent {
+	key, opens, resolves := incidentTrigger(event)
+	if key == "" {
+		return event
+	}
+	eventKey := IdempotencyKey(event)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current := t.incidents[key]
+	switch {
+	case current != nil && current.EventKey == eventKey:
+		// Another channel, or a retry, of the event that last changed the incident.
+	case opens && (current == nil || current.Status == IncidentResolved):
+		current = &incident{ID: "inc-" + newRequestID(), Status: IncidentOpen, OpenedAt: event.ObservedAt, EventKey: eventKey}
+		t.incidents[key] = current
+		t.persistLocked()
+	case opens:
+		// A repeat breach while the incident is still open.
+	case resolves && current != nil && current.Status == IncidentOpen:
+		current.Status = IncidentResolved
+		current.EventKey = eventKey
+		t.persistLocked()
+	default:
+		return event
+	}
+	event.IncidentID = current.ID
+	event.IncidentStatus = current.Status
+	return event
+}
+
+// SnapshotState returns the incident state for a state snapshot.
+func (t *IncidentTracker) SnapshotState() (json.RawMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return json.Marshal(t.incidents)
+}
+
+// RestoreState replaces the incident state with one saved by SnapshotState.
+func (t *IncidentTracker) RestoreState(data json.RawMessage) error {
+	incidents := make(map[string]*incident)
+	if err := json.Unmarshal(data, &incidents); err != nil {
+		return fmt.Errorf("parse incident state: %w", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.incidents = incidents
+	return nil
+}
+
+// incidentTrigger returns the incident key an event relates to, if any, and whether it
+// opens or resolves that incident.
+func incidentTrigger(event SupplyChangeEvent) (key string, opens, resolves bool) {
+	if event.NewTotalSupply == nil || event.TargetTotalSupply == nil {
+		return "", false, false
+	}
+	asset := strings.ToLower(event.AssetAddress)
+	if event.Holder != "" {
+		asset += "|" + strings.ToLower(event.Holder)
+	}
+	below := event.NewTotalSupply.Cmp(event.TargetTotalSupply) < 0
+	if event.Type == EventLevelCrossed {
+		return asset + "|level:" + event.TargetTotalSupply.String(), !below, below
+	}
+	// A downward target is breached at or below it and resolved once back above.
+	safe := below
+	if event.TargetDirection == TargetDirectionBelow {
+		safe = event.NewTotalSupply.Cmp(event.TargetTotalSupply) > 0
+	}
+	return asset + "|cap", event.Type == EventTargetReached, safe
+}
+
+// persistLocked writes the incident state to the tracker's path. Write errors are
+// logged and do not stop delivery.
+func (t *IncidentTracker) persistLocked() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.Marshal(t.incidents)
+	if err != nil {
+		logger.Errorf("incident state: encode: %v", err)
+		return
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		logger.Errorf("incident state: write %s: %v", tmp, err)
+		return
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		logger.Errorf("incident state: replace %s: %v", t.path, err)
+	}
+}
+
+// incidentNotifier decorates a notifier with the tracker's incident IDs.
+type incidentNotifier struct {
+	next    Notifier
+	tracker *IncidentTracker
+}
+
+// Name implements Notifier with the wrapped notifier's name, so routes and selectors
+// still apply.
+func (n *incidentNotifier) Name() string {
+	return n.next.Name()
+}
+
+// Notify annotates the event and delivers it through the wrapped notifier.
+func (n *incidentNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	return n.next.Notify(ctx, n.tracker.annotate(event))
+}
+
+// Close closes the wrapped notifier when it holds resources.
+func (n *incidentNotifier) Close() error {
+	if closer, ok := n.next.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
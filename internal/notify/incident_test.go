@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type recordingNotifier struct {
+	events []SupplyChangeEvent
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(_ context.Context, event SupplyChangeEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestIncidentLifecycleAcrossChannels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	tracker, err := NewIncidentTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := &recordingNotifier{}, &recordingNotifier{}
+	channels := []Notifier{tracker.Wrap(first), tracker.Wrap(second)}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := func(eventType EventType, supply int64) SupplyChangeEvent {
+		at = at.Add(time.Minute)
+		return SupplyChangeEvent{
+			Type:              eventType,
+			AssetAddress:      "0xabc",
+			NewTotalSupply:    big.NewInt(supply),
+			TargetTotalSupply: big.NewInt(1000),
+			ObservedAt:        at,
+		}
+	}
+	for _, e := range []SupplyChangeEvent{
+		event(EventTargetReached, 1000),
+		event(EventSupplyIncrease, 1100),
+		event(EventSupplyDecrease, 900),
+		event(EventTargetReached, 1001),
+	} {
+		for _, n := range channels {
+			if err := n.Notify(context.Background(), e); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for i, want := range []string{IncidentOpen, "", IncidentResolved, IncidentOpen} {
+		a, b := first.events[i], second.events[i]
+		if a.IncidentStatus != want || a.IncidentID != b.IncidentID || a.IncidentStatus != b.IncidentStatus {
+			t.Fatalf("event %d: got %q/%q and %q/%q, want status %q on both channels",
+				i, a.IncidentID, a.IncidentStatus, b.IncidentID, b.IncidentStatus, want)
+		}
+	}
+	if first.events[0].IncidentID != first.events[2].IncidentID {
+		t.Errorf("resolve carries %q, want opening ID %q", first.events[2].IncidentID, first.events[0].IncidentID)
+	}
+	if first.events[3].IncidentID == first.events[0].IncidentID {
+		t.Errorf("new breach reused resolved incident %q", first.events[0].IncidentID)
+	}
+
+	restored, err := NewIncidentTracker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved := restored.annotate(event(EventSupplyDecrease, 500))
+	if resolved.IncidentID != first.events[3].IncidentID || resolved.IncidentStatus != IncidentResolved {
+		t.Errorf("after reload got %q/%q, want %q resolved", resolved.IncidentID, resolved.IncidentStatus, first.events[3].IncidentID)
+	}
+}
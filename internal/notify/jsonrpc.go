@@ -6,32 +6,154 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// JSONRPCFormat selects the request body the JSON-RPC notifier sends.
+type JSONRPCFormat string
+
+const (
+	// JSONRPCFormatFlat posts {"message": ...}; the original, non-JSON-RPC format.
+	JSONRPCFormatFlat JSONRPCFormat = "flat"
+	// JSONRPCFormatV2 posts a JSON-RPC 2.0 request whose params carry the message and event.
+	JSONRPCFormatV2 JSONRPCFormat = "jsonrpc2"
+	// JSONRPCFormatCloudEvents posts a structured-mode CloudEvents 1.0 JSON envelope.
+	JSONRPCFormatCloudEvents JSONRPCFormat = "cloudevents"
+)
+
+// cloudEventTypePrefix namespaces CloudEvents types, e.g. "io.aave-cap-alerts.supply_increase".
+const cloudEventTypePrefix = "io.aave-cap-alerts."
+
+// DefaultJSONRPCMethod is the method name used in jsonrpc2 mode when none is configured.
+const DefaultJSONRPCMethod = "aave_capAlert"
+
+// ParseJSONRPCFormat validates a configured body format; empty selects flat.
+func ParseJSONRPCFormat(name string) (JSONRPCFormat, error) {
+	switch f := JSONRPCFormat(name); f {
+	case "":
+		return JSONRPCFormatFlat, nil
+	case JSONRPCFormatFlat, JSONRPCFormatV2, JSONRPCFormatCloudEvents:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (want flat, jsonrpc2, or cloudevents)", name)
+	}
+}
+
 // JSONRPCNotifier delivers events to a custom HTTP endpoint.
 type JSONRPCNotifier struct {
 	url        string
+	format     JSONRPCFormat
+	method     string
+	renderer   *Renderer
 	httpClient *http.Client
+	signer     *requestSigner
 }
 
-// NewJSONRPCNotifier builds a notifier targeting the supplied endpoint.
-func NewJSONRPCNotifier(url string) *JSONRPCNotifier {
+// NewJSONRPCNotifier builds a notifier targeting the supplied endpoint. Unless a template
+// applies or the renderer asks for more than compact verbosity, the message is a one-line
+// summary of the supply change. method is only used in jsonrpc2 mode and defaults to
+// DefaultJSONRPCMethod. An empty userAgent sends DefaultUserAgent.
+func NewJSONRPCNotifier(url string, format JSONRPCFormat, method, userAgent string, renderer *Renderer) *JSONRPCNotifier {
+	if method == "" {
+		method = DefaultJSONRPCMethod
+	}
 	return &JSONRPCNotifier{
 		url:        url,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		format:     format,
+		method:     method,
+		renderer:   renderer,
+		httpClient: newHTTPClient(userAgent),
+	}
+}
+
+// WithSigningSecret returns a copy of the notifier that signs each request body with
+// HMAC-SHA256 under secret, in header (DefaultSignatureHeader when empty) as
+// "sha256=<hex>", and sends X-Timestamp. signTimestamp binds X-Timestamp into the
+// signature as well. An empty secret turns signing off.
+func (j *JSONRPCNotifier) WithSigningSecret(secret, header string, signTimestamp bool) *JSONRPCNotifier {
+	clone := *j
+	clone.signer = newRequestSigner(secret, header, signTimestamp)
+	return &clone
+}
+
+// jsonRPCRequest is a JSON-RPC 2.0 request. The id is the event's idempotency key, so a
+// retried delivery reuses it.
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  jsonRPCParams `json:"params"`
+	ID      string        `json:"id"`
+}
+
+type jsonRPCParams struct {
+	Message string       `json:"message"`
+	Event   eventPayload `json:"event"`
+}
+
+// cloudEvent is a CloudEvents 1.0 envelope in structured JSON mode. The id is the event's
+// idempotency key, so CloudEvents consumers deduplicate retries on (source, id).
+type cloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	Type            string         `json:"type"`
+	Source          string         `json:"source"`
+	ID              string         `json:"id"`
+	Time            time.Time      `json:"time"`
+	Subject         string         `json:"subject,omitempty"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            cloudEventData `json:"data"`
+}
+
+type cloudEventData struct {
+	eventPayload
+	Message string `json:"message"`
+}
+
+func newCloudEvent(event SupplyChangeEvent, message string, pct PctFormat) cloudEvent {
+	return cloudEvent{
+		SpecVersion:     "1.0",
+		Type:            cloudEventTypePrefix + string(event.Type),
+		Source:          "/aave-cap-alerts/assets/" + strings.ToLower(event.AssetAddress),
+		ID:              IdempotencyKey(event),
+		Time:            event.ObservedAt.UTC(),
+		Subject:         event.AssetName,
+		DataContentType: "application/json",
+		Data:            cloudEventData{eventPayload: newEventPayload(event, pct), Message: message},
 	}
 }
 
-// Notify posts a minimal JSON body with a single message field required by the downstream endpoint.
+// Name implements Notifier.
+func (j *JSONRPCNotifier) Name() string {
+	return "json_rpc"
+}
+
+// Notify posts the message to the endpoint as a flat {"message": ...} body, a JSON-RPC 2.0
+// request, or a CloudEvents envelope depending on the configured format.
 func (j *JSONRPCNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
-	oldValue := "n/a"
-	if event.OldTotalSupply != nil {
-		oldValue = event.OldTotalSupply.String()
+	message := summaryMessage(event, j.renderer.displayOpts())
+	if j.renderer.hasTemplate(event) || (j.renderer != nil && j.renderer.display.verbosity != VerbosityCompact) {
+		rendered, err := j.renderer.Render(event)
+		if err != nil {
+			return err
+		}
+		message = rendered
 	}
 
-	body := map[string]string{
-		"message": fmt.Sprintf("asset %s total supply changed: %s -> %s", event.AssetName, oldValue, event.NewTotalSupply.String()),
+	var body any = map[string]string{
+		"message": message,
+	}
+	contentType := "application/json"
+	switch j.format {
+	case JSONRPCFormatV2:
+		body = jsonRPCRequest{
+			JSONRPC: "2.0",
+			Method:  j.method,
+			Params:  jsonRPCParams{Message: message, Event: newEventPayload(event, j.renderer.pctFormat())},
+			ID:      IdempotencyKey(event),
+		}
+	case JSONRPCFormatCloudEvents:
+		body = newCloudEvent(event, message, j.renderer.pctFormat())
+		contentType = "application/cloudevents+json"
 	}
 
 	raw, err := json.Marshal(body)
@@ -43,7 +165,12 @@ func (j *JSONRPCNotifier) Notify(ctx context.Context, event SupplyChangeEvent) e
 	if err != nil {
 		return fmt.Errorf("build post request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Idempotency-Key", IdempotencyKey(event))
+	if event.IncidentID != "" {
+		req.Header.Set("X-Incident-ID", event.IncidentID)
+	}
+	j.signer.sign(req, raw)
 
 	resp, err := j.httpClient.Do(req)
 	if err != nil {
@@ -57,3 +184,33 @@ func (j *JSONRPCNotifier) Notify(ctx context.Context, event SupplyChangeEvent) e
 
 	return nil
 }
+
+// summaryMessage is the one-line compact message. Amounts follow amount_format and
+// show_raw like every other built-in message.
+func summaryMessage(event SupplyChangeEvent, opts displayOptions) string {
+	switch event.Type {
+	case EventFirstObservation:
+		return fmt.Sprintf("now watching asset %s, current total supply %s", event.AssetName, displayAmount(event.NewTotalSupply, event.Decimals, opts))
+	case EventAlertRateLimited, EventDecimalsChanged, EventLevelCrossed, EventDebtCeiling, EventSupplyATH, EventCapETA, EventRateThreshold, EventPairDivergence, EventConcentration, EventAggregateThreshold, EventEModeChanged, EventCapLevel:
+		return fmt.Sprintf("asset %s: %s", event.AssetName, strings.Join(event.TriggerReasons, "; "))
+	case EventMonitoringStarted:
+		return fmt.Sprintf("%s %s", event.AssetName, strings.Join(event.TriggerReasons, "; "))
+	case EventShutdownSummary:
+		return fmt.Sprintf("%s shutting down: %s", event.AssetName, strings.Join(event.TriggerReasons, "; "))
+	case EventProtocolPause:
+		return fmt.Sprintf("pool %s pause state changed: %s", event.AssetAddress, strings.Join(event.TriggerReasons, "; "))
+	case EventTreasuryThreshold:
+		return fmt.Sprintf("asset %s accruedToTreasury reached %s", event.AssetName, event.AccruedToTreasury.String())
+	case EventIndexJump:
+		return fmt.Sprintf("asset %s liquidity index jumped: %s -> %s", event.AssetName, event.OldLiquidityIndex.String(), event.NewLiquidityIndex.String())
+	}
+	message := fmt.Sprintf("asset %s total supply changed: %s -> %s",
+		event.AssetName,
+		displayAmount(event.OldTotalSupply, event.Decimals, opts),
+		displayAmount(event.NewTotalSupply, event.Decimals, opts),
+	)
+	if event.Change != nil {
+		message += ", change " + formatChange(event.Change, opts.pct)
+	}
+	return message
+}
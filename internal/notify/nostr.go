@@ -0,0 +1,338 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/gorilla/websocket"
+)
+
+// nostrTextNote is the kind of a NIP-01 short text note.
+const nostrTextNote = 1
+
+// nostrRelayTimeout bounds connecting to a relay and waiting for its OK, within the
+// caller's context.
+const nostrRelayTimeout = 10 * time.Second
+
+// NostrNotifier publishes each event as a signed text note (NIP-01) to the first relay
+// that accepts it. Relays are tried in order; one that cannot be reached, rejects the
+// note, or does not acknowledge it in time is skipped for the next. A connection is
+// opened for each note, so relays that drop idle clients need no reconnect handling.
+type NostrNotifier struct {
+	key       *btcec.PrivateKey
+	pubkey    string
+	relays    []string
+	renderer  *Renderer
+	userAgent string
+	now       func() time.Time
+}
+
+// NewNostrNotifier builds a notifier that signs with privateKey, given as 64 hex
+// characters or a NIP-19 nsec string, and publishes to relays (ws:// or wss:// URLs).
+// An empty userAgent sends DefaultUserAgent.
+func NewNostrNotifier(privateKey string, relays []string, userAgent string, renderer *Renderer) (*NostrNotifier, error) {
+	secret, err := parseNostrKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("nostr.private_key: %w", err)
+	}
+	if len(relays) == 0 {
+		return nil, fmt.Errorf("nostr.relays must list at least one relay")
+	}
+	for _, relay := range relays {
+		if !strings.HasPrefix(relay, "wss://") && !strings.HasPrefix(relay, "ws://") {
+			return nil, fmt.Errorf("nostr relay %q must be a ws:// or wss:// URL", relay)
+		}
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	key, pub := btcec.PrivKeyFromBytes(secret)
+	return &NostrNotifier{
+		key:       key,
+		pubkey:    hex.EncodeToString(schnorr.SerializePubKey(pub)),
+		relays:    relays,
+		renderer:  renderer,
+		userAgent: userAgent,
+		now:       time.Now,
+	}, nil
+}
+
+// Name implements Notifier.
+func (n *NostrNotifier) Name() string {
+	return "nostr"
+}
+
+// nostrEvent is a NIP-01 event as sent to relays.
+type nostrEvent struct {
+	ID        string     `json:"id"`
+	PubKey    string     `json:"pubkey"`
+	CreatedAt int64      `json:"created_at"`
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig"`
+}
+
+// Notify signs the rendered message as a text note and publishes it.
+func (n *NostrNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	content, err := n.renderer.Render(event)
+	if err != nil {
+		return err
+	}
+	note, err := n.sign(content, [][]string{{"t", "aave"}, {"t", string(event.Type)}})
+	if err != nil {
+		return err
+	}
+	frame, err := json.Marshal([]any{"EVENT", note})
+	if err != nil {
+		return fmt.Errorf("marshal nostr event: %w", err)
+	}
+
+	var errs []error
+	for _, relay := range n.relays {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := n.publish(ctx, relay, note.ID, frame)
+		if err == nil {
+			return nil
+		}
+		logger.Warnf("nostr relay %s: %v", relay, err)
+		errs = append(errs, fmt.Errorf("%s: %w", relay, err))
+	}
+	return fmt.Errorf("nostr: no relay accepted the note: %w", errors.Join(errs...))
+}
+
+// sign builds the text note and signs its ID with BIP-340 Schnorr.
+func (n *NostrNotifier) sign(content string, tags [][]string) (nostrEvent, error) {
+	note := nostrEvent{
+		PubKey:    n.pubkey,
+		CreatedAt: n.now().Unix(),
+		Kind:      nostrTextNote,
+		Tags:      tags,
+		Content:   content,
+	}
+	id := nostrEventID(note)
+	sig, err := schnorr.Sign(n.key, id[:])
+	if err != nil {
+		return nostrEvent{}, fmt.Errorf("sign nostr event: %w", err)
+	}
+	note.ID = hex.EncodeToString(id[:])
+	note.Sig = hex.EncodeToString(sig.Serialize())
+	return note, nil
+}
+
+// publish sends one EVENT frame to relay and waits for the relay's OK for id.
+func (n *NostrNotifier) publish(ctx context.Context, relay, id string, frame []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, nostrRelayTimeout)
+	defer cancel()
+
+	header := http.Header{"User-Agent": []string{n.userAgent}}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, relay, header)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+	// Closing on cancellation unblocks the read below.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("no OK before timeout: %w", ctx.Err())
+			}
+			return fmt.Errorf("read: %w", err)
+		}
+		var msg []json.RawMessage
+		if json.Unmarshal(data, &msg) != nil || len(msg) < 2 {
+			continue
+		}
+		var label, eventID string
+		if json.Unmarshal(msg[0], &label) != nil || label != "OK" || json.Unmarshal(msg[1], &eventID) != nil || eventID != id {
+			// NOTICE and other frames are not the answer to this note.
+			continue
+		}
+		var accepted bool
+		var reason string
+		if len(msg) > 2 {
+			_ = json.Unmarshal(msg[2], &accepted)
+		}
+		if len(msg) > 3 {
+			_ = json.Unmarshal(msg[3], &reason)
+		}
+		if !accepted {
+			return fmt.Errorf("rejected: %s", reason)
+		}
+		return nil
+	}
+}
+
+// nostrEventID is the SHA-256 of the event's canonical serialization,
+// [0,pubkey,created_at,kind,tags,content].
+func nostrEventID(note nostrEvent) [32]byte {
+	var b strings.Builder
+	b.WriteString(`[0,`)
+	writeNostrString(&b, note.PubKey)
+	fmt.Fprintf(&b, ",%d,%d,[", note.CreatedAt, note.Kind)
+	for i, tag := range note.Tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('[')
+		for j, value := range tag {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			writeNostrString(&b, value)
+		}
+		b.WriteByte(']')
+	}
+	b.WriteString("],")
+	writeNostrString(&b, note.Content)
+	b.WriteByte(']')
+	return sha256.Sum256([]byte(b.String()))
+}
+
+// writeNostrString writes s as a JSON string escaped the way NIP-01 requires for the
+// ID: only the quote, backslash and the \n, \r, \t, \b, \f controls are escaped, and
+// every other character is written verbatim. encoding/json escapes more (such as <, >,
+// & and U+2028), which would change the hash.
+func writeNostrString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case utf8.RuneError:
+			// Invalid UTF-8 is sent as U+FFFD by encoding/json, so hash the same.
+			b.WriteRune(utf8.RuneError)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+}
+
+// parseNostrKey decodes a private key given as hex or as a NIP-19 nsec string.
+func parseNostrKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	var key []byte
+	if strings.HasPrefix(strings.ToLower(s), "nsec1") {
+		hrp, data, err := decodeBech32(s)
+		if err != nil {
+			return nil, err
+		}
+		if hrp != "nsec" {
+			return nil, fmt.Errorf("expected an nsec key, got %s", hrp)
+		}
+		key = data
+	} else {
+		decoded, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("must be 64 hex characters or an nsec string")
+		}
+		key = decoded
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("must be 32 bytes, got %d", len(key))
+	}
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(key); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("is not a valid secp256k1 private key")
+	}
+	return key, nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// decodeBech32 decodes a BIP-173 bech32 string into its human-readable part and its data
+// regrouped from 5-bit to 8-bit bytes.
+func decodeBech32(s string) (string, []byte, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return "", nil, fmt.Errorf("bech32 string mixes upper and lower case")
+	}
+	s = strings.ToLower(s)
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+7 > len(s) {
+		return "", nil, fmt.Errorf("malformed bech32 string")
+	}
+	hrp := s[:sep]
+	values := make([]byte, 0, len(s)-sep-1)
+	for _, c := range s[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 character %q", c)
+		}
+		values = append(values, byte(v))
+	}
+
+	expanded := make([]byte, 0, 2*len(hrp)+1+len(values))
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	if bech32Polymod(append(expanded, values...)) != 1 {
+		return "", nil, fmt.Errorf("invalid bech32 checksum")
+	}
+
+	// Regroup the 5-bit values, minus the 6-value checksum, into bytes.
+	var out []byte
+	var acc, bits uint
+	for _, v := range values[:len(values)-6] {
+		acc = acc<<5 | uint(v)
+		bits += 5
+		for bits >= 8 {
+			bits -= 8
+			out = append(out, byte(acc>>bits))
+		}
+	}
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return "", nil, fmt.Errorf("invalid bech32 padding")
+	}
+	return hrp, out, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
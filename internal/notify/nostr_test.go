@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/gorilla/websocket"
+)
+
+// BIP-340 test vector 0.
+const (
+	testNostrKey    = "0000000000000000000000000000000000000000000000000000000000000003"
+	testNostrPubKey = "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"
+)
+
+func TestParseNostrKey(t *testing.T) {
+	key, err := parseNostrKey("nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key); got != "67dea2ed018072d675f5415ecfaed7d2597555e202d85b3d65ea4e58d2d92ffa" {
+		t.Errorf("nsec decoded to %s", got)
+	}
+	for _, bad := range []string{
+		"",
+		"nsec1vl029mgpspedva04g90vltkh6fvh240zqtv9k0t9af8935ke9laqsnlfe6", // checksum
+		"npub10elfcs4fr0l0r8af98jlmgdh9c8tcxjvz9qkw038js35mp4dma8qzvjptg",
+		strings.Repeat("0", 64),
+		strings.Repeat("f", 64), // above the curve order
+	} {
+		if _, err := parseNostrKey(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestNostrEventIDAndSignature(t *testing.T) {
+	n, err := NewNostrNotifier(testNostrKey, []string{"wss://relay.example"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.pubkey != testNostrPubKey {
+		t.Fatalf("pubkey = %s, want %s", n.pubkey, testNostrPubKey)
+	}
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	note, err := n.sign("USDC <supply> & \"cap\"\n done", [][]string{{"t", "aave"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical := `[0,"` + testNostrPubKey + `",1700000000,1,[["t","aave"]],"USDC <supply> & \"cap\"\n` + " " + `done"]`
+	want := sha256.Sum256([]byte(canonical))
+	if note.ID != hex.EncodeToString(want[:]) {
+		t.Fatalf("id = %s, want the hash of %s", note.ID, canonical)
+	}
+
+	sigBytes, _ := hex.DecodeString(note.Sig)
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, _ := hex.DecodeString(note.PubKey)
+	pub, err := schnorr.ParsePubKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(want[:], pub) {
+		t.Fatal("signature does not verify")
+	}
+}
+
+// fakeRelay answers every EVENT with an OK frame carrying accept.
+func fakeRelay(t *testing.T, accept bool, received chan<- nostrEvent) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var frame []json.RawMessage
+		var note nostrEvent
+		if json.Unmarshal(data, &frame) != nil || len(frame) != 2 || json.Unmarshal(frame[1], &note) != nil {
+			return
+		}
+		if received != nil {
+			received <- note
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`["NOTICE","hello"]`))
+		reply, _ := json.Marshal([]any{"OK", note.ID, accept, "blocked: test"})
+		conn.WriteMessage(websocket.TextMessage, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestNostrNotifierTriesNextRelay(t *testing.T) {
+	received := make(chan nostrEvent, 1)
+	rejecting := fakeRelay(t, false, nil)
+	accepting := fakeRelay(t, true, received)
+	n, err := NewNostrNotifier(testNostrKey, []string{"ws://127.0.0.1:1", rejecting, accepting}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := SupplyChangeEvent{Type: EventSupplyIncrease, AssetName: "USDC", TriggerReasons: []string{"increase 5%"}}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	note := <-received
+	if note.Kind != nostrTextNote || !strings.Contains(note.Content, "USDC") || note.PubKey != testNostrPubKey {
+		t.Fatalf("note = %+v", note)
+	}
+
+	only, _ := NewNostrNotifier(testNostrKey, []string{rejecting}, "", nil)
+	if err := only.Notify(context.Background(), event); err == nil || !strings.Contains(err.Error(), "blocked: test") {
+		t.Fatalf("err = %v, want the relay's rejection", err)
+	}
+}
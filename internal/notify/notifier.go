@@ -1,8 +1,16 @@
 package notify
 
-import "context"
+import (
+	"context"
+
+	"aave-cap-alerts/internal/logging"
+)
+
+var logger = logging.New("notify")
 
 // Notifier delivers events to a downstream integration.
 type Notifier interface {
+	// Name identifies the notifier in routing rules and logs.
+	Name() string
 	Notify(ctx context.Context, event SupplyChangeEvent) error
 }
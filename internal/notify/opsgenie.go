@@ -0,0 +1,241 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultOpsGenieURL is the OpsGenie Alert API endpoint for US-hosted accounts.
+const DefaultOpsGenieURL = "https://api.opsgenie.com/v2/alerts"
+
+// opsGenieMessageLimit is the maximum alert message length, in characters, accepted by
+// OpsGenie.
+const opsGenieMessageLimit = 130
+
+// OpsGenieNotifier creates OpsGenie alerts. Alerts use a per-asset, per-event-type alias
+// so repeated events of one kind for the same asset are deduplicated into one open alert.
+// Events that belong to an incident use the incident ID instead, and the event that
+// resolves the incident closes its alert.
+type OpsGenieNotifier struct {
+	apiKey     string
+	apiURL     string
+	priority   string
+	eventTypes map[EventType]struct{}
+	renderer   *Renderer
+	httpClient *http.Client
+}
+
+// NewOpsGenieNotifier builds an OpsGenie notifier. priority must be P1-P5. An empty apiURL
+// uses DefaultOpsGenieURL; an empty eventTypes list forwards every event type. An empty
+// userAgent sends DefaultUserAgent.
+func NewOpsGenieNotifier(apiKey, apiURL, priority string, eventTypes []EventType, userAgent string, renderer *Renderer) (*OpsGenieNotifier, error) {
+	switch priority {
+	case "":
+		priority = "P3"
+	case "P1", "P2", "P3", "P4", "P5":
+	default:
+		return nil, fmt.Errorf("opsgenie priority must be one of P1-P5, got %q", priority)
+	}
+	if apiURL == "" {
+		apiURL = DefaultOpsGenieURL
+	}
+
+	types := make(map[EventType]struct{}, len(eventTypes))
+	for _, t := range eventTypes {
+		types[t] = struct{}{}
+	}
+
+	return &OpsGenieNotifier{
+		apiKey:     apiKey,
+		apiURL:     apiURL,
+		priority:   priority,
+		eventTypes: types,
+		renderer:   renderer,
+		httpClient: newHTTPClient(userAgent),
+	}, nil
+}
+
+// Name implements Notifier.
+func (o *OpsGenieNotifier) Name() string {
+	return "opsgenie"
+}
+
+// Notify creates an alert for the event unless its type is filtered out, or closes the
+// incident's alert for an event that resolves one. Resolutions are sent whatever their
+// type, since they are usually not the type that opened the alert.
+func (o *OpsGenieNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	if event.IncidentStatus == IncidentResolved {
+		return o.closeAlert(ctx, event)
+	}
+	if len(o.eventTypes) > 0 {
+		if _, ok := o.eventTypes[event.Type]; !ok {
+			return nil
+		}
+	}
+
+	description, err := o.renderer.Render(event)
+	if err != nil {
+		return err
+	}
+
+	message, err := o.renderer.Subject(event)
+	if err != nil {
+		return err
+	}
+	message = truncateRunes(message, opsGenieMessageLimit)
+
+	details := map[string]string{
+		"asset_address": event.AssetAddress,
+		"event_type":    string(event.Type),
+	}
+	if event.ExplorerURL != "" {
+		details["explorer_url"] = event.ExplorerURL
+	}
+	if event.DebtCeiling != nil {
+		details["isolation_debt"] = event.IsolationDebt.String()
+		details["debt_ceiling"] = event.DebtCeiling.String()
+	}
+	if event.NewEModeCategory != nil {
+		details["old_emode_category"] = strconv.Itoa(int(*event.OldEModeCategory))
+		details["new_emode_category"] = strconv.Itoa(int(*event.NewEModeCategory))
+	}
+	if event.PreviousATH != nil {
+		details["previous_ath"] = event.PreviousATH.String()
+		details["previous_ath_at"] = event.PreviousATHAt.UTC().Format(time.RFC3339)
+	}
+	if event.PairSupply != nil {
+		details["pair_address"] = event.PairAddress
+		details["pair_supply"] = event.PairSupply.String()
+		details["pair_supply_formatted"] = formatAmount(event.PairSupply, event.PairDecimals)
+	}
+	if event.Divergence != nil {
+		details["divergence_pct"] = formatPct(event.Divergence, o.renderer.pctFormat())
+	}
+	if event.WrapperSupply != nil {
+		details["wrapper_supply"] = event.WrapperSupply.String()
+		details["wrapper_supply_formatted"] = formatAmount(event.WrapperSupply, event.WrapperDecimals)
+	}
+	if event.Concentration != nil {
+		details["concentration_pct"] = formatPct(event.Concentration, o.renderer.pctFormat())
+		details["top_holders"] = strings.Join(event.TopHolders, ",")
+	}
+	if event.LiquidityRate != nil {
+		details["liquidity_rate_pct"] = formatPct(event.LiquidityRate, o.renderer.pctFormat())
+	}
+	if event.VariableBorrowRate != nil {
+		details["variable_borrow_rate_pct"] = formatPct(event.VariableBorrowRate, o.renderer.pctFormat())
+	}
+	if event.CapETA > 0 {
+		details["cap_eta_seconds"] = strconv.FormatInt(int64(event.CapETA/time.Second), 10)
+	}
+	if event.Change != nil {
+		details["change_pct"] = formatChange(event.Change, o.renderer.pctFormat())
+	}
+	if event.IncidentID != "" {
+		details["incident_id"] = event.IncidentID
+		details["incident_status"] = event.IncidentStatus
+	}
+	if event.ReferenceMode != "" {
+		details["reference_mode"] = event.ReferenceMode
+	}
+	if event.AccruedToTreasury != nil {
+		details["accrued_to_treasury"] = event.AccruedToTreasury.String()
+	}
+	for key, value := range map[string]*big.Int{
+		"new_total_supply":    event.NewTotalSupply,
+		"old_total_supply":    event.OldTotalSupply,
+		"target_total_supply": event.TargetTotalSupply,
+		"reference_supply":    event.ReferenceSupply,
+	} {
+		if value != nil {
+			details[key] = value.String()
+			details[key+"_formatted"] = formatAmount(value, event.Decimals)
+		}
+	}
+
+	body := map[string]any{
+		"message":     message,
+		"alias":       opsGenieAlias(event),
+		"description": description,
+		"priority":    o.priority,
+		"source":      "aave-cap-alerts",
+		"tags":        []string{"aave", string(event.Type)},
+		"details":     details,
+	}
+
+	return o.post(ctx, o.apiURL, body)
+}
+
+// closeAlert closes the alert opened for the event's incident, noting the rendered
+// message as the reason.
+func (o *OpsGenieNotifier) closeAlert(ctx context.Context, event SupplyChangeEvent) error {
+	note, err := o.renderer.Render(event)
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(o.apiURL, "/") + "/" + url.PathEscape(opsGenieAlias(event)) + "/close?identifierType=alias"
+	return o.post(ctx, endpoint, map[string]any{
+		"source": "aave-cap-alerts",
+		"note":   note,
+	})
+}
+
+// post sends body as JSON to an OpsGenie Alert API endpoint.
+func (o *OpsGenieNotifier) post(ctx context.Context, endpoint string, body map[string]any) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal opsgenie payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("build opsgenie request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send opsgenie request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("opsgenie returned status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// opsGenieAlias keys alerts by asset (and holder, for balance watchers) and event type so
+// OpsGenie deduplicates repeat events without merging different triggers into one alert.
+// An incident's events share its ID instead, so the resolving event closes the alert the
+// breach opened.
+func opsGenieAlias(event SupplyChangeEvent) string {
+	if event.IncidentID != "" {
+		return "aave-cap-alerts:" + event.IncidentID
+	}
+	alias := "aave-cap-alerts:" + strings.ToLower(event.AssetAddress)
+	if event.Holder != "" {
+		alias += ":" + strings.ToLower(event.Holder)
+	}
+	return alias + ":" + string(event.Type)
+}
+
+// truncateRunes shortens s to at most limit characters, ending in "...", without
+// splitting a multi-byte character.
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	short := "🚨 USDe: target reached"
+	if got := truncateRunes(short, opsGenieMessageLimit); got != short {
+		t.Errorf("short message changed: %q", got)
+	}
+
+	long := strings.Repeat("🚨", opsGenieMessageLimit+10)
+	got := truncateRunes(long, opsGenieMessageLimit)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncated message is not valid UTF-8: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != opsGenieMessageLimit {
+		t.Errorf("truncated length = %d runes, want %d", n, opsGenieMessageLimit)
+	}
+	if !strings.HasSuffix(got, "...") {
+		t.Errorf("truncated message missing ellipsis: %q", got)
+	}
+}
+
+func TestOpsGenieAliasSeparatesEventTypes(t *testing.T) {
+	increase := SupplyChangeEvent{Type: EventSupplyIncrease, AssetAddress: "0xABC"}
+	reached := SupplyChangeEvent{Type: EventTargetReached, AssetAddress: "0xabc"}
+	if got, want := opsGenieAlias(increase), "aave-cap-alerts:0xabc:supply_increase"; got != want {
+		t.Errorf("alias = %q, want %q", got, want)
+	}
+	if opsGenieAlias(increase) == opsGenieAlias(reached) {
+		t.Error("target_reached shares an alias with supply_increase")
+	}
+}
+
+func TestOpsGenieClosesResolvedIncidents(t *testing.T) {
+	type request struct {
+		uri  string
+		body map[string]any
+	}
+	requests := make(chan request, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests <- request{uri: r.URL.RequestURI(), body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	// Filtering on target_reached must not stop the supply_decrease that resolves it.
+	n, err := NewOpsGenieNotifier("key", server.URL+"/v2/alerts", "", []EventType{EventTargetReached}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened := SupplyChangeEvent{Type: EventTargetReached, AssetAddress: "0xabc", IncidentID: "inc-1", IncidentStatus: IncidentOpen}
+	resolved := SupplyChangeEvent{Type: EventSupplyDecrease, AssetAddress: "0xabc", IncidentID: "inc-1", IncidentStatus: IncidentResolved}
+	for _, event := range []SupplyChangeEvent{opened, resolved} {
+		if err := n.Notify(context.Background(), event); err != nil {
+			t.Fatalf("Notify %s: %v", event.Type, err)
+		}
+	}
+
+	create := <-requests
+	if create.uri != "/v2/alerts" || create.body["alias"] != "aave-cap-alerts:inc-1" {
+		t.Errorf("create request = %s with alias %v", create.uri, create.body["alias"])
+	}
+	if got := <-requests; got.uri != "/v2/alerts/aave-cap-alerts:inc-1/close?identifierType=alias" {
+		t.Errorf("resolve request = %s, want the close endpoint", got.uri)
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode controls how percentages are rounded for display.
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds ties away from zero (94.995% -> 95.00%).
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds ties to the nearest even digit (banker's rounding).
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown truncates toward zero, so a display never overstates a value.
+	RoundDown RoundingMode = "down"
+)
+
+// PctFormat configures how percentages are displayed. Only the display is rounded;
+// trigger comparisons always use exact values.
+type PctFormat struct {
+	Decimals int
+	Rounding RoundingMode
+}
+
+// DefaultPctFormat shows two decimal places rounded half-up.
+var DefaultPctFormat = PctFormat{Decimals: 2, Rounding: RoundHalfUp}
+
+// ParsePctFormat validates configured percentage settings. A nil decimals pointer and an
+// empty rounding mode keep the defaults.
+func ParsePctFormat(decimals *int, rounding string) (PctFormat, error) {
+	f := DefaultPctFormat
+	if decimals != nil {
+		if *decimals < 0 || *decimals > 18 {
+			return f, fmt.Errorf("percent decimals must be between 0 and 18")
+		}
+		f.Decimals = *decimals
+	}
+	switch RoundingMode(rounding) {
+	case "":
+	case RoundHalfUp, RoundHalfEven, RoundDown:
+		f.Rounding = RoundingMode(rounding)
+	default:
+		return f, fmt.Errorf("unknown rounding mode %q", rounding)
+	}
+	return f, nil
+}
+
+// formatChange renders a relative change with an explicit "+" on increases, e.g. "+1.50%".
+func formatChange(ratio *big.Rat, f PctFormat) string {
+	if ratio != nil && ratio.Sign() > 0 {
+		return "+" + formatPct(ratio, f)
+	}
+	return formatPct(ratio, f)
+}
+
+// formatPct renders a ratio (0.5 == 50%) as a percentage string such as "50.00%".
+func formatPct(ratio *big.Rat, f PctFormat) string {
+	if ratio == nil {
+		return "n/a"
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(f.Decimals)), nil)
+	scaled := new(big.Rat).Mul(ratio, new(big.Rat).SetInt(new(big.Int).Mul(scale, big.NewInt(100))))
+
+	num := new(big.Int).Abs(scaled.Num())
+	den := scaled.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		// Compare twice the remainder with the denominator to classify the discarded part.
+		cmp := new(big.Int).Lsh(rem, 1).Cmp(den)
+		switch f.Rounding {
+		case RoundDown:
+		case RoundHalfEven:
+			if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, big.NewInt(1))
+			}
+		default:
+			if cmp >= 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+
+	digits := quo.String()
+	if f.Decimals > 0 {
+		if len(digits) <= f.Decimals {
+			digits = strings.Repeat("0", f.Decimals-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-f.Decimals] + "." + digits[len(digits)-f.Decimals:]
+	}
+
+	sign := ""
+	if scaled.Sign() < 0 && quo.Sign() != 0 {
+		sign = "-"
+	}
+	return sign + digits + "%"
+}
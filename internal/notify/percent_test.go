@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFormatPctRounding(t *testing.T) {
+	tests := []struct {
+		ratio *big.Rat
+		f     PctFormat
+		want  string
+	}{
+		{big.NewRat(94995, 100000), PctFormat{2, RoundHalfUp}, "95.00%"},
+		{big.NewRat(94995, 100000), PctFormat{2, RoundHalfEven}, "95.00%"},
+		{big.NewRat(94985, 100000), PctFormat{2, RoundHalfEven}, "94.98%"},
+		{big.NewRat(94995, 100000), PctFormat{2, RoundDown}, "94.99%"},
+		{big.NewRat(949949, 1000000), PctFormat{2, RoundHalfUp}, "94.99%"},
+		{big.NewRat(-94995, 100000), PctFormat{2, RoundHalfUp}, "-95.00%"},
+		{big.NewRat(-94995, 100000), PctFormat{2, RoundDown}, "-94.99%"},
+		{big.NewRat(1, 3), PctFormat{3, RoundHalfUp}, "33.333%"},
+		{big.NewRat(2, 3), PctFormat{0, RoundHalfUp}, "67%"},
+		{big.NewRat(5, 1000), PctFormat{0, RoundHalfEven}, "0%"},
+		{big.NewRat(15, 1000), PctFormat{0, RoundHalfEven}, "2%"},
+		{big.NewRat(1, 100000), PctFormat{2, RoundHalfUp}, "0.00%"},
+		{big.NewRat(-1, 100000), PctFormat{2, RoundHalfUp}, "0.00%"},
+		{big.NewRat(1, 1), PctFormat{2, RoundHalfUp}, "100.00%"},
+		{nil, DefaultPctFormat, "n/a"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPct(tt.ratio, tt.f); got != tt.want {
+			t.Errorf("formatPct(%v, %+v) = %q, want %q", tt.ratio, tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestFormatChangeSign(t *testing.T) {
+	if got := formatChange(big.NewRat(1, 100), DefaultPctFormat); got != "+1.00%" {
+		t.Errorf("positive change = %q", got)
+	}
+	if got := formatChange(big.NewRat(-1, 100), DefaultPctFormat); got != "-1.00%" {
+		t.Errorf("negative change = %q", got)
+	}
+	if got := formatChange(new(big.Rat), DefaultPctFormat); got != "0.00%" {
+		t.Errorf("zero change = %q", got)
+	}
+}
+
+func TestPayloadUsesPctFormat(t *testing.T) {
+	event := SupplyChangeEvent{Change: big.NewRat(94995, 100000)}
+	payload := newEventPayload(event, PctFormat{Decimals: 2, Rounding: RoundDown})
+	if payload.ChangePct == nil || *payload.ChangePct != "+94.99%" {
+		t.Fatalf("change_pct = %v, want +94.99%%", payload.ChangePct)
+	}
+}
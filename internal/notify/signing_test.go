@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// verifyingReceiver checks each request the way a receiver would: the HMAC-SHA256 of
+// the raw body under secret, prefixed by X-Timestamp and "." when signTimestamp is
+// set, must match the signature header.
+func verifyingReceiver(t *testing.T, secret, header string, signTimestamp bool) (*httptest.Server, *int) {
+	t.Helper()
+	verified := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(timestampHeader)
+		mac := hmac.New(sha256.New, []byte(secret))
+		if signTimestamp {
+			mac.Write([]byte(timestamp + "."))
+		}
+		mac.Write(body)
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get(header); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("%s = %q, want %q", header, got, want)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+			t.Errorf("X-Timestamp = %q", timestamp)
+		}
+		verified++
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &verified
+}
+
+func TestSignedRequestsVerify(t *testing.T) {
+	event := SupplyChangeEvent{Type: EventSupplyIncrease, AssetName: `USD"C`, TriggerReasons: []string{"increase"}}
+
+	webhookSrv, webhookVerified := verifyingReceiver(t, "s3cret", DefaultSignatureHeader, false)
+	webhook, err := NewWebhookNotifier(webhookSrv.URL, `{"asset":{{json .AssetName}}}`, "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := webhook.WithSigningSecret("s3cret", "", false).Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	rpcSrv, rpcVerified := verifyingReceiver(t, "other", "X-Aave-Signature", true)
+	rpc := NewJSONRPCNotifier(rpcSrv.URL, JSONRPCFormatV2, "", "", nil).WithSigningSecret("other", "X-Aave-Signature", true)
+	if err := rpc.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if *webhookVerified != 1 || *rpcVerified != 1 {
+		t.Fatalf("verified webhook %d, json_rpc %d request(s); want 1 each", *webhookVerified, *rpcVerified)
+	}
+}
+
+func TestUnsignedWithoutSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(DefaultSignatureHeader) != "" || r.Header.Get(timestampHeader) != "" {
+			t.Errorf("unsigned notifier sent signature headers")
+		}
+	}))
+	defer srv.Close()
+	rpc := NewJSONRPCNotifier(srv.URL, JSONRPCFormatFlat, "", "", nil).WithSigningSecret("", "", false)
+	if err := rpc.Notify(context.Background(), SupplyChangeEvent{AssetName: "USDC"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignatureCoversBodyOnlyByDefault(t *testing.T) {
+	signer := newRequestSigner("s3cret", "", false)
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	body := []byte(`{"asset":"USDC"}`)
+	signer.sign(req, body)
+
+	if got := req.Header.Get(timestampHeader); got != "1700000000" {
+		t.Fatalf("X-Timestamp = %q", got)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if got, want := req.Header.Get(DefaultSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestSignatureCoversTimestampWhenEnabled(t *testing.T) {
+	signer := newRequestSigner("s3cret", "", true)
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	body := []byte(`{"asset":"USDC"}`)
+	signer.sign(req, body)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("1700000000."))
+	mac.Write(body)
+	if got, want := req.Header.Get(DefaultSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	// Replaying the body under a fresh timestamp needs a signature the sender never made.
+	if signer.signature("1700000000", body) == signer.signature("1700000300", body) {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackHeaderLimit is the maximum length, in characters, of a Block Kit header's text.
+const slackHeaderLimit = 150
+
+// SlackNotifier posts events to a Slack incoming webhook as Block Kit messages: a header
+// with the asset name, a section with the old, new and target supply, and a context line
+// with the observation time.
+type SlackNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+	renderer   *Renderer
+}
+
+// NewSlackNotifier builds a notifier that posts to webhookURL.
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		httpClient: newHTTPClient(""),
+	}
+}
+
+// WithUserAgent returns a copy of the notifier that sends userAgent; an empty one sends
+// DefaultUserAgent.
+func (s *SlackNotifier) WithUserAgent(userAgent string) *SlackNotifier {
+	clone := *s
+	clone.httpClient = newHTTPClient(userAgent)
+	return &clone
+}
+
+// WithRenderer returns a copy of the notifier that formats percentages and amounts with
+// the renderer's settings instead of the defaults.
+func (s *SlackNotifier) WithRenderer(renderer *Renderer) *SlackNotifier {
+	clone := *s
+	clone.renderer = renderer
+	return &clone
+}
+
+// Name implements Notifier.
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// slackText is a Block Kit text object.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackBlock is a Block Kit layout block; only the fields of the block types used here.
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	// Text is shown in notifications and by clients that cannot display blocks.
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// Notify posts the event to the webhook.
+func (s *SlackNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	raw, err := json.Marshal(slackPayload(event, s.renderer.displayOpts()))
+	if err != nil {
+		return fmt.Errorf("marshal slack payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("build slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send slack request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		// Slack explains a rejected payload in a short plain-text body, e.g. "invalid_blocks".
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if reason := strings.TrimSpace(string(body)); reason != "" {
+			return fmt.Errorf("slack returned status %s: %s", resp.Status, reason)
+		}
+		return fmt.Errorf("slack returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// slackPayload builds the Block Kit message for an event. The header is plain text, so
+// the asset name needs no escaping beyond JSON's; the mrkdwn fields escape &, < and >
+// as Slack requires.
+func slackPayload(event SupplyChangeEvent, opts displayOptions) slackMessage {
+	header := event.AssetName
+	if header == "" {
+		header = event.AssetAddress
+	}
+
+	summary := "*" + slackEscape(string(event.Type)) + "*"
+	if event.Change != nil {
+		summary += " " + slackEscape(formatChange(event.Change, opts.pct))
+	}
+	if len(event.TriggerReasons) > 0 {
+		summary += "\n" + slackEscape(strings.Join(event.TriggerReasons, "; "))
+	}
+	if opts.sparkline {
+		if line := sparkline(event.History); line != "" {
+			summary += "\nTrend: " + line
+		}
+	}
+
+	supplyField := func(label string, amount *big.Int) slackText {
+		value := displayAmount(amount, event.Decimals, opts)
+		return slackText{Type: "mrkdwn", Text: "*" + label + "*\n" + slackEscape(value)}
+	}
+	fields := []slackText{
+		supplyField("Old supply", event.OldTotalSupply),
+		supplyField("New supply", event.NewTotalSupply),
+		supplyField("Target supply", event.TargetTotalSupply),
+	}
+
+	observed := "Observed at " + event.ObservedAt.UTC().Format(time.RFC3339)
+	if event.BlockNumber > 0 {
+		observed += fmt.Sprintf(" · block %d", event.BlockNumber)
+	}
+
+	return slackMessage{
+		Text: slackEscape(DefaultSubject(event)),
+		Blocks: []slackBlock{
+			{Type: "header", Text: &slackText{Type: "plain_text", Text: truncateRunes(header, slackHeaderLimit)}},
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: summary}, Fields: fields},
+			{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: slackEscape(observed)}}},
+		},
+	}
+}
+
+// slackEscape escapes the three characters Slack's mrkdwn treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackNotifierPostsBlocks(t *testing.T) {
+	var got slackMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("payload is not JSON: %v", err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+	}))
+	defer srv.Close()
+
+	event := SupplyChangeEvent{
+		Type:              EventTargetReached,
+		AssetName:         `USD<C> & "co"`,
+		OldTotalSupply:    big.NewInt(900_000_000),
+		NewTotalSupply:    big.NewInt(1_000_000_000),
+		TargetTotalSupply: big.NewInt(1_000_000_000),
+		Decimals:          6,
+		TriggerReasons:    []string{"total supply reached target 1000000000"},
+		ObservedAt:        time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := NewSlackNotifier(srv.URL).Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Blocks) != 3 {
+		t.Fatalf("blocks = %+v", got.Blocks)
+	}
+	header, section, footer := got.Blocks[0], got.Blocks[1], got.Blocks[2]
+	if header.Type != "header" || header.Text.Type != "plain_text" || header.Text.Text != event.AssetName {
+		t.Errorf("header = %+v", header.Text)
+	}
+	if section.Type != "section" || len(section.Fields) != 3 {
+		t.Fatalf("section = %+v", section)
+	}
+	for i, want := range []string{"*Old supply*\n900", "*New supply*\n1,000", "*Target supply*\n1,000"} {
+		if !strings.HasPrefix(section.Fields[i].Text, want) {
+			t.Errorf("field %d = %q, want prefix %q", i, section.Fields[i].Text, want)
+		}
+	}
+	if footer.Type != "context" || !strings.Contains(footer.Elements[0].Text, "2024-05-01T12:00:00Z") {
+		t.Errorf("context = %+v", footer.Elements)
+	}
+	if !strings.HasPrefix(got.Text, "USD&lt;C&gt; &amp; \"co\":") {
+		t.Errorf("fallback text not escaped for mrkdwn: %q", got.Text)
+	}
+}
+
+func TestSlackNotifierReportsStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_blocks", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	err := NewSlackNotifier(srv.URL).Notify(context.Background(), SupplyChangeEvent{AssetName: "USDC"})
+	if err == nil || !strings.Contains(err.Error(), "400 Bad Request") || !strings.Contains(err.Error(), "invalid_blocks") {
+		t.Fatalf("err = %v, want the status and Slack's reason", err)
+	}
+}
+
+func TestSlackNotifierUsesRendererDisplay(t *testing.T) {
+	renderer, err := NewRenderer(RenderOptions{Pct: PctFormat{Decimals: 0, Rounding: RoundHalfUp}, Amount: DefaultAmountFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC",
+		NewTotalSupply: big.NewInt(1_000_000_000),
+		Change:         big.NewRat(1, 8),
+		Decimals:       6,
+	}
+	msg := slackPayload(event, renderer.displayOpts())
+	section := msg.Blocks[1]
+	if !strings.HasSuffix(section.Text.Text, " +13%") {
+		t.Errorf("summary = %q, want the change with no decimals", section.Text.Text)
+	}
+	if got := section.Fields[1].Text; got != "*New supply*\n1,000" {
+		t.Errorf("new supply = %q, want no raw value", got)
+	}
+}
+
+func TestSlackPayloadIncludesSparkline(t *testing.T) {
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC",
+		NewTotalSupply: big.NewInt(3),
+		History:        []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)},
+	}
+	opts := displayOptions{amount: DefaultAmountFormat}
+	if summary := slackPayload(event, opts).Blocks[1].Text.Text; strings.Contains(summary, "Trend:") {
+		t.Errorf("summary = %q, want no trend without the sparkline option", summary)
+	}
+	opts.sparkline = true
+	summary := slackPayload(event, opts).Blocks[1].Text.Text
+	if want := "\nTrend: " + sparkline(event.History); !strings.HasSuffix(summary, want) {
+		t.Errorf("summary = %q, want suffix %q", summary, want)
+	}
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"math/big"
+	"strings"
+)
+
+var sparkGlyphs = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline maps samples onto Unicode block glyphs scaled between their minimum and
+// maximum. It returns "" when there are fewer than two samples; a flat series renders at
+// the lowest glyph.
+func sparkline(samples []*big.Int) string {
+	if len(samples) < 2 {
+		return ""
+	}
+
+	lo, hi := samples[0], samples[0]
+	for _, s := range samples[1:] {
+		if s.Cmp(lo) < 0 {
+			lo = s
+		}
+		if s.Cmp(hi) > 0 {
+			hi = s
+		}
+	}
+	span := new(big.Int).Sub(hi, lo)
+	top := big.NewInt(int64(len(sparkGlyphs) - 1))
+
+	var sb strings.Builder
+	for _, s := range samples {
+		if span.Sign() == 0 {
+			sb.WriteRune(sparkGlyphs[0])
+			continue
+		}
+		offset := new(big.Int).Sub(s, lo)
+		level := offset.Mul(offset, top).Quo(offset, span)
+		sb.WriteRune(sparkGlyphs[level.Int64()])
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	// Registers the pure-Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	idempotency_key     TEXT NOT NULL UNIQUE,
+	observed_at         TEXT NOT NULL,
+	type                TEXT NOT NULL,
+	asset_name          TEXT NOT NULL,
+	asset_address       TEXT NOT NULL,
+	chain               TEXT NOT NULL,
+	holder              TEXT,
+	old_total_supply    TEXT,
+	new_total_supply    TEXT,
+	target_total_supply TEXT,
+	decimals            INTEGER NOT NULL,
+	block_number        INTEGER,
+	trigger_reasons     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS events_asset_observed ON events (asset_address, observed_at);
+`
+
+// SQLiteNotifier appends each event as a row of the events table in a SQLite database,
+// creating the file and schema if needed. Rows are keyed by IdempotencyKey, so a
+// redelivered event is stored once.
+type SQLiteNotifier struct {
+	// mu serializes writers; SQLite allows one at a time and the pool holds a single
+	// connection, so this only keeps waiting callers orderly.
+	mu    sync.Mutex
+	db    *sql.DB
+	chain string
+}
+
+// NewSQLiteNotifier opens (or creates) the database at path. chain is stored with every
+// row to tell apart databases shared between deployments; it may be empty.
+func NewSQLiteNotifier(path, chain string) (*SQLiteNotifier, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, stmt := range []string{"PRAGMA journal_mode=WAL", "PRAGMA busy_timeout=5000", sqliteSchema} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initialize sqlite %s: %w", path, err)
+		}
+	}
+	return &SQLiteNotifier{db: db, chain: chain}, nil
+}
+
+// Name implements Notifier.
+func (s *SQLiteNotifier) Name() string {
+	return "sqlite"
+}
+
+// Notify inserts the event.
+func (s *SQLiteNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	reasons := event.TriggerReasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	rawReasons, err := json.Marshal(reasons)
+	if err != nil {
+		return fmt.Errorf("marshal trigger reasons: %w", err)
+	}
+	var block *uint64
+	if event.BlockNumber != 0 {
+		block = &event.BlockNumber
+	}
+	var holder *string
+	if event.Holder != "" {
+		holder = &event.Holder
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.db.ExecContext(ctx, `INSERT OR IGNORE INTO events (
+		idempotency_key, observed_at, type, asset_name, asset_address, chain, holder,
+		old_total_supply, new_total_supply, target_total_supply, decimals, block_number, trigger_reasons
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		IdempotencyKey(event),
+		event.ObservedAt.UTC().Format(time.RFC3339Nano),
+		string(event.Type),
+		event.AssetName,
+		event.AssetAddress,
+		s.chain,
+		holder,
+		bigIntString(event.OldTotalSupply),
+		bigIntString(event.NewTotalSupply),
+		bigIntString(event.TargetTotalSupply),
+		int(event.Decimals),
+		block,
+		string(rawReasons),
+	)
+	if err != nil {
+		return fmt.Errorf("insert sqlite event: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *SQLiteNotifier) Close() error {
+	return s.db.Close()
+}
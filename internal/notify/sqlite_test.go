@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteNotifierStoresEventsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	n, err := NewSQLiteNotifier(path, "ethereum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      "USDC",
+		AssetAddress:   "0x98C23E9d8f34FEFb1B7BD6a91B7FF122F4e16F5c",
+		OldTotalSupply: big.NewInt(100),
+		NewTotalSupply: big.NewInt(200),
+		Decimals:       6,
+		BlockNumber:    42,
+		TriggerReasons: []string{"total supply increased"},
+		ObservedAt:     time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i < 2; i++ {
+		if err := n.Notify(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int
+	var chain, newSupply, reasons string
+	row := n.db.QueryRow(`SELECT COUNT(*), MAX(chain), MAX(new_total_supply), MAX(trigger_reasons) FROM events`)
+	if err := row.Scan(&count, &chain, &newSupply, &reasons); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 || chain != "ethereum" || newSupply != "200" || reasons != `["total supply increased"]` {
+		t.Fatalf("row = %d %q %q %q", count, chain, newSupply, reasons)
+	}
+}
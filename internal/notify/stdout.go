@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdoutNotifier writes each event as a single line of JSON to standard output.
+type StdoutNotifier struct {
+	mu  sync.Mutex
+	out io.Writer
+	pct PctFormat
+}
+
+// NewStdoutNotifier builds a notifier that prints events to stdout, with change_pct
+// formatted by pct.
+func NewStdoutNotifier(pct PctFormat) *StdoutNotifier {
+	return &StdoutNotifier{out: os.Stdout, pct: pct}
+}
+
+// Name implements Notifier.
+func (s *StdoutNotifier) Name() string {
+	return "stdout"
+}
+
+// Notify encodes the event as JSON and writes it on its own line.
+func (s *StdoutNotifier) Notify(_ context.Context, event SupplyChangeEvent) error {
+	raw, err := json.Marshal(newEventPayload(event, s.pct))
+	if err != nil {
+		return fmt.Errorf("marshal stdout event: %w", err)
+	}
+	raw = append(raw, '\n')
+
+	// Watchers run concurrently; serialize writes so lines never interleave.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(raw); err != nil {
+		return fmt.Errorf("write stdout event: %w", err)
+	}
+	return nil
+}
@@ -14,25 +14,87 @@ import (
 type TelegramNotifier struct {
 	botToken   string
 	chatID     string
+	chatRoutes []TelegramChatRoute
+	renderer   *Renderer
 	httpClient *http.Client
 }
 
-// NewTelegramNotifier builds a Telegram notifier with the supplied credentials.
-func NewTelegramNotifier(botToken, chatID string) *TelegramNotifier {
+// TelegramChatRoute sends matching events to a chat other than the default. Assets match
+// an event's asset name or address case-insensitively; empty Assets or EventTypes match
+// everything.
+type TelegramChatRoute struct {
+	Assets     []string
+	EventTypes []EventType
+	ChatID     string
+}
+
+func (r TelegramChatRoute) matches(event SupplyChangeEvent) bool {
+	if len(r.Assets) > 0 {
+		found := false
+		for _, asset := range r.Assets {
+			if strings.EqualFold(asset, event.AssetName) || strings.EqualFold(asset, event.AssetAddress) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(r.EventTypes) > 0 {
+		found := false
+		for _, eventType := range r.EventTypes {
+			if eventType == event.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// NewTelegramNotifier builds a Telegram notifier with the supplied credentials. Chat
+// routes are checked in order and the first match picks the chat; events matching none go
+// to chatID. An empty userAgent sends DefaultUserAgent. A nil renderer uses the built-in
+// message format.
+func NewTelegramNotifier(botToken, chatID string, chatRoutes []TelegramChatRoute, userAgent string, renderer *Renderer) *TelegramNotifier {
 	return &TelegramNotifier{
 		botToken:   botToken,
 		chatID:     chatID,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		chatRoutes: chatRoutes,
+		renderer:   renderer,
+		httpClient: newHTTPClient(userAgent),
+	}
+}
+
+// chatFor selects the destination chat for an event.
+func (t *TelegramNotifier) chatFor(event SupplyChangeEvent) string {
+	for _, route := range t.chatRoutes {
+		if route.matches(event) {
+			return route.ChatID
+		}
 	}
+	return t.chatID
+}
+
+// Name implements Notifier.
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
 }
 
 // Notify sends the event payload to the configured chat.
 func (t *TelegramNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
-	message := renderMessage(event)
+	message, err := t.renderer.Render(event)
+	if err != nil {
+		return err
+	}
 
 	endpoint := fmt.Sprintf("https://api.telegram.org/bot%v/sendMessage", t.botToken)
 	form := url.Values{}
-	form.Set("chat_id", t.chatID)
+	form.Set("chat_id", t.chatFor(event))
 	form.Set("text", message)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
@@ -54,16 +116,162 @@ func (t *TelegramNotifier) Notify(ctx context.Context, event SupplyChangeEvent)
 	return nil
 }
 
-func renderMessage(event SupplyChangeEvent) string {
+func renderMessage(event SupplyChangeEvent, opts displayOptions) string {
+	if opts.verbosity == VerbosityCompact {
+		return summaryMessage(event, opts)
+	}
+
 	var sb strings.Builder
-	sb.WriteString("Asset total supply change detected\n")
+	switch event.Type {
+	case EventFirstObservation:
+		sb.WriteString("Now watching asset\n")
+	case EventIndexJump:
+		sb.WriteString("Abnormal liquidity index move detected\n")
+	case EventProtocolPause:
+		sb.WriteString("🚨 Protocol pause state changed\n")
+	case EventTreasuryThreshold:
+		sb.WriteString("Protocol fee accrual threshold crossed\n")
+	case EventDebtCeiling:
+		sb.WriteString("Isolation debt ceiling utilization high\n")
+	case EventLevelCrossed:
+		sb.WriteString("Alert level crossed\n")
+	case EventPairDivergence:
+		sb.WriteString("Paired supplies diverged\n")
+	case EventRateThreshold:
+		sb.WriteString("Reserve interest rate threshold crossed\n")
+	case EventConcentration:
+		sb.WriteString("Supply concentration threshold reached\n")
+	case EventDecimalsChanged:
+		sb.WriteString("⚠️ Token decimals changed\n")
+	case EventAlertRateLimited:
+		sb.WriteString("Alert rate limit reached\n")
+	case EventMonitoringStarted:
+		sb.WriteString("Monitoring started\n")
+	case EventShutdownSummary:
+		sb.WriteString("Monitor shutting down\n")
+	case EventAggregateThreshold:
+		sb.WriteString("Combined cross-chain supply threshold reached\n")
+	case EventEModeChanged:
+		sb.WriteString("Reserve eMode category changed\n")
+	case EventSupplyDigest:
+		sb.WriteString("Asset total supply change digest\n")
+	case EventCapLevel:
+		sb.WriteString("Supply approaching target\n")
+	case EventSubthresholdChange:
+		sb.WriteString("Sub-threshold supply change (low priority)\n")
+	case EventCapETA:
+		sb.WriteString("⏳ Supply projected to reach target soon\n")
+	default:
+		sb.WriteString("Asset total supply change detected\n")
+	}
 	sb.WriteString(fmt.Sprintf("Asset: %s (%s)\n", event.AssetName, event.AssetAddress))
-	sb.WriteString(fmt.Sprintf("New total supply: %s\n", formatTokens(event.NewTotalSupply)))
+	if event.Holder != "" {
+		sb.WriteString(fmt.Sprintf("Holder: %s\n", event.Holder))
+	}
+	if event.ExplorerURL != "" {
+		sb.WriteString(fmt.Sprintf("Explorer: %s\n", event.ExplorerURL))
+	}
+	if event.NewTotalSupply != nil {
+		sb.WriteString(fmt.Sprintf("New total supply: %s\n", displayAmount(event.NewTotalSupply, event.Decimals, opts)))
+	}
 	if event.OldTotalSupply != nil {
-		sb.WriteString(fmt.Sprintf("Previous total supply: %s\n", formatTokens(event.OldTotalSupply)))
+		sb.WriteString(fmt.Sprintf("Previous total supply: %s\n", displayAmount(event.OldTotalSupply, event.Decimals, opts)))
+	}
+	if event.Change != nil {
+		sb.WriteString(fmt.Sprintf("Change: %s\n", formatChange(event.Change, opts.pct)))
+	}
+	if event.WrapperSupply != nil {
+		sb.WriteString(fmt.Sprintf("Wrapper amount: %s\n", displayAmount(event.WrapperSupply, event.WrapperDecimals, opts)))
+	}
+	if event.ReferenceSupply != nil {
+		sb.WriteString(fmt.Sprintf("Reference (%s): %s\n", event.ReferenceMode, displayAmount(event.ReferenceSupply, event.Decimals, opts)))
+	}
+	if opts.sparkline {
+		if line := sparkline(event.History); line != "" {
+			sb.WriteString(fmt.Sprintf("Trend: %s\n", line))
+		}
+	}
+	if event.CoalescedChanges > 1 {
+		sb.WriteString(fmt.Sprintf("Coalesced changes: %d\n", event.CoalescedChanges))
 	}
 	if event.TargetTotalSupply != nil {
-		sb.WriteString(fmt.Sprintf("Target threshold: %s\n", formatTokens(event.TargetTotalSupply)))
+		direction := ""
+		if event.TargetDirection == TargetDirectionBelow {
+			direction = " (alerts below)"
+		}
+		sb.WriteString(fmt.Sprintf("Target threshold%s: %s\n", direction, displayAmount(event.TargetTotalSupply, event.Decimals, opts)))
+	}
+	if event.NewLiquidityIndex != nil {
+		sb.WriteString(fmt.Sprintf("Liquidity index (RAY): %s -> %s\n", event.OldLiquidityIndex.String(), event.NewLiquidityIndex.String()))
+	}
+	if event.DebtCeiling != nil {
+		sb.WriteString(fmt.Sprintf("Isolation debt: %s / %s USD\n", formatAmount(event.IsolationDebt, 2), formatAmount(event.DebtCeiling, 2)))
+	}
+	if event.NewEModeCategory != nil {
+		sb.WriteString(fmt.Sprintf("eMode category: %d -> %d\n", *event.OldEModeCategory, *event.NewEModeCategory))
+	}
+	if event.PreviousATH != nil {
+		sb.WriteString(fmt.Sprintf("Previous ATH: %s (%s)\n", displayAmount(event.PreviousATH, event.Decimals, opts), event.PreviousATHAt.UTC().Format(time.RFC3339)))
+	}
+	if event.PairSupply != nil {
+		sb.WriteString(fmt.Sprintf("Paired token: %s, total supply %s\n", event.PairAddress, displayAmount(event.PairSupply, event.PairDecimals, opts)))
+	}
+	if event.Divergence != nil {
+		sb.WriteString(fmt.Sprintf("Divergence: %s\n", formatPct(event.Divergence, opts.pct)))
+	}
+	for _, c := range event.Contributions {
+		sb.WriteString(fmt.Sprintf("%s: %s (%s)\n", c.Chain, displayAmount(c.Supply, c.Decimals, opts), c.Address))
+	}
+	if event.Concentration != nil {
+		sb.WriteString(fmt.Sprintf("Top %d holders: %s of supply\n", len(event.TopHolders), formatPct(event.Concentration, opts.pct)))
+	}
+	if event.LiquidityRate != nil {
+		sb.WriteString(fmt.Sprintf("Liquidity rate: %s\n", formatPct(event.LiquidityRate, opts.pct)))
+	}
+	if event.VariableBorrowRate != nil {
+		sb.WriteString(fmt.Sprintf("Variable borrow rate: %s\n", formatPct(event.VariableBorrowRate, opts.pct)))
+	}
+	if event.CapETA > 0 {
+		sb.WriteString(fmt.Sprintf("Projected to reach target in: %s\n", event.CapETA.Round(time.Minute)))
+	}
+	if event.IncidentID != "" {
+		sb.WriteString(fmt.Sprintf("Incident: %s (%s)\n", event.IncidentID, event.IncidentStatus))
+	}
+	if event.AccruedToTreasury != nil {
+		sb.WriteString(fmt.Sprintf("Accrued to treasury: %s\n", event.AccruedToTreasury.String()))
+	}
+	if event.Source == SourceGraph {
+		sb.WriteString("Source: subgraph fallback (may lag the chain)\n")
+	}
+	if opts.verbosity == VerbosityDetailed {
+		sb.WriteString(fmt.Sprintf("Event type: %s\n", event.Type))
+		if event.NewTotalSupply != nil && event.TargetTotalSupply != nil && event.TargetTotalSupply.Sign() != 0 {
+			ratio := new(big.Rat).SetFrac(event.NewTotalSupply, event.TargetTotalSupply)
+			sb.WriteString(fmt.Sprintf("Of target: %s\n", formatPct(ratio, opts.pct)))
+		}
+		if event.TargetTotalSupply != nil {
+			direction := "above"
+			if event.TargetDirection == TargetDirectionBelow {
+				direction = TargetDirectionBelow
+			}
+			sb.WriteString(fmt.Sprintf("Target direction: %s\n", direction))
+		}
+		reference := event.ReferenceMode
+		if reference == "" {
+			reference = "last poll"
+		}
+		sb.WriteString(fmt.Sprintf("Reference mode: %s\n", reference))
+		if event.Source != "" {
+			sb.WriteString(fmt.Sprintf("Source: %s\n", event.Source))
+		}
+		sb.WriteString(fmt.Sprintf("Decimals: %d\n", event.Decimals))
+		if event.BlockNumber != 0 {
+			sb.WriteString(fmt.Sprintf("Block: %d", event.BlockNumber))
+			if !event.BlockTimestamp.IsZero() {
+				sb.WriteString(fmt.Sprintf(" (%s)", event.BlockTimestamp.UTC().Format(time.RFC3339)))
+			}
+			sb.WriteString("\n")
+		}
 	}
 	if len(event.TriggerReasons) > 0 {
 		sb.WriteString("Reasons:\n")
@@ -77,21 +285,33 @@ func renderMessage(event SupplyChangeEvent) string {
 	return sb.String()
 }
 
+// formatTokens groups an integer's digits in thousands, e.g. "-1,234,567". It works on
+// the decimal string, so values of any size format exactly.
 func formatTokens(amount *big.Int) string {
 	if amount == nil {
 		return "n/a"
 	}
 
 	digits := amount.String()
+	sign := ""
+	if amount.Sign() < 0 {
+		sign, digits = "-", digits[1:]
+	}
 	if len(digits) <= 3 {
-		return digits
+		return sign + digits
 	}
 
-	var parts []string
-	for len(digits) > 3 {
-		parts = append([]string{digits[len(digits)-3:]}, parts...)
-		digits = digits[:len(digits)-3]
+	var sb strings.Builder
+	sb.Grow(len(sign) + len(digits) + len(digits)/3)
+	sb.WriteString(sign)
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	sb.WriteString(digits[:head])
+	for i := head; i < len(digits); i += 3 {
+		sb.WriteByte(',')
+		sb.WriteString(digits[i : i+3])
 	}
-	parts = append([]string{digits}, parts...)
-	return strings.Join(parts, ",")
+	return sb.String()
 }
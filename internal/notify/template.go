@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs returns the helpers available to every message template.
+func templateFuncs(opts displayOptions) template.FuncMap {
+	return template.FuncMap{
+		"tokens":    formatTokens,
+		"amount":    formatAmount,
+		"join":      strings.Join,
+		"sparkline": sparkline,
+		"pct": func(ratio *big.Rat) string {
+			return formatPct(ratio, opts.pct)
+		},
+		"display": func(amount *big.Int, decimals uint8) string {
+			return opts.amount.format(amount, decimals)
+		},
+	}
+}
+
+// RenderOptions configures a Renderer.
+type RenderOptions struct {
+	// DefaultTemplate and ByType (keyed by event type name) are optional templates.
+	DefaultTemplate string
+	ByType          map[string]string
+	// ByAsset holds templates keyed by asset name or address, matched case-insensitively;
+	// they take precedence over the per-type and default templates.
+	ByAsset map[string]string
+	// Pct controls percentage display.
+	Pct PctFormat
+	// Amount controls how token amounts are written in the built-in format and by the
+	// display template function. The zero value groups thousands.
+	Amount AmountFormat
+	// ShowRaw appends the exact base-unit value after each human-readable amount in the
+	// built-in format.
+	ShowRaw bool
+	// Sparkline adds a trend line of recent samples to the built-in format.
+	Sparkline bool
+}
+
+// Renderer turns events into message text using Go text/template. Each event type may have
+// its own template; types without one use the default template, and when no default is
+// configured the built-in message format is used.
+type Renderer struct {
+	fallback *template.Template
+	byType   map[EventType]*template.Template
+	byAsset  map[string]*template.Template
+	// subject renders titles for notifiers that have one; nil uses DefaultSubject.
+	subject *template.Template
+	display displayOptions
+}
+
+// NewRenderer parses the configured templates; both the default and the per-type
+// overrides may be empty.
+func NewRenderer(opts RenderOptions) (*Renderer, error) {
+	r := &Renderer{
+		byType:  make(map[EventType]*template.Template, len(opts.ByType)),
+		byAsset: make(map[string]*template.Template, len(opts.ByAsset)),
+		display: displayOptions{pct: opts.Pct, amount: opts.Amount, showRaw: opts.ShowRaw, verbosity: VerbosityNormal, sparkline: opts.Sparkline},
+	}
+	funcs := templateFuncs(r.display)
+
+	if opts.DefaultTemplate != "" {
+		tmpl, err := template.New("default").Funcs(funcs).Parse(opts.DefaultTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("parse default template: %w", err)
+		}
+		r.fallback = tmpl
+	}
+
+	for name, text := range opts.ByType {
+		eventType, err := ParseEventType(name)
+		if err != nil {
+			return nil, fmt.Errorf("template for %q: %w", name, err)
+		}
+		tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s template: %w", name, err)
+		}
+		r.byType[eventType] = tmpl
+	}
+
+	for asset, text := range opts.ByAsset {
+		tmpl, err := template.New(asset).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse template for asset %s: %w", asset, err)
+		}
+		r.byAsset[strings.ToLower(asset)] = tmpl
+	}
+
+	return r, nil
+}
+
+// WithDefaultTemplate returns a copy of the renderer whose default template is text, for
+// notifiers with their own template. Per-asset and per-type templates still take
+// precedence.
+func (r *Renderer) WithDefaultTemplate(name, text string) (*Renderer, error) {
+	clone := Renderer{display: defaultDisplay}
+	if r != nil {
+		clone = *r
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs(clone.display)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s template: %w", name, err)
+	}
+	clone.fallback = tmpl
+	return &clone, nil
+}
+
+// WithSubjectTemplate returns a copy of the renderer that renders subjects with text, for
+// notifiers whose messages have a title separate from the body. An empty text keeps the
+// default subject.
+func (r *Renderer) WithSubjectTemplate(text string) (*Renderer, error) {
+	clone := Renderer{display: defaultDisplay}
+	if r != nil {
+		clone = *r
+	}
+	if text == "" {
+		return &clone, nil
+	}
+	tmpl, err := template.New("subject").Funcs(templateFuncs(clone.display)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject template: %w", err)
+	}
+	clone.subject = tmpl
+	return &clone, nil
+}
+
+// DefaultSubject summarizes an event in one line: the asset and its trigger reasons.
+func DefaultSubject(event SupplyChangeEvent) string {
+	return fmt.Sprintf("%s: %s", event.AssetName, strings.Join(event.TriggerReasons, "; "))
+}
+
+// Subject produces the title for an event, trimmed to one line. A nil renderer, or one
+// without a subject template, uses DefaultSubject.
+func (r *Renderer) Subject(event SupplyChangeEvent) (string, error) {
+	if r == nil || r.subject == nil {
+		return DefaultSubject(event), nil
+	}
+	var sb strings.Builder
+	if err := r.subject.Execute(&sb, event); err != nil {
+		return "", fmt.Errorf("render subject template: %w", err)
+	}
+	return strings.Join(strings.Fields(sb.String()), " "), nil
+}
+
+// pctFormat returns the percentage format used by payloads built alongside the message.
+func (r *Renderer) pctFormat() PctFormat {
+	if r == nil {
+		return DefaultPctFormat
+	}
+	return r.display.pct
+}
+
+// displayOpts returns the number display settings, for notifiers that lay out their own
+// messages instead of rendering one.
+func (r *Renderer) displayOpts() displayOptions {
+	if r == nil {
+		return defaultDisplay
+	}
+	return r.display
+}
+
+// template returns the user template for the event: the asset's, then the event type's,
+// then the default. It is nil when the built-in format applies.
+func (r *Renderer) template(event SupplyChangeEvent) *template.Template {
+	if r == nil {
+		return nil
+	}
+	if tmpl, ok := r.byAsset[strings.ToLower(event.AssetName)]; ok {
+		return tmpl
+	}
+	if tmpl, ok := r.byAsset[strings.ToLower(event.AssetAddress)]; ok {
+		return tmpl
+	}
+	if tmpl, ok := r.byType[event.Type]; ok {
+		return tmpl
+	}
+	return r.fallback
+}
+
+// hasTemplate reports whether a user template applies to the event.
+func (r *Renderer) hasTemplate(event SupplyChangeEvent) bool {
+	return r.template(event) != nil
+}
+
+// Render produces the message text for an event. A nil renderer uses the built-in format.
+func (r *Renderer) Render(event SupplyChangeEvent) (string, error) {
+	if r == nil {
+		return renderMessage(event, defaultDisplay), nil
+	}
+
+	tmpl := r.template(event)
+	if tmpl == nil {
+		return renderMessage(event, r.display), nil
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, event); err != nil {
+		return "", fmt.Errorf("render %s template: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,63 @@
+package notify
+
+import "testing"
+
+func TestRendererTemplatePrecedence(t *testing.T) {
+	r, err := NewRenderer(RenderOptions{
+		DefaultTemplate: "default",
+		ByType:          map[string]string{"supply_increase": "by type"},
+		ByAsset:         map[string]string{"USDC": "asset {{.AssetName}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifierRenderer, err := r.WithDefaultTemplate("notifier.tmpl", "notifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		renderer *Renderer
+		event    SupplyChangeEvent
+		want     string
+	}{
+		{r, SupplyChangeEvent{AssetName: "usdc", Type: EventSupplyIncrease}, "asset usdc"},
+		{r, SupplyChangeEvent{AssetName: "DAI", Type: EventSupplyIncrease}, "by type"},
+		{r, SupplyChangeEvent{AssetName: "DAI", Type: EventSupplyDecrease}, "default"},
+		{notifierRenderer, SupplyChangeEvent{AssetName: "DAI", Type: EventSupplyDecrease}, "notifier"},
+		{notifierRenderer, SupplyChangeEvent{AssetName: "USDC", Type: EventSupplyDecrease}, "asset USDC"},
+	}
+	for _, tc := range cases {
+		got, err := tc.renderer.Render(tc.event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("Render(%s %s) = %q, want %q", tc.event.AssetName, tc.event.Type, got, tc.want)
+		}
+	}
+}
+
+func TestWithDefaultTemplateReportsParseErrors(t *testing.T) {
+	if _, err := (*Renderer)(nil).WithDefaultTemplate("broken.tmpl", "{{.AssetName"); err == nil {
+		t.Fatalf("expected a parse error")
+	}
+}
+
+func TestSubjectTemplate(t *testing.T) {
+	event := SupplyChangeEvent{AssetName: "USDC", Type: EventTargetReached, TriggerReasons: []string{"total supply reached target 100"}}
+	if got, _ := (*Renderer)(nil).Subject(event); got != "USDC: total supply reached target 100" {
+		t.Errorf("default subject = %q", got)
+	}
+
+	r, err := (*Renderer)(nil).WithSubjectTemplate("[{{.Type}}]\n{{.AssetName}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := r.Subject(event); got != "[target_reached] USDC" {
+		t.Errorf("templated subject = %q, want it on one line", got)
+	}
+	if _, err := r.WithSubjectTemplate("{{.AssetName"); err == nil {
+		t.Fatalf("expected a parse error")
+	}
+}
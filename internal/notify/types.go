@@ -1,18 +1,412 @@
 package notify
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"math/big"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// EventType classifies what caused an event to fire.
+type EventType string
+
+const (
+	// EventSupplyIncrease reports a total supply increase above the trigger threshold.
+	EventSupplyIncrease EventType = "supply_increase"
+	// EventSupplyDecrease reports a total supply decrease.
+	EventSupplyDecrease EventType = "supply_decrease"
+	// EventTargetReached reports total supply crossing the configured target.
+	EventTargetReached EventType = "target_reached"
+	// EventFirstObservation is informational: the first value recorded after startup.
+	EventFirstObservation EventType = "first_observation"
+	// EventIndexJump reports an abnormal move in the reserve liquidity index between polls.
+	EventIndexJump EventType = "liquidity_index_jump"
+	// EventProtocolPause reports a change in the Pool's protocol-wide pause state.
+	EventProtocolPause EventType = "protocol_pause"
+	// EventTreasuryThreshold fires when a reserve's accruedToTreasury crosses its threshold.
+	EventTreasuryThreshold EventType = "treasury_threshold"
+	// EventDebtCeiling fires when isolation-mode debt utilization reaches its threshold.
+	EventDebtCeiling EventType = "debt_ceiling_utilization"
+	// EventLevelCrossed fires when the tracked value crosses one of an asset's alert levels.
+	EventLevelCrossed EventType = "level_crossed"
+	// EventDecimalsChanged fires when a token's decimals differ from the cached value.
+	EventDecimalsChanged EventType = "decimals_changed"
+	// EventSupplyATH fires when the tracked value exceeds the highest value seen so far.
+	EventSupplyATH EventType = "supply_ath"
+	// EventAlertRateLimited reports that further alerts for an asset are being suppressed.
+	EventAlertRateLimited EventType = "alert_rate_limited"
+	// EventCapETA fires when the projected time until the target is reached drops below
+	// the asset's warning duration.
+	EventCapETA EventType = "cap_eta"
+	// EventRateThreshold fires when a reserve's liquidity or variable borrow rate rises
+	// to its threshold.
+	EventRateThreshold EventType = "rate_threshold"
+	// EventPairDivergence fires when two correlated tokens' supplies diverge beyond the
+	// pair's threshold.
+	EventPairDivergence EventType = "pair_divergence"
+	// EventConcentration fires when the share of supply held by an asset's top holders
+	// rises to its threshold.
+	EventConcentration EventType = "concentration_threshold"
+	// EventAggregateThreshold fires when the combined supply of an aggregate's members
+	// crosses its threshold.
+	EventAggregateThreshold EventType = "aggregate_threshold"
+	// EventEModeChanged fires when a reserve's efficiency-mode category changes.
+	EventEModeChanged EventType = "emode_changed"
+	// EventSupplyDigest summarizes the supply changes held back while an asset is in the
+	// adaptive mode's digest phase.
+	EventSupplyDigest EventType = "supply_digest"
+	// EventCapLevel fires when the tracked value reaches one of an asset's cap_levels, a
+	// percentage of its target.
+	EventCapLevel EventType = "cap_level"
+	// EventSubthresholdChange reports supply changes that fired no trigger, one at a time
+	// or as a periodic digest of their accumulated drift.
+	EventSubthresholdChange EventType = "subthreshold_change"
+	// EventMonitoringStarted announces that the service is running with its configuration.
+	EventMonitoringStarted EventType = "monitoring_started"
+	// EventShutdownSummary reports the service's activity as it shuts down.
+	EventShutdownSummary EventType = "shutdown_summary"
+)
+
+// TargetDirectionBelow marks events of assets whose target alerts on a downward crossing.
+const TargetDirectionBelow = "below"
+
+// EventTypes lists every known event type.
+var EventTypes = []EventType{
+	EventSupplyIncrease,
+	EventSupplyDecrease,
+	EventTargetReached,
+	EventFirstObservation,
+	EventIndexJump,
+	EventProtocolPause,
+	EventTreasuryThreshold,
+	EventDebtCeiling,
+	EventLevelCrossed,
+	EventDecimalsChanged,
+	EventSupplyATH,
+	EventAlertRateLimited,
+	EventCapETA,
+	EventRateThreshold,
+	EventPairDivergence,
+	EventConcentration,
+	EventAggregateThreshold,
+	EventEModeChanged,
+	EventSupplyDigest,
+	EventCapLevel,
+	EventSubthresholdChange,
+	EventMonitoringStarted,
+	EventShutdownSummary,
+}
+
+// ParseEventType validates a configured event type name.
+func ParseEventType(v string) (EventType, error) {
+	for _, t := range EventTypes {
+		if string(t) == v {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown event type %q", v)
+}
+
+// Values for SupplyChangeEvent.Source.
+const (
+	// SourceRPC marks readings taken directly from the chain.
+	SourceRPC = "rpc"
+	// SourceGraph marks readings taken from a subgraph, which may lag the chain.
+	SourceGraph = "graph"
+)
+
 // SupplyChangeEvent captures the details of an asset total supply change.
 type SupplyChangeEvent struct {
-	AssetName         string
-	AssetAddress      string
-	OldTotalSupply    *big.Int
-	NewTotalSupply    *big.Int
+	Type         EventType
+	AssetName    string
+	AssetAddress string
+	// Labels are the asset's configured labels; nil for events not tied to an asset.
+	Labels map[string]string
+	// ExplorerURL links to the asset on the chain's block explorer, empty if not configured.
+	ExplorerURL    string
+	Holder         string
+	OldTotalSupply *big.Int
+	NewTotalSupply *big.Int
+	// Change is the exact relative change (new-old)/old, nil when there is no usable baseline.
+	Change            *big.Rat
 	TargetTotalSupply *big.Int
-	Decimals          uint8
-	TriggerReasons    []string
-	ObservedAt        time.Time
+	// TargetDirection is "below" when the target alerts on a downward crossing, empty for
+	// the default upward one.
+	TargetDirection string
+	// ReferenceSupply is the pinned value the percentage triggers compare against, and
+	// ReferenceMode how it was chosen; both empty when they compare against the last poll.
+	ReferenceSupply *big.Int
+	ReferenceMode   string
+	Decimals        uint8
+	Source          string
+	// BlockNumber is the latest block seen when the value was read, zero if unknown.
+	BlockNumber uint64
+	// BlockTimestamp is that block's header timestamp, zero if unknown.
+	BlockTimestamp time.Time
+	// OldLiquidityIndex and NewLiquidityIndex are set on liquidity index events, in RAY.
+	OldLiquidityIndex *big.Int
+	NewLiquidityIndex *big.Int
+	// AccruedToTreasury is the reserve's unminted protocol fees, set on treasury events.
+	AccruedToTreasury *big.Int
+	// IsolationDebt and DebtCeiling are set on debt ceiling events, in USD with two decimals.
+	IsolationDebt *big.Int
+	DebtCeiling   *big.Int
+	// OldEModeCategory and NewEModeCategory are set on eMode events; 0 means no category.
+	OldEModeCategory *uint8
+	NewEModeCategory *uint8
+	// PreviousATH and PreviousATHAt are the all-time high being replaced, set on ATH events.
+	PreviousATH   *big.Int
+	PreviousATHAt time.Time
+	// CapETA is the projected time until the target is reached, set on cap_eta events.
+	CapETA time.Duration
+	// LiquidityRate and VariableBorrowRate are the reserve's annual rates as ratios
+	// (0.05 == 5%), set on rate threshold events.
+	LiquidityRate      *big.Rat
+	VariableBorrowRate *big.Rat
+	// PairAddress, PairSupply, and PairDecimals describe the second token of a pair and
+	// Divergence their relative difference (0.05 == 5%), set on pair divergence events.
+	PairAddress  string
+	PairSupply   *big.Int
+	PairDecimals uint8
+	Divergence   *big.Rat
+	// Concentration is the share of the supply held by TopHolders (0.4 == 40%), largest
+	// first, set on concentration events.
+	Concentration *big.Rat
+	TopHolders    []string
+	// Contributions break an aggregate's combined NewTotalSupply down by member, set on
+	// aggregate events.
+	Contributions []ChainContribution
+	// WrapperSupply is the wrapper token's own amount, in WrapperDecimals, when the asset
+	// is denominated in its underlying; the supplies above are then underlying amounts.
+	WrapperSupply   *big.Int
+	WrapperDecimals uint8
+	// Notifiers, when set, names the only notifiers the event is delivered to, in place
+	// of the notification routes; set on cap_level events.
+	Notifiers []string
+	// IncidentID and IncidentStatus tie the events that open and resolve a breach
+	// together; set by an IncidentTracker, empty otherwise.
+	IncidentID     string
+	IncidentStatus string
+	TriggerReasons []string
+	// CoalescedChanges counts the successive changes merged into this event by a coalesce
+	// window; zero when coalescing is off.
+	CoalescedChanges int
+	// History holds recent samples of the tracked value, oldest first, on supply events.
+	History    []*big.Int
+	ObservedAt time.Time
+}
+
+// eventPayload is the JSON representation of a SupplyChangeEvent. Supplies are encoded as
+// decimal strings so consumers don't lose precision on values beyond 2^53.
+type eventPayload struct {
+	Type              EventType         `json:"type"`
+	AssetName         string            `json:"asset_name"`
+	AssetAddress      string            `json:"asset_address"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	ExplorerURL       string            `json:"explorer_url,omitempty"`
+	Holder            string            `json:"holder,omitempty"`
+	OldTotalSupply    *string           `json:"old_total_supply"`
+	NewTotalSupply    *string           `json:"new_total_supply"`
+	TargetTotalSupply *string           `json:"target_total_supply"`
+	OldFormatted      *string           `json:"old_total_supply_formatted"`
+	NewFormatted      *string           `json:"new_total_supply_formatted"`
+	TargetFormatted   *string           `json:"target_total_supply_formatted"`
+	TargetDirection   string            `json:"target_direction,omitempty"`
+	ChangePct         *string           `json:"change_pct,omitempty"`
+	ReferenceSupply   *string           `json:"reference_supply,omitempty"`
+	ReferenceMode     string            `json:"reference_mode,omitempty"`
+	Decimals          uint8             `json:"decimals"`
+	Source            string            `json:"source"`
+	BlockNumber       uint64            `json:"block_number,omitempty"`
+	BlockTimestamp    *time.Time        `json:"block_timestamp,omitempty"`
+	OldLiquidityIndex *string           `json:"old_liquidity_index,omitempty"`
+	NewLiquidityIndex *string           `json:"new_liquidity_index,omitempty"`
+	AccruedToTreasury *string           `json:"accrued_to_treasury,omitempty"`
+	IsolationDebt     *string           `json:"isolation_debt,omitempty"`
+	DebtCeiling       *string           `json:"debt_ceiling,omitempty"`
+	OldEModeCategory  *uint8            `json:"old_emode_category,omitempty"`
+	NewEModeCategory  *uint8            `json:"new_emode_category,omitempty"`
+	PreviousATH       *string           `json:"previous_ath,omitempty"`
+	PreviousATHAt     *time.Time        `json:"previous_ath_at,omitempty"`
+	CapETASeconds     *int64            `json:"cap_eta_seconds,omitempty"`
+	LiquidityRatePct  *string           `json:"liquidity_rate_pct,omitempty"`
+	BorrowRatePct     *string           `json:"variable_borrow_rate_pct,omitempty"`
+	PairAddress       string            `json:"pair_address,omitempty"`
+	PairSupply        *string           `json:"pair_supply,omitempty"`
+	PairFormatted     *string           `json:"pair_supply_formatted,omitempty"`
+	DivergencePct     *string           `json:"divergence_pct,omitempty"`
+	ConcentrationPct  *string           `json:"concentration_pct,omitempty"`
+	WrapperSupply     *string           `json:"wrapper_supply,omitempty"`
+	WrapperFormatted  *string           `json:"wrapper_supply_formatted,omitempty"`
+	TopHolders        []string          `json:"top_holders,omitempty"`
+	Contributions     []contribution    `json:"contributions,omitempty"`
+	IncidentID        string            `json:"incident_id,omitempty"`
+	IncidentStatus    string            `json:"incident_status,omitempty"`
+	TriggerReasons    []string          `json:"trigger_reasons"`
+	CoalescedChanges  int               `json:"coalesced_changes,omitempty"`
+	ObservedAt        time.Time         `json:"observed_at"`
+}
+
+func newEventPayload(event SupplyChangeEvent, pct PctFormat) eventPayload {
+	reasons := event.TriggerReasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return eventPayload{
+		Type:              event.Type,
+		AssetName:         event.AssetName,
+		AssetAddress:      event.AssetAddress,
+		Labels:            event.Labels,
+		ExplorerURL:       event.ExplorerURL,
+		Holder:            event.Holder,
+		OldTotalSupply:    bigIntString(event.OldTotalSupply),
+		NewTotalSupply:    bigIntString(event.NewTotalSupply),
+		TargetTotalSupply: bigIntString(event.TargetTotalSupply),
+		OldFormatted:      formattedString(event.OldTotalSupply, event.Decimals),
+		NewFormatted:      formattedString(event.NewTotalSupply, event.Decimals),
+		TargetFormatted:   formattedString(event.TargetTotalSupply, event.Decimals),
+		TargetDirection:   event.TargetDirection,
+		ChangePct:         changeString(event.Change, pct),
+		ReferenceSupply:   bigIntString(event.ReferenceSupply),
+		ReferenceMode:     event.ReferenceMode,
+		Decimals:          event.Decimals,
+		Source:            event.Source,
+		BlockNumber:       event.BlockNumber,
+		BlockTimestamp:    optionalTime(event.BlockTimestamp),
+		OldLiquidityIndex: bigIntString(event.OldLiquidityIndex),
+		NewLiquidityIndex: bigIntString(event.NewLiquidityIndex),
+		AccruedToTreasury: bigIntString(event.AccruedToTreasury),
+		IsolationDebt:     bigIntString(event.IsolationDebt),
+		DebtCeiling:       bigIntString(event.DebtCeiling),
+		OldEModeCategory:  event.OldEModeCategory,
+		NewEModeCategory:  event.NewEModeCategory,
+		PreviousATH:       bigIntString(event.PreviousATH),
+		PreviousATHAt:     optionalTime(event.PreviousATHAt),
+		CapETASeconds:     optionalSeconds(event.CapETA),
+		LiquidityRatePct:  rateString(event.LiquidityRate, pct),
+		BorrowRatePct:     rateString(event.VariableBorrowRate, pct),
+		PairAddress:       event.PairAddress,
+		PairSupply:        bigIntString(event.PairSupply),
+		PairFormatted:     formattedString(event.PairSupply, event.PairDecimals),
+		DivergencePct:     rateString(event.Divergence, pct),
+		ConcentrationPct:  rateString(event.Concentration, pct),
+		WrapperSupply:     bigIntString(event.WrapperSupply),
+		WrapperFormatted:  formattedString(event.WrapperSupply, event.WrapperDecimals),
+		TopHolders:        event.TopHolders,
+		Contributions:     newContributions(event.Contributions),
+		IncidentID:        event.IncidentID,
+		IncidentStatus:    event.IncidentStatus,
+		TriggerReasons:    reasons,
+		CoalescedChanges:  event.CoalescedChanges,
+		ObservedAt:        event.ObservedAt.UTC(),
+	}
+}
+
+// ChainContribution is one member's share of an aggregate.
+type ChainContribution struct {
+	Chain    string
+	Address  string
+	Supply   *big.Int
+	Decimals uint8
+}
+
+// contribution is the JSON representation of a ChainContribution.
+type contribution struct {
+	Chain     string  `json:"chain"`
+	Address   string  `json:"address"`
+	Supply    *string `json:"supply"`
+	Formatted *string `json:"supply_formatted"`
+}
+
+func newContributions(contributions []ChainContribution) []contribution {
+	if len(contributions) == 0 {
+		return nil
+	}
+	out := make([]contribution, len(contributions))
+	for i, c := range contributions {
+		out[i] = contribution{
+			Chain:     c.Chain,
+			Address:   c.Address,
+			Supply:    bigIntString(c.Supply),
+			Formatted: formattedString(c.Supply, c.Decimals),
+		}
+	}
+	return out
+}
+
+func formattedString(v *big.Int, decimals uint8) *string {
+	if v == nil {
+		return nil
+	}
+	s := formatAmount(v, decimals)
+	return &s
+}
+
+func changeString(change *big.Rat, pct PctFormat) *string {
+	if change == nil {
+		return nil
+	}
+	s := formatChange(change, pct)
+	return &s
+}
+
+func rateString(rate *big.Rat, pct PctFormat) *string {
+	if rate == nil {
+		return nil
+	}
+	s := formatPct(rate, pct)
+	return &s
+}
+
+func bigIntString(v *big.Int) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.String()
+	return &s
+}
+
+// IdempotencyKey returns a deterministic identifier for an event so receivers can discard
+// duplicate deliveries. It is the hex SHA-256 of
+// "<lowercase asset address>|<holder or empty>|<event type>|<new supply>|<block>", where
+// block is the block number or, when it is unknown, "t" followed by the observation time
+// in Unix nanoseconds so distinct events never share a key. Retries of the same event
+// always produce the same key.
+func IdempotencyKey(event SupplyChangeEvent) string {
+	newSupply := ""
+	if event.NewTotalSupply != nil {
+		newSupply = event.NewTotalSupply.String()
+	}
+	block := strconv.FormatUint(event.BlockNumber, 10)
+	if event.BlockNumber == 0 {
+		block = "t" + strconv.FormatInt(event.ObservedAt.UnixNano(), 10)
+	}
+	raw := fmt.Sprintf("%s|%s|%s|%s|%s",
+		strings.ToLower(event.AssetAddress),
+		strings.ToLower(event.Holder),
+		event.Type,
+		newSupply,
+		block,
+	)
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func optionalSeconds(d time.Duration) *int64 {
+	if d <= 0 {
+		return nil
+	}
+	seconds := int64(d / time.Second)
+	return &seconds
 }
@@ -0,0 +1,42 @@
+package notify
+
+import "fmt"
+
+// Verbosity controls how much of an event the built-in message format includes.
+type Verbosity string
+
+const (
+	// VerbosityCompact is a single line, for pagers and SMS-like channels.
+	VerbosityCompact Verbosity = "compact"
+	// VerbosityNormal is the standard multi-line message.
+	VerbosityNormal Verbosity = "normal"
+	// VerbosityDetailed adds the event type, the share of the target reached, the target
+	// direction, reference mode and source, decimals, and block details.
+	VerbosityDetailed Verbosity = "detailed"
+)
+
+// ParseVerbosity validates a configured verbosity; empty selects fallback.
+func ParseVerbosity(name string, fallback Verbosity) (Verbosity, error) {
+	switch v := Verbosity(name); v {
+	case "":
+		return fallback, nil
+	case VerbosityCompact, VerbosityNormal, VerbosityDetailed:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown verbosity %q (want compact, normal, or detailed)", name)
+	}
+}
+
+// WithVerbosity returns a copy of the renderer that uses the given verbosity for the
+// built-in format, so notifiers can share templates but differ in length. User templates
+// are unaffected.
+func (r *Renderer) WithVerbosity(v Verbosity) *Renderer {
+	if r == nil {
+		display := defaultDisplay
+		display.verbosity = v
+		return &Renderer{display: display}
+	}
+	clone := *r
+	clone.display.verbosity = v
+	return &clone
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// DefaultWebhookContentType is the Content-Type sent when none is configured.
+const DefaultWebhookContentType = "application/json"
+
+// WebhookNotifier POSTs a body rendered from a user-supplied text/template to a URL, for
+// receivers that expect their own payload shape.
+type WebhookNotifier struct {
+	url         string
+	body        *template.Template
+	contentType string
+	httpClient  *http.Client
+	signer      *requestSigner
+}
+
+// NewWebhookNotifier parses bodyTemplate, which is executed against the SupplyChangeEvent
+// with the message template helpers plus json, which writes a value as JSON (a quoted
+// and escaped string, a bare number for amounts, null for nil). The pct and display
+// helpers follow the renderer's percent and amount settings. An empty contentType sends
+// DefaultWebhookContentType and an empty userAgent sends DefaultUserAgent.
+func NewWebhookNotifier(url, bodyTemplate, contentType, userAgent string, renderer *Renderer) (*WebhookNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook.url is required")
+	}
+	if bodyTemplate == "" {
+		return nil, fmt.Errorf("webhook.body_template is required")
+	}
+	funcs := templateFuncs(renderer.displayOpts())
+	funcs["json"] = func(v any) (string, error) {
+		raw, err := json.Marshal(v)
+		return string(raw), err
+	}
+	body, err := template.New("webhook").Funcs(funcs).Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook.body_template: %w", err)
+	}
+	if contentType == "" {
+		contentType = DefaultWebhookContentType
+	}
+	return &WebhookNotifier{
+		url:         url,
+		body:        body,
+		contentType: contentType,
+		httpClient:  newHTTPClient(userAgent),
+	}, nil
+}
+
+// WithSigningSecret returns a copy of the notifier that signs each request body with
+// HMAC-SHA256 under secret, in header (DefaultSignatureHeader when empty) as
+// "sha256=<hex>", and sends X-Timestamp. signTimestamp binds X-Timestamp into the
+// signature as well. An empty secret turns signing off.
+func (w *WebhookNotifier) WithSigningSecret(secret, header string, signTimestamp bool) *WebhookNotifier {
+	clone := *w
+	clone.signer = newRequestSigner(secret, header, signTimestamp)
+	return &clone
+}
+
+// Name implements Notifier.
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify renders the body template for the event and posts it.
+func (w *WebhookNotifier) Notify(ctx context.Context, event SupplyChangeEvent) error {
+	var body bytes.Buffer
+	if err := w.body.Execute(&body, event); err != nil {
+		return fmt.Errorf("render webhook body: %w", err)
+	}
+
+	raw := body.Bytes()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.contentType)
+	req.Header.Set("Idempotency-Key", IdempotencyKey(event))
+	w.signer.sign(req, raw)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
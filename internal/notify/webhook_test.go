@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierRendersBody(t *testing.T) {
+	var body []byte
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	n, err := NewWebhookNotifier(srv.URL,
+		`{"asset":{{json .AssetName}},"new":{{json .NewTotalSupply}},"target":{{json .TargetTotalSupply}},"reasons":{{json .TriggerReasons}},"at":{{json .ObservedAt}},"shown":"{{amount .NewTotalSupply .Decimals}}"}`,
+		"", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := SupplyChangeEvent{
+		Type:           EventSupplyIncrease,
+		AssetName:      `USD"C`,
+		NewTotalSupply: big.NewInt(1_500_000),
+		Decimals:       6,
+		TriggerReasons: []string{"increase"},
+		ObservedAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != DefaultWebhookContentType {
+		t.Errorf("Content-Type = %q", contentType)
+	}
+	var got struct {
+		Asset   string    `json:"asset"`
+		New     *big.Int  `json:"new"`
+		Target  *big.Int  `json:"target"`
+		Reasons []string  `json:"reasons"`
+		At      time.Time `json:"at"`
+		Shown   string    `json:"shown"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body %s: %v", body, err)
+	}
+	if got.Asset != event.AssetName || got.New.Int64() != 1_500_000 || got.Target != nil || got.Shown != "1.5" || !got.At.Equal(event.ObservedAt) {
+		t.Errorf("body = %s", body)
+	}
+}
+
+func TestNewWebhookNotifierRejectsBadTemplate(t *testing.T) {
+	_, err := NewWebhookNotifier("https://example.com", `{"asset": {{.AssetName}`, "", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "webhook.body_template") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestWebhookNotifierUsesRendererDisplay(t *testing.T) {
+	renderer, err := NewRenderer(RenderOptions{Pct: PctFormat{Decimals: 0, Rounding: RoundHalfUp}, Amount: AmountFormat{Style: AmountSigRound, Figures: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewWebhookNotifier("https://example.com", `{{pct .Change}} {{display .NewTotalSupply .Decimals}}`, "", "", renderer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body strings.Builder
+	event := SupplyChangeEvent{Change: big.NewRat(1, 8), NewTotalSupply: big.NewInt(1_234_000_000), Decimals: 6}
+	if err := n.body.Execute(&body, event); err != nil {
+		t.Fatal(err)
+	}
+	if got := body.String(); got != "13% ≈1.2K" {
+		t.Errorf("body = %q", got)
+	}
+}